@@ -0,0 +1,30 @@
+package api
+
+import (
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/sirupsen/logrus"
+)
+
+func (s *Server) authenticate(apiContext *types.APIContext) error {
+	if s.Authenticator == nil {
+		return nil
+	}
+
+	// Already authenticated for the idempotency cache
+	if identity, ok := apiContext.Request.Context().Value(identityKey{}).(*types.Identity); ok {
+		apiContext.Identity = identity
+		return nil
+	}
+
+	identity, ok, err := s.Authenticator.Authenticate(apiContext.Request)
+	if err != nil {
+		logrus.Debugf("failed to authenticate request %s: %v", apiContext.RequestID, err)
+	}
+	if !ok {
+		return httperror.NewAPIError(httperror.Unauthorized, "authentication required")
+	}
+
+	apiContext.Identity = identity
+	return nil
+}
@@ -0,0 +1,21 @@
+package authn
+
+import (
+	"net/http"
+
+	"github.com/rancher/norman/types"
+)
+
+const (
+	AnonymousUser        = "system:anonymous"
+	UnauthenticatedGroup = "system:unauthenticated"
+)
+
+// Anonymous accepts every request as the anonymous user. It belongs at the end of a Chain, so access control
+// decides what unauthenticated callers may do.
+var Anonymous = AuthenticatorFunc(func(req *http.Request) (*types.Identity, bool, error) {
+	return &types.Identity{
+		Name:   AnonymousUser,
+		Groups: []string{UnauthenticatedGroup},
+	}, true, nil
+})
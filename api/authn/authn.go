@@ -0,0 +1,50 @@
+package authn
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rancher/norman/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Authenticator resolves the caller of a request. It returns false without an error if the request does not
+// carry credentials it understands, so the next authenticator of a Chain can try.
+type Authenticator interface {
+	Authenticate(req *http.Request) (*types.Identity, bool, error)
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator.
+type AuthenticatorFunc func(req *http.Request) (*types.Identity, bool, error)
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) (*types.Identity, bool, error) {
+	return f(req)
+}
+
+// Chain tries the authenticators in order and returns the first identity found. Errors are only returned if no
+// authenticator accepted the request.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(req *http.Request) (*types.Identity, bool, error) {
+	var errs []error
+	for _, authenticator := range c {
+		identity, ok, err := authenticator.Authenticate(req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			return identity, true, nil
+		}
+	}
+	return nil, false, utilerrors.NewAggregate(errs)
+}
+
+// BearerToken returns the token of the Authorization header of the request, or an empty string.
+func BearerToken(req *http.Request) string {
+	auth := strings.TrimSpace(req.Header.Get("Authorization"))
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(auth[7:])
+}
@@ -0,0 +1,60 @@
+package authn
+
+import (
+	"net/http"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	key := []byte("secret")
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":    "alice",
+		"iss":    "https://issuer",
+		"aud":    []string{"norman"},
+		"groups": []string{"admins"},
+	}).SignedString(key)
+	assert.NoError(t, err)
+
+	chain := Chain{
+		&JWTAuthenticator{
+			KeyFunc:        func(*jwt.Token) (interface{}, error) { return key, nil },
+			Issuer:         "https://issuer",
+			Audience:       "norman",
+			UsernamePrefix: "oidc:",
+		},
+		&TokenAuthenticator{
+			Lookup: func(token string) (*types.Identity, error) {
+				if token == "apikey" {
+					return &types.Identity{Name: "bob"}, nil
+				}
+				return nil, nil
+			},
+		},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	identity, ok, err := chain.Authenticate(req)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "oidc:alice", identity.Name)
+	assert.Equal(t, []string{"admins"}, identity.Groups)
+
+	req.Header.Set("Authorization", "Bearer apikey")
+	identity, ok, err = chain.Authenticate(req)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "bob", identity.Name)
+
+	req.Header.Set("Authorization", "Bearer unknown")
+	_, ok, _ = chain.Authenticate(req)
+	assert.False(t, ok)
+
+	identity, ok, _ = append(chain, Anonymous).Authenticate(req)
+	assert.True(t, ok)
+	assert.Equal(t, AnonymousUser, identity.Name)
+}
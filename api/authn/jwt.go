@@ -0,0 +1,82 @@
+package authn
+
+import (
+	"fmt"
+	"net/http"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+)
+
+// JWTAuthenticator validates bearer tokens that are JWTs, like OIDC ID tokens, signed with a key returned by
+// KeyFunc.
+type JWTAuthenticator struct {
+	// KeyFunc returns the key verifying the token, usually selected by the kid header from the keys of the issuer.
+	KeyFunc jwt.Keyfunc
+	// Issuer and Audience, if set, have to match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// UsernameClaim defaults to sub, GroupsClaim to groups.
+	UsernameClaim string
+	GroupsClaim   string
+	// UsernamePrefix is prepended to the username, like oidc: to keep it apart from local users.
+	UsernamePrefix string
+}
+
+func (j *JWTAuthenticator) Authenticate(req *http.Request) (*types.Identity, bool, error) {
+	raw := BearerToken(req)
+	if raw == "" {
+		return nil, false, nil
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(raw, claims, j.KeyFunc)
+	if err != nil {
+		if verr, ok := err.(*jwt.ValidationError); ok && verr.Errors&jwt.ValidationErrorMalformed != 0 {
+			// not a JWT, let other authenticators handle the token
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if !token.Valid {
+		return nil, false, fmt.Errorf("invalid token")
+	}
+
+	if j.Issuer != "" && !claims.VerifyIssuer(j.Issuer, true) {
+		return nil, false, fmt.Errorf("invalid token issuer")
+	}
+	if j.Audience != "" && !verifyAudience(claims, j.Audience) {
+		return nil, false, fmt.Errorf("invalid token audience")
+	}
+
+	usernameClaim := j.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = "sub"
+	}
+	username := convert.ToString(claims[usernameClaim])
+	if username == "" {
+		return nil, false, fmt.Errorf("token has no %s claim", usernameClaim)
+	}
+
+	groupsClaim := j.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+
+	return &types.Identity{
+		Name:   j.UsernamePrefix + username,
+		UID:    convert.ToString(claims["sub"]),
+		Groups: convert.ToStringSlice(claims[groupsClaim]),
+	}, true, nil
+}
+
+// verifyAudience accepts aud as a single string or a list of strings.
+func verifyAudience(claims jwt.MapClaims, audience string) bool {
+	for _, aud := range convert.ToStringSlice(claims["aud"]) {
+		if aud == audience {
+			return true
+		}
+	}
+	return convert.ToString(claims["aud"]) == audience
+}
@@ -0,0 +1,28 @@
+package authn
+
+import (
+	"net/http"
+
+	"github.com/rancher/norman/types"
+)
+
+// TokenLookup returns the identity owning token, or nil if the token is unknown.
+type TokenLookup func(token string) (*types.Identity, error)
+
+// TokenAuthenticator authenticates bearer tokens by looking them up, for example in a store of API keys.
+type TokenAuthenticator struct {
+	Lookup TokenLookup
+}
+
+func (t *TokenAuthenticator) Authenticate(req *http.Request) (*types.Identity, bool, error) {
+	token := BearerToken(req)
+	if token == "" {
+		return nil, false, nil
+	}
+
+	identity, err := t.Lookup(token)
+	if err != nil || identity == nil {
+		return nil, false, err
+	}
+	return identity, true, nil
+}
@@ -0,0 +1,28 @@
+package authn
+
+import (
+	"net/http"
+
+	"github.com/rancher/norman/types"
+)
+
+// ClientCertAuthenticator authenticates requests with a verified TLS client certificate. The common name is the
+// user and the organizations are the groups. The server has to verify client certificates, for example with
+// tls.VerifyClientCertIfGiven.
+type ClientCertAuthenticator struct{}
+
+func (c *ClientCertAuthenticator) Authenticate(req *http.Request) (*types.Identity, bool, error) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil, false, nil
+	}
+
+	cert := req.TLS.VerifiedChains[0][0]
+	if cert.Subject.CommonName == "" {
+		return nil, false, nil
+	}
+
+	return &types.Identity{
+		Name:   cert.Subject.CommonName,
+		Groups: cert.Subject.Organization,
+	}, true, nil
+}
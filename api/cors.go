@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var defaultCORSMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+// CORSConfig configures cross origin requests. AllowedOrigins entries may contain wildcards, for example
+// "https://*.example.com", or be "*" to allow any origin. AllowCredentials only applies to origins matching an
+// entry other than "*", so credentialed requests are never allowed from any origin.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// allowedOrigin returns if origin is allowed and if it is allowed by an entry other than "*".
+func (c *CORSConfig) allowedOrigin(origin string) (bool, bool) {
+	anyOrigin := false
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			anyOrigin = true
+			continue
+		}
+		if strings.EqualFold(allowed, origin) {
+			return true, true
+		}
+		if ok, _ := path.Match(strings.ToLower(allowed), strings.ToLower(origin)); ok {
+			return true, true
+		}
+	}
+	return anyOrigin, false
+}
+
+// handle sets the CORS response headers and returns true if the request was a preflight request that has
+// been fully answered.
+func (c *CORSConfig) handle(rw http.ResponseWriter, req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+
+	preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+
+	rw.Header().Add("Vary", "Origin")
+	allowed, listed := c.allowedOrigin(origin)
+	if !allowed {
+		if preflight {
+			rw.WriteHeader(http.StatusForbidden)
+			return true
+		}
+		return false
+	}
+
+	if c.AllowCredentials && listed {
+		rw.Header().Set("Access-Control-Allow-Origin", origin)
+		rw.Header().Set("Access-Control-Allow-Credentials", "true")
+	} else if len(c.AllowedOrigins) == 1 && c.AllowedOrigins[0] == "*" {
+		rw.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		rw.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if !preflight {
+		if len(c.ExposedHeaders) > 0 {
+			rw.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+		}
+		return false
+	}
+
+	methods := c.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	rw.Header().Add("Vary", "Access-Control-Request-Method")
+	rw.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if len(c.AllowedHeaders) > 0 {
+		rw.Header().Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+	} else if headers := req.Header.Get("Access-Control-Request-Headers"); headers != "" {
+		rw.Header().Add("Vary", "Access-Control-Request-Headers")
+		rw.Header().Set("Access-Control-Allow-Headers", headers)
+	}
+
+	if c.MaxAge > 0 {
+		rw.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+	return true
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	listed := &CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		ExposedHeaders:   []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           time.Minute,
+	}
+	wildcard := &CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	}
+	mixed := &CORSConfig{
+		AllowedOrigins:   []string{"*", "https://app.example.com"},
+		AllowedMethods:   []string{http.MethodGet},
+		AllowedHeaders:   []string{"Authorization"},
+		AllowCredentials: true,
+	}
+
+	tests := []struct {
+		name           string
+		config         *CORSConfig
+		method         string
+		origin         string
+		requestMethod  string
+		requestHeaders string
+		handled        bool
+		code           int
+		expected       map[string]string
+		vary           []string
+	}{
+		{
+			name:    "no origin",
+			config:  listed,
+			method:  http.MethodGet,
+			handled: false,
+		},
+		{
+			name:    "allowed origin",
+			config:  listed,
+			method:  http.MethodGet,
+			origin:  "https://app.example.com",
+			handled: false,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-Id",
+				"Access-Control-Allow-Methods":     "",
+			},
+			vary: []string{"Origin"},
+		},
+		{
+			name:    "allowed origin by pattern",
+			config:  listed,
+			method:  http.MethodGet,
+			origin:  "https://API.example.org",
+			handled: false,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin": "https://API.example.org",
+			},
+			vary: []string{"Origin"},
+		},
+		{
+			name:    "denied origin",
+			config:  listed,
+			method:  http.MethodGet,
+			origin:  "https://evil.example.com",
+			handled: false,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "",
+				"Access-Control-Allow-Credentials": "",
+			},
+			vary: []string{"Origin"},
+		},
+		{
+			name:          "denied preflight",
+			config:        listed,
+			method:        http.MethodOptions,
+			origin:        "https://evil.example.com",
+			requestMethod: http.MethodPut,
+			handled:       true,
+			code:          http.StatusForbidden,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+			vary: []string{"Origin"},
+		},
+		{
+			name:           "preflight echoes the request headers",
+			config:         listed,
+			method:         http.MethodOptions,
+			origin:         "https://app.example.com",
+			requestMethod:  http.MethodPut,
+			requestHeaders: "Content-Type, X-Custom",
+			handled:        true,
+			code:           http.StatusNoContent,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, POST, PUT, DELETE",
+				"Access-Control-Allow-Headers":     "Content-Type, X-Custom",
+				"Access-Control-Max-Age":           "60",
+				"Access-Control-Expose-Headers":    "",
+			},
+			vary: []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:           "preflight with configured methods and headers",
+			config:         mixed,
+			method:         http.MethodOptions,
+			origin:         "https://app.example.com",
+			requestMethod:  http.MethodGet,
+			requestHeaders: "X-Custom",
+			handled:        true,
+			code:           http.StatusNoContent,
+			expected: map[string]string{
+				"Access-Control-Allow-Methods": "GET",
+				"Access-Control-Allow-Headers": "Authorization",
+				"Access-Control-Max-Age":       "",
+			},
+			vary: []string{"Origin", "Access-Control-Request-Method"},
+		},
+		{
+			name:    "options without request method is not a preflight",
+			config:  listed,
+			method:  http.MethodOptions,
+			origin:  "https://app.example.com",
+			handled: false,
+			expected: map[string]string{
+				"Access-Control-Allow-Methods": "",
+			},
+			vary: []string{"Origin"},
+		},
+		{
+			name:    "wildcard never allows credentials",
+			config:  wildcard,
+			method:  http.MethodGet,
+			origin:  "https://any.example.net",
+			handled: false,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+			},
+			vary: []string{"Origin"},
+		},
+		{
+			name:    "wildcard with listed origins echoes unlisted origins without credentials",
+			config:  mixed,
+			method:  http.MethodGet,
+			origin:  "https://any.example.net",
+			handled: false,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://any.example.net",
+				"Access-Control-Allow-Credentials": "",
+			},
+			vary: []string{"Origin"},
+		},
+		{
+			name:    "wildcard with listed origins allows credentials for listed origins",
+			config:  mixed,
+			method:  http.MethodGet,
+			origin:  "https://app.example.com",
+			handled: false,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+			},
+			vary: []string{"Origin"},
+		},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "/v1/widgets", nil)
+		if test.origin != "" {
+			req.Header.Set("Origin", test.origin)
+		}
+		if test.requestMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", test.requestMethod)
+		}
+		if test.requestHeaders != "" {
+			req.Header.Set("Access-Control-Request-Headers", test.requestHeaders)
+		}
+		rw := httptest.NewRecorder()
+
+		assert.Equal(t, test.handled, test.config.handle(rw, req), test.name)
+		if test.handled {
+			assert.Equal(t, test.code, rw.Code, test.name)
+		}
+		for header, value := range test.expected {
+			assert.Equal(t, value, rw.Header().Get(header), test.name+" "+header)
+		}
+		assert.Equal(t, test.vary, rw.Header()["Vary"], test.name)
+	}
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rancher/norman/types"
+	"github.com/sirupsen/logrus"
+)
+
+// DebugConfig enables the debug endpoints below PathPrefix, /debug if unset:
+//
+//	pprof/            the available profiles
+//	pprof/profile     a CPU profile over ?seconds=, 30 by default
+//	pprof/<name>      the named profile, as text with ?debug=1
+//	goroutines        the stacks of all goroutines
+//	schemas           the served schemas with their stores and handlers
+//
+// Callers are identified by the Authenticator of the server and must pass Authorize, without it every request is
+// denied.
+type DebugConfig struct {
+	PathPrefix string
+	Authorize  func(identity *types.Identity, req *http.Request) bool
+}
+
+// DebugGroups authorizes callers that are a member of one of groups.
+func DebugGroups(groups ...string) func(identity *types.Identity, req *http.Request) bool {
+	return func(identity *types.Identity, req *http.Request) bool {
+		if identity == nil {
+			return false
+		}
+		for _, group := range identity.Groups {
+			for _, allowed := range groups {
+				if group == allowed {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+func (c *DebugConfig) prefix() string {
+	if c.PathPrefix == "" {
+		return "/debug/"
+	}
+	return strings.TrimSuffix(c.PathPrefix, "/") + "/"
+}
+
+// handleDebug serves the request if it is for a debug endpoint.
+func (s *Server) handleDebug(rw http.ResponseWriter, req *http.Request) bool {
+	prefix := s.Debug.prefix()
+	if !strings.HasPrefix(req.URL.Path, prefix) {
+		return false
+	}
+
+	var identity *types.Identity
+	if s.Authenticator != nil {
+		var ok bool
+		identity, ok, _ = s.Authenticator.Authenticate(req)
+		if !ok {
+			http.Error(rw, "authentication required", http.StatusUnauthorized)
+			return true
+		}
+	}
+	if s.Debug.Authorize == nil || !s.Debug.Authorize(identity, req) {
+		http.Error(rw, "forbidden", http.StatusForbidden)
+		return true
+	}
+
+	endpoint := strings.TrimPrefix(req.URL.Path, prefix)
+	logrus.Infof("Serving debug endpoint %s", endpoint)
+	switch {
+	case endpoint == "goroutines":
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		pprof.Lookup("goroutine").WriteTo(rw, 2)
+	case endpoint == "schemas":
+		s.writeDebugSchemas(rw)
+	case endpoint == "pprof" || endpoint == "pprof/":
+		writeProfileIndex(rw)
+	case endpoint == "pprof/profile":
+		writeCPUProfile(rw, req)
+	case strings.HasPrefix(endpoint, "pprof/"):
+		writeProfile(rw, req, strings.TrimPrefix(endpoint, "pprof/"))
+	default:
+		http.NotFound(rw, req)
+	}
+	return true
+}
+
+func writeProfileIndex(rw http.ResponseWriter) {
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(rw, "profile")
+	for _, profile := range pprof.Profiles() {
+		fmt.Fprintf(rw, "%s %d\n", profile.Name(), profile.Count())
+	}
+}
+
+func writeCPUProfile(rw http.ResponseWriter, req *http.Request) {
+	seconds, err := strconv.Atoi(req.URL.Query().Get("seconds"))
+	if err != nil || seconds <= 0 {
+		seconds = 30
+	}
+
+	rw.Header().Set("Content-Type", "application/octet-stream")
+	if err := pprof.StartCPUProfile(rw); err != nil {
+		http.Error(rw, "failed to start CPU profile: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	select {
+	case <-time.After(time.Duration(seconds) * time.Second):
+	case <-req.Context().Done():
+	}
+	pprof.StopCPUProfile()
+}
+
+func writeProfile(rw http.ResponseWriter, req *http.Request, name string) {
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		http.NotFound(rw, req)
+		return
+	}
+
+	debug, _ := strconv.Atoi(req.URL.Query().Get("debug"))
+	if name == "heap" && req.URL.Query().Get("gc") != "" {
+		runtime.GC()
+	}
+	if debug > 0 {
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		rw.Header().Set("Content-Type", "application/octet-stream")
+	}
+	profile.WriteTo(rw, debug)
+}
+
+type debugSchema struct {
+	ID           string            `json:"id"`
+	Version      string            `json:"version"`
+	Store        string            `json:"store,omitempty"`
+	Handlers     map[string]string `json:"handlers,omitempty"`
+	VerbHandlers map[string]int    `json:"verbHandlers,omitempty"`
+}
+
+func (s *Server) writeDebugSchemas(rw http.ResponseWriter) {
+	schemas := s.CurrentSchemas()
+
+	var result []debugSchema
+	for _, schema := range schemas.Schemas() {
+		handlers := map[string]string{}
+		for name, handler := range map[string]interface{}{
+			"action":              schema.ActionHandler,
+			"list":                schema.ListHandler,
+			"link":                schema.LinkHandler,
+			"create":              schema.CreateHandler,
+			"update":              schema.UpdateHandler,
+			"delete":              schema.DeleteHandler,
+			"error":               schema.ErrorHandler,
+			"inputFormatter":      schema.InputFormatter,
+			"formatter":           schema.Formatter,
+			"collectionFormatter": schema.CollectionFormatter,
+			"validator":           schema.Validator,
+		} {
+			if funcName := funcName(handler); funcName != "" {
+				handlers[name] = funcName
+			}
+		}
+
+		verbHandlers := map[string]int{}
+		for verb, handlers := range schema.VerbHandlers {
+			verbHandlers[verb] = len(handlers)
+		}
+
+		debug := debugSchema{
+			ID:           schema.ID,
+			Version:      schema.Version.Path,
+			Handlers:     handlers,
+			VerbHandlers: verbHandlers,
+		}
+		if schema.Store != nil {
+			debug.Store = fmt.Sprintf("%T", schema.Store)
+		}
+		result = append(result, debug)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Version == result[j].Version {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].Version < result[j].Version
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(result)
+}
+
+func funcName(f interface{}) string {
+	value := reflect.ValueOf(f)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return ""
+	}
+	if fn := runtime.FuncForPC(value.Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
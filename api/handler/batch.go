@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/rancher/norman/httperror"
+	ehandler "github.com/rancher/norman/httperror/handler"
+	"github.com/rancher/norman/types"
+)
+
+// MaxBatchSize is the most objects one POST of an array to a collection may create.
+var MaxBatchSize = 100
+
+// batchCreate creates every object of items on its own. The response lists the created object or the error of
+// each item in order, with status 201 if all were created and 207 otherwise.
+func batchCreate(apiContext *types.APIContext, store types.Store, items []map[string]interface{}) error {
+	if len(items) > MaxBatchSize {
+		return httperror.NewAPIError(httperror.MaxLimitExceeded,
+			"batch of "+strconv.Itoa(len(items))+" objects exceeds the limit of "+strconv.Itoa(MaxBatchSize))
+	}
+
+	results := make([]interface{}, 0, len(items))
+	status := http.StatusCreated
+	for _, item := range items {
+		data, err := ValidateBody(apiContext, item, true)
+		if err == nil {
+			data, err = store.Create(apiContext, apiContext.Schema, data)
+		}
+		if err != nil {
+			results = append(results, ehandler.ErrorData(err))
+			status = http.StatusMultiStatus
+			continue
+		}
+		results = append(results, data)
+	}
+
+	apiContext.WriteResponse(status, results)
+	return nil
+}
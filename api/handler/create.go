@@ -4,20 +4,27 @@ import (
 	"net/http"
 
 	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/parse"
 	"github.com/rancher/norman/types"
 )
 
 func CreateHandler(apiContext *types.APIContext, next types.RequestHandler) error {
-	var err error
+	store := apiContext.Schema.Store
+	if store == nil {
+		return httperror.NewAPIError(httperror.NotFound, "no store found")
+	}
 
-	data, err := ParseAndValidateBody(apiContext, true)
+	items, err := parse.ReadBodyList(apiContext.Request)
 	if err != nil {
 		return err
 	}
+	if items != nil {
+		return batchCreate(apiContext, store, items)
+	}
 
-	store := apiContext.Schema.Store
-	if store == nil {
-		return httperror.NewAPIError(httperror.NotFound, "no store found")
+	data, err := ParseAndValidateBody(apiContext, true)
+	if err != nil {
+		return err
 	}
 
 	data, err = store.Create(apiContext, apiContext.Schema, data)
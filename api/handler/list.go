@@ -20,6 +20,9 @@ func ListHandler(request *types.APIContext, next types.RequestHandler) error {
 	}
 
 	if request.ID == "" {
+		if err := parse.ValidateFilter(request, request.Schema); err != nil {
+			return err
+		}
 		opts := parse.QueryOptions(request, request.Schema)
 		// Save the pagination on the context so it's not reset later
 		request.Pagination = opts.Pagination
@@ -63,6 +63,9 @@ func ApplyPagination(pagination *types.Pagination, data []map[string]interface{}
 	}
 
 	total := int64(len(data))
+	if total <= limit {
+		pagination.LimitExceeded = false
+	}
 
 	// Reset fields
 	pagination.Next = ""
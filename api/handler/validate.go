@@ -12,6 +12,12 @@ func ParseAndValidateBody(apiContext *types.APIContext, create bool) (map[string
 		return nil, err
 	}
 
+	return ValidateBody(apiContext, data, create)
+}
+
+// ValidateBody validates and converts data, an already parsed body, like ParseAndValidateBody.
+func ValidateBody(apiContext *types.APIContext, data map[string]interface{}, create bool) (map[string]interface{}, error) {
+	var err error
 	if create {
 		for key, value := range apiContext.SubContextAttributeProvider.Create(apiContext, apiContext.Schema) {
 			if data == nil {
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotentBody limits the bodies of requests with an Idempotency-Key, which are read into memory to be
+	// hashed, and of the responses cached for them. Larger responses are not cached.
+	maxIdempotentBody = 2 << 20
+	// maxIdempotentEntries limits the cached responses, requests are handled without caching once it is reached.
+	maxIdempotentEntries = 10000
+	// idempotencySweepInterval is how often expired responses are removed from the cache.
+	idempotencySweepInterval = time.Minute
+)
+
+type idempotentResponse struct {
+	done     chan struct{}
+	bodyHash string
+	code     int
+	header   http.Header
+	body     []byte
+	expires  time.Time
+}
+
+type idempotencyCache struct {
+	sync.Mutex
+	entries   map[string]*idempotentResponse
+	nextSweep time.Time
+}
+
+// get returns the cached response for key and true, or registers a new pending entry for a request with the
+// body hash and returns false if the caller should handle the request itself. The entry is nil if the cache is
+// full.
+func (c *idempotencyCache) get(key, bodyHash string) (*idempotentResponse, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]*idempotentResponse{}
+	}
+
+	now := time.Now()
+	if now.After(c.nextSweep) || len(c.entries) >= maxIdempotentEntries {
+		c.sweep(now)
+	}
+
+	if entry, ok := c.entries[key]; ok {
+		return entry, true
+	}
+	if len(c.entries) >= maxIdempotentEntries {
+		return nil, false
+	}
+
+	entry := &idempotentResponse{
+		done:     make(chan struct{}),
+		bodyHash: bodyHash,
+	}
+	c.entries[key] = entry
+	return entry, false
+}
+
+// sweep removes expired responses, at most once per idempotencySweepInterval unless the cache is full.
+func (c *idempotencyCache) sweep(now time.Time) {
+	for k, entry := range c.entries {
+		if !entry.expires.IsZero() && now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.nextSweep = now.Add(idempotencySweepInterval)
+}
+
+// complete stores the response of a pending entry and wakes up the requests waiting for it.
+func (c *idempotencyCache) complete(entry *idempotentResponse, code int, header http.Header, body []byte, expires time.Time) {
+	c.Lock()
+	entry.code = code
+	entry.header = header
+	entry.body = body
+	entry.expires = expires
+	c.Unlock()
+	close(entry.done)
+}
+
+func (c *idempotencyCache) remove(key string, entry *idempotentResponse) {
+	c.Lock()
+	delete(c.entries, key)
+	c.Unlock()
+	close(entry.done)
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	// Only as much is kept as needed to tell that a response is too large to be cached
+	if remaining := maxIdempotentBody + 1 - r.body.Len(); remaining > 0 {
+		if len(b) < remaining {
+			remaining = len(b)
+		}
+		r.body.Write(b[:remaining])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+type identityKey struct{}
+
+// serveIdempotent handles a POST carrying an Idempotency-Key header. The first response for a key is cached for
+// IdempotencyWindow and replayed for retries by the same caller, concurrent retries wait for the first request to
+// finish. Reusing a key with a different body is rejected. Server errors are not cached so they can be retried.
+func (s *Server) serveIdempotent(rw http.ResponseWriter, req *http.Request, key string) {
+	caller, ok := s.idempotencyCaller(req)
+	if !ok {
+		// Handled normally, which rejects the request as unauthenticated
+		s.serveRequest(rw, req)
+		return
+	}
+	if s.Authenticator != nil {
+		req = req.WithContext(context.WithValue(req.Context(), identityKey{}, caller))
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxIdempotentBody+1))
+	if err != nil {
+		s.reject(rw, req, httperror.WrapAPIError(err, httperror.InvalidBodyContent, "failed to read body"))
+		return
+	}
+	if len(body) > maxIdempotentBody {
+		s.reject(rw, req, httperror.NewAPIError(httperror.MaxLengthExceeded, "body is too large for a request with "+idempotencyKeyHeader))
+		return
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	bodyHash := hex.EncodeToString(sum[:])
+
+	user, groups := Impersonation(req)
+	cacheKey := strings.Join([]string{
+		caller.Name,
+		strings.Join(caller.Groups, ","),
+		user,
+		strings.Join(groups, ","),
+		req.Method,
+		req.URL.String(),
+		key,
+	}, "\x00")
+
+	entry, cached := s.idempotency.get(cacheKey, bodyHash)
+	if entry == nil {
+		s.serveRequest(rw, req)
+		return
+	}
+	if cached {
+		if entry.bodyHash != bodyHash {
+			s.reject(rw, req, httperror.NewAPIError(httperror.InvalidBodyContent,
+				idempotencyKeyHeader+" was already used for a request with a different body"))
+			return
+		}
+
+		<-entry.done
+		if entry.code != 0 {
+			for k, v := range entry.header {
+				rw.Header()[k] = v
+			}
+			rw.Header().Set(idempotencyReplayedHeader, "true")
+			rw.WriteHeader(entry.code)
+			rw.Write(entry.body)
+			return
+		}
+		s.serveRequest(rw, req)
+		return
+	}
+
+	rec := &responseRecorder{ResponseWriter: rw}
+	defer func() {
+		if rec.code == 0 || rec.code >= http.StatusInternalServerError || rec.body.Len() > maxIdempotentBody {
+			s.idempotency.remove(cacheKey, entry)
+			return
+		}
+		header := http.Header{}
+		for k, v := range rw.Header() {
+			header[k] = v
+		}
+		s.idempotency.complete(entry, rec.code, header, rec.body.Bytes(), time.Now().Add(s.IdempotencyWindow))
+	}()
+
+	s.serveRequest(rec, req)
+}
+
+// idempotencyCaller returns who sent the request, as authenticated by the Authenticator of the server or, without
+// one, from the headers of the proxy in front of it. It returns false if the request can not be authenticated.
+func (s *Server) idempotencyCaller(req *http.Request) (*types.Identity, bool) {
+	if s.Authenticator == nil {
+		user, groups := Impersonation(req)
+		return &types.Identity{
+			Name:   user,
+			Groups: groups,
+		}, true
+	}
+
+	identity, ok, _ := s.Authenticator.Authenticate(req)
+	if !ok || identity == nil {
+		return nil, false
+	}
+	return identity, true
+}
+
+// reject writes err as the response to a request that is not handled.
+func (s *Server) reject(rw http.ResponseWriter, req *http.Request, err error) {
+	apiContext, parseErr := s.Parser(rw, req)
+	if parseErr != nil {
+		err = parseErr
+	}
+	s.handleError(apiContext, err)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/api/authn"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	server, err := NewServer(
+		WithSchemas(types.NewSchemas().MustImport(&version, widget{})),
+		WithDefaultStore(memory.NewStore()),
+		WithAuthenticator(authn.AuthenticatorFunc(func(req *http.Request) (*types.Identity, bool, error) {
+			user := authn.BearerToken(req)
+			return &types.Identity{Name: user}, user != "", nil
+		})),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	server.IdempotencyWindow = time.Minute
+
+	create := func(user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/widgets", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+user)
+		req.Header.Set(idempotencyKeyHeader, "key")
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := create("alice", `{"name":"a"}`)
+	assert.Equal(t, http.StatusCreated, rw.Code)
+	assert.Equal(t, "", rw.Header().Get(idempotencyReplayedHeader))
+
+	rw = create("alice", `{"name":"a"}`)
+	assert.Equal(t, http.StatusCreated, rw.Code)
+	assert.Equal(t, "true", rw.Header().Get(idempotencyReplayedHeader))
+
+	rw = create("alice", `{"name":"b"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rw.Code)
+
+	rw = create("bob", `{"name":"c"}`)
+	assert.Equal(t, http.StatusCreated, rw.Code)
+	assert.Equal(t, "", rw.Header().Get(idempotencyReplayedHeader))
+
+	rw = create("", `{"name":"a"}`)
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+}
+
+func TestIdempotencyCacheLimits(t *testing.T) {
+	c := &idempotencyCache{}
+	for i := 0; i < maxIdempotentEntries; i++ {
+		entry, cached := c.get(strconv.Itoa(i), "hash")
+		if !assert.NotNil(t, entry) || !assert.False(t, cached) {
+			return
+		}
+		c.complete(entry, http.StatusCreated, http.Header{}, nil, time.Now().Add(time.Minute))
+	}
+
+	entry, cached := c.get("full", "hash")
+	assert.Nil(t, entry)
+	assert.False(t, cached)
+
+	entry, cached = c.get("0", "hash")
+	if assert.True(t, cached) {
+		assert.Equal(t, http.StatusCreated, entry.code)
+	}
+
+	c.Lock()
+	for _, entry := range c.entries {
+		entry.expires = time.Now().Add(-time.Second)
+	}
+	c.Unlock()
+	entry, cached = c.get("full", "hash")
+	assert.NotNil(t, entry)
+	assert.False(t, cached)
+	assert.Len(t, c.entries, 1)
+}
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	server, err := NewServer(
+		WithSchemas(types.NewSchemas().MustImport(&version, widget{})),
+		WithDefaultStore(memory.NewStore()),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	server.IdempotencyWindow = time.Minute
+
+	body := `{"name":"a","padding":"` + strings.Repeat("x", maxIdempotentBody) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/widgets", strings.NewReader(body))
+	req.Header.Set(idempotencyKeyHeader, "key")
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rw.Code)
+	assert.Empty(t, server.idempotency.entries)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+const (
+	ImpersonateUserHeader  = "Impersonate-User"
+	ImpersonateGroupHeader = "Impersonate-Group"
+)
+
+// ImpersonationAuthorizer returns an error if the caller of the request may not act as user and groups. The
+// caller is apiContext.Identity if the server has an Authenticator, else it is identified from the request.
+type ImpersonationAuthorizer func(apiContext *types.APIContext, user string, groups []string) error
+
+// Impersonation returns the user and groups the request acts as. Stores and access control use the same headers,
+// so a validated identity applies to everything the request does.
+func Impersonation(req *http.Request) (string, []string) {
+	return req.Header.Get(ImpersonateUserHeader), req.Header[http.CanonicalHeaderKey(ImpersonateGroupHeader)]
+}
+
+// checkImpersonation validates the Impersonate-* headers of the request. A server with an Authenticator rejects
+// them unless an ImpersonationAuthorizer is set, and then replaces the headers by the identity the request acts as, so stores and access control never see
+// identities sent by the client that were not authorized.
+func (s *Server) checkImpersonation(apiContext *types.APIContext) error {
+	user, groups := Impersonation(apiContext.Request)
+	impersonating := user != "" || len(groups) > 0
+
+	if impersonating && s.ImpersonationAuthorizer == nil && s.Authenticator != nil {
+		return httperror.NewAPIError(httperror.PermissionDenied, "impersonation is not allowed")
+	}
+
+	if impersonating && s.ImpersonationAuthorizer != nil {
+		if err := s.ImpersonationAuthorizer(apiContext, user, groups); err != nil {
+			if _, ok := err.(*httperror.APIError); ok {
+				return err
+			}
+			return httperror.WrapAPIError(err, httperror.PermissionDenied, "can not impersonate "+user)
+		}
+		if apiContext.Identity != nil {
+			if user == "" {
+				user = apiContext.Identity.Name
+			}
+			apiContext.Identity = &types.Identity{
+				Name:   user,
+				Groups: groups,
+			}
+		}
+	}
+
+	if s.Authenticator != nil {
+		setImpersonation(apiContext.Request.Header, apiContext.Identity)
+	}
+	return nil
+}
+
+// setImpersonation replaces the Impersonate-* headers with identity, or removes them if identity is nil.
+func setImpersonation(header http.Header, identity *types.Identity) {
+	for key := range header {
+		if strings.HasPrefix(key, "Impersonate-") {
+			delete(header, key)
+		}
+	}
+	if identity == nil {
+		return
+	}
+
+	header.Set(ImpersonateUserHeader, identity.Name)
+	for _, group := range identity.Groups {
+		header.Add(ImpersonateGroupHeader, group)
+	}
+}
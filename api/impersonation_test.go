@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher/norman/api/authn"
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonation(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+
+	var seenUser string
+	recordUser := store.Middleware{
+		List: func(next store.ListFunc) store.ListFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+				seenUser = apiContext.Request.Header.Get(ImpersonateUserHeader)
+				return next(apiContext, schema, opt)
+			}
+		},
+	}
+	alice := authn.AuthenticatorFunc(func(req *http.Request) (*types.Identity, bool, error) {
+		return &types.Identity{Name: "alice", Groups: []string{"users"}}, true, nil
+	})
+
+	newServer := func(opts ...Option) *Server {
+		server, err := NewServer(append(opts,
+			WithSchemas(types.NewSchemas().MustImport(&version, widget{})),
+			WithDefaultStore(memory.NewStore()),
+			WithStoreMiddleware(recordUser),
+			WithAuthenticator(alice),
+		)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return server
+	}
+	list := func(server *Server, impersonate string) int {
+		seenUser = ""
+		req := httptest.NewRequest(http.MethodGet, "/v1/widgets", nil)
+		if impersonate != "" {
+			req.Header.Set(ImpersonateUserHeader, impersonate)
+			req.Header.Set(ImpersonateGroupHeader, "system:masters")
+		}
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	server := newServer()
+	assert.Equal(t, http.StatusOK, list(server, ""))
+	assert.Equal(t, "alice", seenUser)
+
+	assert.Equal(t, http.StatusForbidden, list(server, "bob"))
+	assert.Equal(t, "", seenUser)
+
+	server = newServer(func(s *Server) error {
+		s.ImpersonationAuthorizer = func(apiContext *types.APIContext, user string, groups []string) error {
+			if user != "bob" {
+				return errors.New("denied")
+			}
+			return nil
+		}
+		return nil
+	})
+	assert.Equal(t, http.StatusOK, list(server, "bob"))
+	assert.Equal(t, "bob", seenUser)
+
+	assert.Equal(t, http.StatusForbidden, list(server, "carol"))
+	assert.Equal(t, "", seenUser)
+}
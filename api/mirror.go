@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxMirroredBody is how much of a response body is kept for comparing with the mirrored response.
+const maxMirroredBody = 1 << 20
+
+// MirrorConfig mirrors read requests to Target, for example a server with a new store or schema version, to
+// compare its responses with the ones served. The mirrored responses are discarded.
+type MirrorConfig struct {
+	// Percentage of the GET requests mirrored, from 0 to 100.
+	Percentage float64
+	Target     http.Handler
+	// Timeout of mirrored requests, 10 seconds by default.
+	Timeout time.Duration
+	// MaxInFlight limits the mirrored requests running at once, further requests are not mirrored. The default
+	// is 10.
+	MaxInFlight int32
+	// Compare returns a description of how the mirrored response differs, or "" if it matches. By default the
+	// status codes and the bodies, as JSON if possible, are compared.
+	Compare func(served, mirrored *MirroredResponse) string
+	// ForwardCredentials sends the Authorization, Proxy-Authorization and Cookie headers of requests to Target.
+	// They are removed by default, so a remote target never receives the credentials of clients.
+	ForwardCredentials bool
+}
+
+var credentialHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+}
+
+// mirrorable reports if req is a GET whose response ends, watches and streams are never mirrored.
+func mirrorable(req *http.Request) bool {
+	if req.Method != http.MethodGet || req.Header.Get("Upgrade") != "" {
+		return false
+	}
+	if _, ok := req.URL.Query()["watch"]; ok {
+		return false
+	}
+	accept := req.Header.Get("Accept")
+	return !strings.Contains(accept, "text/event-stream") && !strings.Contains(accept, "application/x-ndjson")
+}
+
+func copyHeader(header http.Header) http.Header {
+	result := http.Header{}
+	for k, v := range header {
+		result[k] = append([]string{}, v...)
+	}
+	return result
+}
+
+// MirroredResponse is a response captured for comparison. Body holds at most the first megabyte.
+type MirroredResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// RemoteMirrorTarget returns a target for MirrorConfig forwarding requests to the server at rawURL.
+func RemoteMirrorTarget(rawURL string) (http.Handler, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return httputil.NewSingleHostReverseProxy(u), nil
+}
+
+// Mirror returns middleware mirroring requests as configured by config, see Server.Middleware.
+func Mirror(config MirrorConfig) func(http.Handler) http.Handler {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxInFlight <= 0 {
+		config.MaxInFlight = 10
+	}
+	if config.Compare == nil {
+		config.Compare = compareMirrored
+	}
+
+	var inFlight int32
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if !mirrorable(req) || rand.Float64()*100 >= config.Percentage {
+				next.ServeHTTP(rw, req)
+				return
+			}
+			if atomic.AddInt32(&inFlight, 1) > config.MaxInFlight {
+				atomic.AddInt32(&inFlight, -1)
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			tee := &teeResponseWriter{
+				ResponseWriter: rw,
+				status:         http.StatusOK,
+			}
+			next.ServeHTTP(tee, req)
+
+			served := &MirroredResponse{
+				Status: tee.status,
+				Header: copyHeader(rw.Header()),
+				Body:   tee.body.Bytes(),
+			}
+			mirrored := req.WithContext(context.Background())
+			mirrored.Header = copyHeader(req.Header)
+			if !config.ForwardCredentials {
+				for _, header := range credentialHeaders {
+					mirrored.Header.Del(header)
+				}
+			}
+			go func() {
+				defer atomic.AddInt32(&inFlight, -1)
+				config.mirror(mirrored, served)
+			}()
+		})
+	}
+}
+
+func (c *MirrorConfig) mirror(req *http.Request, served *MirroredResponse) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.Timeout)
+	defer cancel()
+
+	recorder := httptest.NewRecorder()
+	c.Target.ServeHTTP(recorder, req.WithContext(ctx))
+	if ctx.Err() != nil {
+		logrus.Warnf("Mirrored request %s %s timed out after %v", req.Method, req.URL, c.Timeout)
+		return
+	}
+
+	body := recorder.Body.Bytes()
+	if len(body) > maxMirroredBody {
+		body = body[:maxMirroredBody]
+	}
+	mirrored := &MirroredResponse{
+		Status: recorder.Code,
+		Header: recorder.Header(),
+		Body:   body,
+	}
+	if diff := c.Compare(served, mirrored); diff != "" {
+		logrus.Warnf("Mirrored request %s %s differs: %s", req.Method, req.URL, diff)
+	}
+}
+
+func compareMirrored(served, mirrored *MirroredResponse) string {
+	if served.Status != mirrored.Status {
+		return "status " + http.StatusText(served.Status) + " != " + http.StatusText(mirrored.Status)
+	}
+
+	var servedJSON, mirroredJSON interface{}
+	if json.Unmarshal(served.Body, &servedJSON) == nil && json.Unmarshal(mirrored.Body, &mirroredJSON) == nil {
+		if !reflect.DeepEqual(servedJSON, mirroredJSON) {
+			return "JSON bodies differ"
+		}
+		return ""
+	}
+
+	if !bytes.Equal(served.Body, mirrored.Body) {
+		return "bodies differ"
+	}
+	return ""
+}
+
+// teeResponseWriter keeps a copy of the status and the start of the body written.
+type teeResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (t *teeResponseWriter) WriteHeader(code int) {
+	t.status = code
+	t.ResponseWriter.WriteHeader(code)
+}
+
+func (t *teeResponseWriter) Write(data []byte) (int, error) {
+	if remaining := maxMirroredBody - t.body.Len(); remaining > 0 {
+		if len(data) < remaining {
+			remaining = len(data)
+		}
+		t.body.Write(data[:remaining])
+	}
+	return t.ResponseWriter.Write(data)
+}
+
+func (t *teeResponseWriter) Flush() {
+	if flusher, ok := t.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rancher/norman/api/authn"
+	"github.com/rancher/norman/metrics"
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/types"
+)
+
+// Option configures a server created by NewServer.
+type Option func(*Server) error
+
+// NewServer creates a server like NewAPIServer configured by opts. Schemas given with WithSchemas are added last,
+// so the stores and handlers set by the other options apply to them regardless of the order of opts.
+func NewServer(opts ...Option) (*Server, error) {
+	s := NewAPIServer()
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	pending := s.pendingSchemas
+	s.pendingSchemas = nil
+	for _, schemas := range pending {
+		if err := s.AddSchemas(schemas); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// WithSchemas serves schemas.
+func WithSchemas(schemas *types.Schemas) Option {
+	return func(s *Server) error {
+		s.pendingSchemas = append(s.pendingSchemas, schemas)
+		return nil
+	}
+}
+
+// WithDefaultStore sets the store of schemas that have none.
+func WithDefaultStore(store types.Store) Option {
+	return func(s *Server) error {
+		s.Defaults.Store = store
+		return nil
+	}
+}
+
+// WithStoreWrapper replaces the wrapper applied to the store of every schema, wrapper.Wrap by default.
+func WithStoreWrapper(wrapper StoreWrapper) Option {
+	return func(s *Server) error {
+		s.StoreWrapper = wrapper
+		return nil
+	}
+}
+
+// WithStoreMiddleware wraps the store of every schema with middlewares, the first one being the outermost.
+func WithStoreMiddleware(middlewares ...store.Middleware) Option {
+	return func(s *Server) error {
+		s.StoreMiddleware = append(s.StoreMiddleware, middlewares...)
+		return nil
+	}
+}
+
+// WithMiddleware wraps the request handling of the server with middlewares, the first one being the outermost.
+func WithMiddleware(middlewares ...func(http.Handler) http.Handler) Option {
+	return func(s *Server) error {
+		s.Middleware = append(s.Middleware, middlewares...)
+		return nil
+	}
+}
+
+func WithAccessControl(accessControl types.AccessControl) Option {
+	return func(s *Server) error {
+		s.AccessControl = accessControl
+		return nil
+	}
+}
+
+func WithAuthenticator(authenticator authn.Authenticator) Option {
+	return func(s *Server) error {
+		s.Authenticator = authenticator
+		return nil
+	}
+}
+
+// WithLimits sets the default and maximum page sizes of schemas that don't set their own.
+func WithLimits(limit, maxLimit int64) Option {
+	return func(s *Server) error {
+		s.Defaults.Limit = limit
+		s.Defaults.MaxLimit = maxLimit
+		return nil
+	}
+}
+
+// WithSlowRequestThreshold logs requests taking longer than threshold with the time spent in stores.
+func WithSlowRequestThreshold(threshold time.Duration) Option {
+	return func(s *Server) error {
+		s.SlowRequestThreshold = threshold
+		return nil
+	}
+}
+
+// WithStoreMetrics records the prometheus store metrics, as if NORMAN_STORE_METRICS was set.
+func WithStoreMetrics() Option {
+	return func(s *Server) error {
+		metrics.EnableStoreMetrics()
+		return nil
+	}
+}
+
+// WithErrorHandler sets how errors of schemas without their own error handler are written.
+func WithErrorHandler(errorHandler types.ErrorHandler) Option {
+	return func(s *Server) error {
+		s.Defaults.ErrorHandler = errorHandler
+		return nil
+	}
+}
+
+// WithResponseWriter serves the response format, as selected by the _format query parameter, with writer.
+func WithResponseWriter(format string, writer ResponseWriter) Option {
+	return func(s *Server) error {
+		s.ResponseWriters[format] = writer
+		return nil
+	}
+}
+
+// WithDebug enables the debug endpoints, see DebugConfig.
+func WithDebug(config DebugConfig) Option {
+	return func(s *Server) error {
+		s.Debug = &config
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type widget struct {
+	types.Resource
+	Name string `json:"name"`
+}
+
+func TestNewServer(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().MustImport(&version, widget{})
+
+	var order []string
+	middleware := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(rw, req)
+			})
+		}
+	}
+
+	server, err := NewServer(
+		WithSchemas(schemas),
+		WithDefaultStore(memory.NewStore()),
+		WithLimits(10, 20),
+		WithMiddleware(middleware("outer"), middleware("inner")),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	schema := server.CurrentSchemas().Schema(&version, "widget")
+	if assert.NotNil(t, schema) {
+		assert.NotNil(t, schema.Store)
+		assert.Equal(t, int64(10), schema.DefaultLimit)
+	}
+
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/widgets", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+}
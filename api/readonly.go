@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+const readOnlyRetryAfter = "60"
+
+type readOnlyState struct {
+	sync.RWMutex
+	all     bool
+	schemas map[string]bool
+}
+
+// SetReadOnly toggles read-only mode for the whole server. While enabled all mutating requests are rejected
+// with a 503, reads and watches keep working.
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.readOnly.Lock()
+	defer s.readOnly.Unlock()
+	s.readOnly.all = readOnly
+}
+
+// SetSchemaReadOnly toggles read-only mode for a single schema ID.
+func (s *Server) SetSchemaReadOnly(schemaID string, readOnly bool) {
+	s.readOnly.Lock()
+	defer s.readOnly.Unlock()
+	if s.readOnly.schemas == nil {
+		s.readOnly.schemas = map[string]bool{}
+	}
+	if readOnly {
+		s.readOnly.schemas[schemaID] = true
+	} else {
+		delete(s.readOnly.schemas, schemaID)
+	}
+}
+
+func (s *Server) IsReadOnly(schemaID string) bool {
+	s.readOnly.RLock()
+	defer s.readOnly.RUnlock()
+	return s.readOnly.all || s.readOnly.schemas[schemaID]
+}
+
+func (s *Server) checkReadOnly(apiContext *types.APIContext) error {
+	switch apiContext.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	schemaID := ""
+	if apiContext.Schema != nil {
+		schemaID = apiContext.Schema.ID
+	}
+	if !s.IsReadOnly(schemaID) {
+		return nil
+	}
+
+	apiContext.Response.Header().Set("Retry-After", readOnlyRetryAfter)
+	return httperror.NewAPIError(httperror.ServiceUnavailable, "server is in read-only mode")
+}
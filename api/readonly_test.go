@@ -0,0 +1,127 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type gadget struct {
+	types.Resource
+	Name string `json:"name"`
+}
+
+func newReadOnlyServer(t *testing.T) *Server {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().
+		MustImportAndCustomize(&version, widget{}, func(schema *types.Schema) {
+			schema.ResourceActions = map[string]types.Action{"poke": {}}
+			schema.ActionHandler = func(actionName string, action *types.Action, apiContext *types.APIContext) error {
+				apiContext.WriteResponse(http.StatusOK, nil)
+				return nil
+			}
+		}).
+		MustImport(&version, gadget{})
+
+	server, err := NewServer(WithSchemas(schemas), WithDefaultStore(memory.NewStore()))
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	for _, path := range []string{"/v1/widgets", "/v1/gadgets"} {
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":"a"}`)))
+		assert.Equal(t, http.StatusCreated, rw.Code, path)
+	}
+	return server
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		readOnly bool
+	}{
+		{"list", http.MethodGet, "/v1/widgets", "", false},
+		{"get", http.MethodGet, "/v1/widgets/a", "", false},
+		{"create", http.MethodPost, "/v1/widgets", `{"name":"b"}`, true},
+		{"update", http.MethodPut, "/v1/widgets/a", `{"name":"a"}`, true},
+		{"delete", http.MethodDelete, "/v1/widgets/a", "", true},
+		{"delete by method override", http.MethodGet, "/v1/widgets/a?_method=DELETE", "", true},
+		{"delete by remove action", http.MethodPost, "/v1/widgets/a?action=remove", "", true},
+		{"action", http.MethodPost, "/v1/widgets/a?action=poke", "", true},
+	}
+
+	server := newReadOnlyServer(t)
+	if server == nil {
+		return
+	}
+	server.SetReadOnly(true)
+	assert.True(t, server.IsReadOnly("widget"))
+
+	for _, test := range tests {
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+		if test.readOnly {
+			assert.Equal(t, http.StatusServiceUnavailable, rw.Code, test.name)
+			assert.Equal(t, readOnlyRetryAfter, rw.Header().Get("Retry-After"), test.name)
+		} else {
+			assert.Equal(t, http.StatusOK, rw.Code, test.name)
+			assert.Empty(t, rw.Header().Get("Retry-After"), test.name)
+		}
+	}
+
+	// Nothing was written while read-only
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/widgets/a", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	rw = httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/widgets/b", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	server.SetReadOnly(false)
+	assert.False(t, server.IsReadOnly("widget"))
+	rw = httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/widgets", strings.NewReader(`{"name":"b"}`)))
+	assert.Equal(t, http.StatusCreated, rw.Code)
+}
+
+func TestSchemaReadOnly(t *testing.T) {
+	server := newReadOnlyServer(t)
+	if server == nil {
+		return
+	}
+	server.SetSchemaReadOnly("widget", true)
+	assert.True(t, server.IsReadOnly("widget"))
+	assert.False(t, server.IsReadOnly("gadget"))
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/v1/widgets", http.StatusServiceUnavailable},
+		{"/v1/gadgets", http.StatusCreated},
+	}
+	for _, test := range tests {
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(`{"name":"b"}`)))
+		assert.Equal(t, test.expected, rw.Code, test.path)
+	}
+
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/widgets", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	server.SetSchemaReadOnly("widget", false)
+	assert.False(t, server.IsReadOnly("widget"))
+	rw = httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/widgets", strings.NewReader(`{"name":"b"}`)))
+	assert.Equal(t, http.StatusCreated, rw.Code)
+}
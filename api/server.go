@@ -1,11 +1,14 @@
 package api
 
 import (
+	"net"
 	"net/http"
 	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/rancher/norman/api/access"
+	"github.com/rancher/norman/api/authn"
 	"github.com/rancher/norman/api/builtin"
 	"github.com/rancher/norman/api/handler"
 	"github.com/rancher/norman/api/writer"
@@ -13,8 +16,11 @@ import (
 	"github.com/rancher/norman/httperror"
 	ehandler "github.com/rancher/norman/httperror/handler"
 	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/pkg/subscribe"
+	"github.com/rancher/norman/store"
 	"github.com/rancher/norman/store/wrapper"
 	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/urlbuilder"
 	"github.com/sirupsen/logrus"
 )
 
@@ -29,12 +35,45 @@ type Server struct {
 	Resolver                    parse.ResolverFunc
 	SubContextAttributeProvider types.SubContextAttributeProvider
 	ResponseWriters             map[string]ResponseWriter
-	Schemas                     *types.Schemas
-	QueryFilter                 types.QueryFilter
-	StoreWrapper                StoreWrapper
-	URLParser                   parse.URLParser
-	Defaults                    Defaults
-	AccessControl               types.AccessControl
+	// Schemas are the served schemas. Once the server handles requests read them with CurrentSchemas, since
+	// ReloadSchemas replaces them.
+	Schemas              *types.Schemas
+	schemasLock          sync.RWMutex
+	schemaNotifier       *subscribe.SchemaNotifier
+	initNotifier         sync.Once
+	QueryFilter          types.QueryFilter
+	StoreWrapper         StoreWrapper
+	URLParser            parse.URLParser
+	Defaults             Defaults
+	AccessControl        types.AccessControl
+	CORS                 *CORSConfig
+	SlowRequestThreshold time.Duration
+	IdempotencyWindow    time.Duration
+	// ImpersonationAuthorizer validates requests carrying Impersonate-User or Impersonate-Group headers. Without
+	// one and without an Authenticator the headers are trusted, as set by an authenticating proxy in front of the
+	// server. With an Authenticator but without an ImpersonationAuthorizer they are rejected, and the headers
+	// seen by stores are always set from the authenticated identity, or the impersonated one if authorized.
+	ImpersonationAuthorizer ImpersonationAuthorizer
+	// Authenticator resolves the caller of every request to APIContext.Identity and rejects requests it can not
+	// authenticate. Without one authentication is left to a proxy in front of the server.
+	Authenticator authn.Authenticator
+	// TrustedProxies are the networks whose X-Forwarded-* and X-API-URL-Prefix headers are used to build links.
+	// If nil the headers of every request are used, see urlbuilder.ParseTrustedProxies.
+	TrustedProxies []*net.IPNet
+	// RelativeLinks makes responses link to path-only URLs instead of absolute ones.
+	RelativeLinks bool
+	// StoreMiddleware wraps the store of every schema added, the first one being the outermost.
+	StoreMiddleware []store.Middleware
+	// Middleware wraps the request handling, the first one being the outermost.
+	Middleware []func(http.Handler) http.Handler
+	// Debug enables the profiling and introspection endpoints if set.
+	Debug *DebugConfig
+
+	readOnly       readOnlyState
+	idempotency    idempotencyCache
+	initHandler    sync.Once
+	handler        http.Handler
+	pendingSchemas []*types.Schemas
 }
 
 type Defaults struct {
@@ -46,6 +85,9 @@ type Defaults struct {
 	UpdateHandler types.RequestHandler
 	Store         types.Store
 	ErrorHandler  types.ErrorHandler
+	// Limit and MaxLimit are the default and maximum page sizes for schemas that don't set their own
+	Limit    int64
+	MaxLimit int64
 }
 
 func NewAPIServer() *Server {
@@ -66,6 +108,24 @@ func NewAPIServer() *Server {
 				ContentType: "application/yaml",
 				Encoder:     types.YAMLEncoder,
 			},
+			"ndjson": &writer.NDJSONResponseWriter{
+				EncodingResponseWriter: writer.EncodingResponseWriter{
+					ContentType: "application/x-ndjson",
+					Encoder:     types.JSONEncoder,
+				},
+			},
+			"csv": &writer.CSVResponseWriter{
+				EncodingResponseWriter: writer.EncodingResponseWriter{
+					ContentType: "text/csv",
+					Encoder:     types.JSONEncoder,
+				},
+			},
+			"xlsx": &writer.XLSXResponseWriter{
+				EncodingResponseWriter: writer.EncodingResponseWriter{
+					ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+					Encoder:     types.JSONEncoder,
+				},
+			},
 		},
 		SubContextAttributeProvider: &parse.DefaultSubContextAttributeProvider{},
 		Resolver:                    parse.DefaultResolver,
@@ -90,8 +150,24 @@ func NewAPIServer() *Server {
 	return s
 }
 
+// CurrentSchemas returns the served schemas.
+func (s *Server) CurrentSchemas() *types.Schemas {
+	s.schemasLock.RLock()
+	defer s.schemasLock.RUnlock()
+	return s.Schemas
+}
+
 func (s *Server) parser(rw http.ResponseWriter, req *http.Request) (*types.APIContext, error) {
-	ctx, err := parse.Parse(rw, req, s.Schemas, s.URLParser, s.Resolver)
+	schemas := s.CurrentSchemas()
+
+	if s.TrustedProxies != nil {
+		req = urlbuilder.StripUntrustedForwarded(req, s.TrustedProxies)
+	}
+	if s.RelativeLinks {
+		req = req.WithContext(urlbuilder.WithRelativeLinks(req.Context()))
+	}
+
+	ctx, err := parse.Parse(rw, req, schemas, s.URLParser, s.Resolver)
 	ctx.ResponseWriter = s.ResponseWriters[ctx.ResponseFormat]
 	if ctx.ResponseWriter == nil {
 		ctx.ResponseWriter = s.ResponseWriters["json"]
@@ -110,25 +186,77 @@ func (s *Server) parser(rw http.ResponseWriter, req *http.Request) (*types.APICo
 	return ctx, err
 }
 
+// AddSchemas adds schemas to the served ones. The served schemas are copied, changed and swapped in, so requests
+// reading them are not affected.
 func (s *Server) AddSchemas(schemas *types.Schemas) error {
 	if schemas.Err() != nil {
 		return schemas.Err()
 	}
 
+	s.schemasLock.Lock()
+	defer s.schemasLock.Unlock()
+
+	newSchemas := types.NewSchemas()
+	if s.Schemas != nil {
+		newSchemas = s.Schemas.Copy()
+	}
+
 	s.initBuiltin.Do(func() {
 		if s.IgnoreBuiltin {
 			return
 		}
 		for _, schema := range builtin.Schemas.Schemas() {
-			s.Schemas.AddSchema(*schema)
+			newSchemas.AddSchema(*schema)
 		}
 	})
 
 	for _, schema := range schemas.Schemas() {
-		s.Schemas.AddSchema(*schema)
+		newSchemas.AddSchema(*schema)
+	}
+
+	if err := newSchemas.Err(); err != nil {
+		return err
+	}
+	s.Schemas = newSchemas
+	return nil
+}
+
+// ReloadSchemas atomically replaces the served schemas. Stores and handlers of the new schemas are set up the
+// same way as AddSchemas and open subscriptions are notified of the change.
+func (s *Server) ReloadSchemas(schemas *types.Schemas) error {
+	if schemas.Err() != nil {
+		return schemas.Err()
+	}
+
+	newSchemas := types.NewSchemas()
+	newSchemas.AddHook = s.setupDefaults
+	if !s.IgnoreBuiltin {
+		for _, schema := range builtin.Schemas.Schemas() {
+			newSchemas.AddSchema(*schema)
+		}
+	}
+	for _, schema := range schemas.Schemas() {
+		newSchemas.AddSchema(*schema)
+	}
+	if err := newSchemas.Err(); err != nil {
+		return err
 	}
 
-	return s.Schemas.Err()
+	s.schemasLock.Lock()
+	s.Schemas = newSchemas
+	s.schemasLock.Unlock()
+
+	s.notifier().Notify()
+	return nil
+}
+
+// notifier returns the notifier of the subscriptions to the server, creating it for servers not built with
+// NewAPIServer.
+func (s *Server) notifier() *subscribe.SchemaNotifier {
+	s.initNotifier.Do(func() {
+		s.schemaNotifier = subscribe.NewSchemaNotifier()
+	})
+	return s.schemaNotifier
 }
 
 func (s *Server) setupDefaults(schema *types.Schema) {
@@ -164,9 +292,22 @@ func (s *Server) setupDefaults(schema *types.Schema) {
 		schema.ErrorHandler = s.Defaults.ErrorHandler
 	}
 
+	if schema.DefaultLimit == 0 {
+		schema.DefaultLimit = s.Defaults.Limit
+	}
+
+	if schema.MaxLimit == 0 {
+		schema.MaxLimit = s.Defaults.MaxLimit
+	}
+
 	if schema.Store != nil && s.StoreWrapper != nil {
 		schema.Store = s.StoreWrapper(schema.Store)
 	}
+
+	if schema.Store != nil {
+		middlewares := append([]store.Middleware{}, s.StoreMiddleware...)
+		schema.Store = store.Wrap(schema.Store, append(middlewares, storeTimingMiddleware)...)
+	}
 }
 
 func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
@@ -177,21 +318,74 @@ func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		}
 	}()
 
-	if apiResponse, err := s.handle(rw, req); err != nil {
+	s.initHandler.Do(func() {
+		var handler http.Handler = http.HandlerFunc(s.serveHTTP)
+		for i := len(s.Middleware) - 1; i >= 0; i-- {
+			handler = s.Middleware[i](handler)
+		}
+		s.handler = handler
+	})
+	s.handler.ServeHTTP(rw, req)
+}
+
+func (s *Server) serveHTTP(rw http.ResponseWriter, req *http.Request) {
+	if s.CORS != nil && s.CORS.handle(rw, req) {
+		return
+	}
+
+	if s.Debug != nil && s.handleDebug(rw, req) {
+		return
+	}
+
+	if s.IdempotencyWindow > 0 && req.Method == http.MethodPost {
+		if key := req.Header.Get(idempotencyKeyHeader); key != "" {
+			s.serveIdempotent(rw, req, key)
+			return
+		}
+	}
+
+	s.serveRequest(rw, req)
+}
+
+func (s *Server) serveRequest(rw http.ResponseWriter, req *http.Request) {
+	if s.SlowRequestThreshold > 0 {
+		s.serveTimed(rw, req)
+	} else {
+		s.serve(rw, req)
+	}
+}
+
+func (s *Server) serve(rw http.ResponseWriter, req *http.Request) *types.APIContext {
+	apiResponse, err := s.handle(rw, req)
+	if err != nil {
 		s.handleError(apiResponse, err)
 	}
+	return apiResponse
 }
 
 func (s *Server) handle(rw http.ResponseWriter, req *http.Request) (*types.APIContext, error) {
+	req = req.WithContext(subscribe.WithSchemaNotifier(req.Context(), s.notifier()))
 	apiRequest, err := s.Parser(rw, req)
 	if err != nil {
 		return apiRequest, err
 	}
 
+	if err := s.authenticate(apiRequest); err != nil {
+		return apiRequest, err
+	}
+
 	if err := CheckCSRF(apiRequest); err != nil {
 		return apiRequest, err
 	}
 
+	if err := s.checkImpersonation(apiRequest); err != nil {
+		return apiRequest, err
+	}
+
+	if err := s.checkReadOnly(apiRequest); err != nil {
+		return apiRequest, err
+	}
+
 	action, err := ValidateAction(apiRequest)
 	if err != nil {
 		return apiRequest, err
@@ -204,6 +398,7 @@ func (s *Server) handle(rw http.ResponseWriter, req *http.Request) (*types.APICo
 	if action == nil && apiRequest.Type != "" {
 		var handler types.RequestHandler
 		var nextHandler types.RequestHandler
+		var verb string
 		if apiRequest.Link == "" {
 			switch apiRequest.Method {
 			case http.MethodGet:
@@ -216,24 +411,31 @@ func (s *Server) handle(rw http.ResponseWriter, req *http.Request) (*types.APICo
 						return apiRequest, err
 					}
 				}
+				verb = types.VerbList
+				if apiRequest.ID != "" {
+					verb = types.VerbGet
+				}
 				handler = apiRequest.Schema.ListHandler
 				nextHandler = s.Defaults.ListHandler
 			case http.MethodPost:
 				if err := apiRequest.AccessControl.CanCreate(apiRequest, apiRequest.Schema); err != nil {
 					return apiRequest, err
 				}
+				verb = types.VerbCreate
 				handler = apiRequest.Schema.CreateHandler
 				nextHandler = s.Defaults.CreateHandler
 			case http.MethodPut:
 				if err := apiRequest.AccessControl.CanUpdate(apiRequest, nil, apiRequest.Schema); err != nil {
 					return apiRequest, err
 				}
+				verb = types.VerbUpdate
 				handler = apiRequest.Schema.UpdateHandler
 				nextHandler = s.Defaults.UpdateHandler
 			case http.MethodDelete:
 				if err := apiRequest.AccessControl.CanDelete(apiRequest, nil, apiRequest.Schema); err != nil {
 					return apiRequest, err
 				}
+				verb = types.VerbDelete
 				handler = apiRequest.Schema.DeleteHandler
 				nextHandler = s.Defaults.DeleteHandler
 			}
@@ -245,6 +447,9 @@ func (s *Server) handle(rw http.ResponseWriter, req *http.Request) (*types.APICo
 		if handler == nil {
 			return apiRequest, httperror.NewAPIError(httperror.NotFound, "")
 		}
+		if verb != "" {
+			handler = apiRequest.Schema.WithVerbHandlers(verb, handler)
+		}
 
 		return apiRequest, handler(apiRequest, nextHandler)
 	} else if action != nil {
@@ -255,6 +460,11 @@ func (s *Server) handle(rw http.ResponseWriter, req *http.Request) (*types.APICo
 }
 
 func handleAction(action *types.Action, context *types.APIContext) error {
+	if ac, ok := context.AccessControl.(types.ActionAccessControl); ok {
+		if err := ac.CanAction(context, context.Schema, context.Action); err != nil {
+			return err
+		}
+	}
 	if context.ID != "" {
 		if err := access.ByID(context, context.Version, context.Type, context.ID, nil); err != nil {
 			return err
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerbHandlers(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().MustImportAndCustomize(&version, widget{}, func(schema *types.Schema) {
+		schema.AddVerbHandler(types.VerbList, func(apiContext *types.APIContext, next types.RequestHandler) error {
+			if apiContext.Query.Get("cached") == "true" {
+				apiContext.WriteResponse(http.StatusAccepted, []map[string]interface{}{})
+				return nil
+			}
+			return next(apiContext, nil)
+		})
+	})
+
+	server, err := NewServer(WithSchemas(schemas), WithDefaultStore(memory.NewStore()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/widgets?cached=true", nil))
+	assert.Equal(t, http.StatusAccepted, rw.Code)
+
+	rw = httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/widgets", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
+
+func TestDebugEndpoints(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	server, err := NewServer(
+		WithSchemas(types.NewSchemas().MustImport(&version, widget{})),
+		WithDebug(DebugConfig{
+			Authorize: func(identity *types.Identity, req *http.Request) bool {
+				return req.Header.Get("X-Admin") == "true"
+			},
+		}),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/debug/goroutines", nil))
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/schemas", nil)
+	req.Header.Set("X-Admin", "true")
+	rw = httptest.NewRecorder()
+	server.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `"id":"widget"`)
+}
+
+func TestMirror(t *testing.T) {
+	mirrored := make(chan string, 1)
+	var authorization string
+	middleware := Mirror(MirrorConfig{
+		Percentage: 100,
+		Target: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			authorization = req.Header.Get("Authorization")
+			rw.Write([]byte(`{"name": "b"}`))
+		}),
+		Compare: func(served, response *MirroredResponse) string {
+			diff := compareMirrored(served, response)
+			mirrored <- diff
+			return diff
+		},
+	})
+
+	handler := middleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(`{"name":"a"}`))
+	}))
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/widgets", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(rw, req)
+	assert.Equal(t, `{"name":"a"}`, rw.Body.String())
+	assert.Equal(t, "JSON bodies differ", <-mirrored)
+	assert.Equal(t, "", authorization)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/v1/widgets", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/widgets?watch=true", nil),
+	} {
+		rw = httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		select {
+		case <-mirrored:
+			t.Fatalf("%s %s was mirrored", req.Method, req.URL)
+		default:
+		}
+	}
+}
+
+func TestAddSchemasSwaps(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	server := NewAPIServer()
+	server.IgnoreBuiltin = true
+
+	before := server.CurrentSchemas()
+	if !assert.NoError(t, server.AddSchemas(types.NewSchemas().MustImport(&version, widget{}))) {
+		return
+	}
+
+	assert.Nil(t, before.Schema(&version, "widget"))
+	assert.NotNil(t, server.CurrentSchemas().Schema(&version, "widget"))
+}
+
+func TestReloadSchemasWithoutNewAPIServer(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	server := &Server{IgnoreBuiltin: true}
+
+	assert.NoError(t, server.ReloadSchemas(types.NewSchemas().MustImport(&version, widget{})))
+	assert.NotNil(t, server.CurrentSchemas().Schema(&version, "widget"))
+}
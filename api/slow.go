@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/types"
+	"github.com/sirupsen/logrus"
+)
+
+type storeTimingKey struct{}
+
+type storeTiming struct {
+	sync.Mutex
+	ops map[string]time.Duration
+}
+
+func (s *storeTiming) add(op string, d time.Duration) {
+	s.Lock()
+	s.ops[op] += d
+	s.Unlock()
+}
+
+func (s *storeTiming) String() string {
+	s.Lock()
+	defer s.Unlock()
+
+	var ops []string
+	for op, d := range s.ops {
+		ops = append(ops, fmt.Sprintf("%s=%v", op, d))
+	}
+	sort.Strings(ops)
+	return strings.Join(ops, ",")
+}
+
+func recordStoreTime(apiContext *types.APIContext, op string, start time.Time) {
+	if apiContext == nil || apiContext.Request == nil {
+		return
+	}
+	if timing, ok := apiContext.Request.Context().Value(storeTimingKey{}).(*storeTiming); ok {
+		timing.add(op, time.Since(start))
+	}
+}
+
+// storeTimingMiddleware records how long each store operation takes for the slow request log.
+var storeTimingMiddleware = store.Middleware{
+	ByID: func(next store.ByIDFunc) store.ByIDFunc {
+		return func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+			defer recordStoreTime(apiContext, "byID", time.Now())
+			return next(apiContext, schema, id)
+		}
+	},
+	List: func(next store.ListFunc) store.ListFunc {
+		return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+			defer recordStoreTime(apiContext, "list", time.Now())
+			return next(apiContext, schema, opt)
+		}
+	},
+	Create: func(next store.CreateFunc) store.CreateFunc {
+		return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+			defer recordStoreTime(apiContext, "create", time.Now())
+			return next(apiContext, schema, data)
+		}
+	},
+	Update: func(next store.UpdateFunc) store.UpdateFunc {
+		return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+			defer recordStoreTime(apiContext, "update", time.Now())
+			return next(apiContext, schema, data, id)
+		}
+	},
+	Delete: func(next store.DeleteFunc) store.DeleteFunc {
+		return func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+			defer recordStoreTime(apiContext, "delete", time.Now())
+			return next(apiContext, schema, id)
+		}
+	},
+}
+
+type sizeWriter struct {
+	http.ResponseWriter
+	size int
+}
+
+func (s *sizeWriter) Write(b []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(b)
+	s.size += n
+	return n, err
+}
+
+func (s *sizeWriter) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (s *sizeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := s.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("response writer does not support hijacking")
+}
+
+// serveTimed serves the request and logs it if it took longer than SlowRequestThreshold.
+func (s *Server) serveTimed(rw http.ResponseWriter, req *http.Request) {
+	timing := &storeTiming{
+		ops: map[string]time.Duration{},
+	}
+	sw := &sizeWriter{ResponseWriter: rw}
+	req = req.WithContext(context.WithValue(req.Context(), storeTimingKey{}, timing))
+
+	start := time.Now()
+	apiContext := s.serve(sw, req)
+	elapsed := time.Since(start)
+	if elapsed < s.SlowRequestThreshold {
+		return
+	}
+
+	schemaID := ""
+	if apiContext != nil && apiContext.Schema != nil {
+		schemaID = apiContext.Schema.ID
+	}
+	logrus.Warnf("Slow API request %s %s took %v: schema=%s user=%s store=[%s] size=%d",
+		req.Method, req.URL.Path, elapsed, schemaID, req.Header.Get("Impersonate-User"), timing, sw.size)
+}
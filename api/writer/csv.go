@@ -0,0 +1,221 @@
+package writer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/rancher/norman/parse"
+	"github.com/rancher/norman/parse/builder"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/values"
+	"github.com/sirupsen/logrus"
+)
+
+// CSVResponseWriter exports collections as CSV, one resource per row. The columns can be selected with
+// ?_fields=name,spec.foo where nested fields are addressed with dots. The remaining pages of the collection are
+// fetched from the store, so the export holds the whole collection. Anything that is not a collection, such as
+// errors, is written as JSON with the embedded encoder instead.
+type CSVResponseWriter struct {
+	EncodingResponseWriter
+}
+
+func (c *CSVResponseWriter) Write(apiContext *types.APIContext, code int, obj interface{}) {
+	items, ok := exportItems(obj)
+	if !ok {
+		writeJSON(&c.EncodingResponseWriter, apiContext, code, obj)
+		return
+	}
+
+	AddCommonResponseHeader(apiContext)
+	apiContext.Response.Header().Set("content-type", c.ContentType)
+	if apiContext.Schema != nil {
+		apiContext.Response.Header().Set("Content-Disposition", `attachment; filename="`+apiContext.Schema.PluralName+`.csv"`)
+	}
+	apiContext.Response.WriteHeader(code)
+
+	flusher, _ := apiContext.Response.(http.Flusher)
+	w := csv.NewWriter(apiContext.Response)
+	c.export(apiContext, items, func(cells []exportCell) error {
+		row := make([]string, len(cells))
+		for i, cell := range cells {
+			row[i] = csvCell(cell)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+		w.Flush()
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return w.Error()
+	})
+	w.Flush()
+}
+
+// exportCell is a cell of an exported table. Numbers are kept apart so they are never escaped as text.
+type exportCell struct {
+	value  string
+	number bool
+}
+
+// exportItems returns the items of a collection, false if obj is not a collection.
+func exportItems(obj interface{}) ([]interface{}, bool) {
+	switch v := obj.(type) {
+	case []map[string]interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return items, true
+	case []interface{}:
+		return v, true
+	}
+	return nil, false
+}
+
+// writeJSON writes obj with the encoder of j labelled as JSON, for responses of the export writers that are not
+// collections.
+func writeJSON(j *EncodingResponseWriter, apiContext *types.APIContext, code int, obj interface{}) {
+	jsonWriter := &EncodingResponseWriter{
+		ContentType: "application/json",
+		Encoder:     j.Encoder,
+	}
+	jsonWriter.Write(apiContext, code, obj)
+}
+
+// export calls row with the header and then every resource of the collection, listing the pages after input
+// from the store until the collection is complete or row fails.
+func (j *EncodingResponseWriter) export(apiContext *types.APIContext, input []interface{}, row func([]exportCell) error) {
+	b := builder.NewBuilder(apiContext)
+	b.Version = apiContext.Version
+
+	columns := csvColumns(apiContext)
+	cells := make([]exportCell, len(columns))
+	for i, column := range columns {
+		cells[i] = exportCell{value: column}
+	}
+	if err := row(cells); err != nil {
+		return
+	}
+
+	included := includeCache{}
+	for {
+		for _, value := range input {
+			data, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			converted := j.convert(b, apiContext, data, included)
+			if converted == nil {
+				continue
+			}
+
+			for i, column := range columns {
+				cells[i] = csvValue(converted, column)
+			}
+			if err := row(cells); err != nil {
+				return
+			}
+		}
+
+		var ok bool
+		if input, ok = nextPage(apiContext); !ok {
+			return
+		}
+	}
+}
+
+// nextPage lists the page after the current one from the store, false once the last page was listed.
+func nextPage(apiContext *types.APIContext) ([]interface{}, bool) {
+	pagination := apiContext.Pagination
+	if pagination == nil || !pagination.Partial || pagination.Next == "" || pagination.Next == pagination.Marker ||
+		apiContext.Schema == nil || apiContext.Schema.Store == nil {
+		return nil, false
+	}
+
+	opts := parse.QueryOptions(apiContext, apiContext.Schema)
+	opts.Pagination = &types.Pagination{
+		Limit:  pagination.Limit,
+		Marker: pagination.Next,
+	}
+	data, err := apiContext.Schema.Store.List(apiContext, apiContext.Schema, &opts)
+	if err != nil {
+		logrus.Errorf("Failed to list the next page of %s for export: %v", apiContext.Schema.ID, err)
+		return nil, false
+	}
+	apiContext.Pagination = opts.Pagination
+
+	items, _ := exportItems(data)
+	return items, len(items) > 0
+}
+
+func csvColumns(apiContext *types.APIContext) []string {
+	var columns []string
+	for _, field := range strings.Split(apiContext.Option("fields"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			columns = append(columns, field)
+		}
+	}
+	if len(columns) > 0 || apiContext.Schema == nil {
+		return columns
+	}
+
+	columns = append(columns, "id")
+	var fields []string
+	for name := range apiContext.Schema.ResourceFields {
+		if name != "id" {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return append(columns, fields...)
+}
+
+func csvValue(resource *types.RawResource, column string) exportCell {
+	if column == "id" {
+		return exportCell{value: resource.ID}
+	}
+
+	val, ok := values.GetValue(resource.Values, strings.Split(column, ".")...)
+	if !ok || val == nil {
+		return exportCell{}
+	}
+
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return exportCell{value: csvJSON(v)}
+	case []interface{}:
+		for _, item := range v {
+			if _, ok := item.(map[string]interface{}); ok {
+				return exportCell{value: csvJSON(v)}
+			}
+		}
+		return exportCell{value: strings.Join(convert.ToStringSlice(v), ",")}
+	case []string:
+		return exportCell{value: strings.Join(v, ",")}
+	case int, int32, int64, float32, float64, json.Number:
+		return exportCell{value: convert.ToString(v), number: true}
+	}
+	return exportCell{value: convert.ToString(val)}
+}
+
+// csvCell prefixes text spreadsheets would evaluate as formulas with a quote, so exported data can't run formulas.
+// Numbers are left alone, so negative numbers stay numbers.
+func csvCell(cell exportCell) string {
+	if !cell.number && cell.value != "" && strings.ContainsAny(cell.value[:1], "=+-@\t\r") {
+		return "'" + cell.value
+	}
+	return cell.value
+}
+
+func csvJSON(val interface{}) string {
+	content, err := json.Marshal(val)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
@@ -0,0 +1,124 @@
+package writer_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rancher/norman/api"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	types.Resource
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+func newExportServer(t *testing.T) *api.Server {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	server, err := api.NewServer(
+		api.WithSchemas(types.NewSchemas().MustImport(&version, item{})),
+		api.WithDefaultStore(memory.NewStore()),
+	)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	for _, body := range []string{
+		`{"name":"a","count":-1}`,
+		`{"name":"=cmd","count":2}`,
+		`{"name":"-b","count":3}`,
+	} {
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/items", strings.NewReader(body)))
+		assert.Equal(t, http.StatusCreated, rw.Code)
+	}
+	return server
+}
+
+func TestCSVExport(t *testing.T) {
+	server := newExportServer(t)
+	if server == nil {
+		return
+	}
+
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/items?_format=csv&_fields=name,count&limit=2", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "text/csv", rw.Header().Get("Content-Type"))
+	assert.Contains(t, rw.Header().Get("Content-Disposition"), "items.csv")
+
+	rows, err := csv.NewReader(rw.Body).ReadAll()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.NotEmpty(t, rows) {
+		assert.Equal(t, []string{"name", "count"}, rows[0])
+		// Every page is exported, text that looks like a formula is escaped but negative numbers are not
+		assert.ElementsMatch(t, [][]string{
+			{"a", "-1"},
+			{"'=cmd", "2"},
+			{"'-b", "3"},
+		}, rows[1:])
+	}
+}
+
+func TestExportError(t *testing.T) {
+	server := newExportServer(t)
+	if server == nil {
+		return
+	}
+
+	for _, format := range []string{"csv", "xlsx"} {
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/items/missing?_format="+format, nil))
+		assert.Equal(t, http.StatusNotFound, rw.Code, format)
+		assert.Equal(t, "application/json", rw.Header().Get("Content-Type"), format)
+	}
+}
+
+func TestXLSXExport(t *testing.T) {
+	server := newExportServer(t)
+	if server == nil {
+		return
+	}
+
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/items?_format=xlsx&_fields=name,count&limit=2", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", rw.Header().Get("Content-Type"))
+
+	body := rw.Body.Bytes()
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	files := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if !assert.NoError(t, err) {
+			return
+		}
+		content, err := ioutil.ReadAll(r)
+		r.Close()
+		assert.NoError(t, err)
+		files[f.Name] = string(content)
+	}
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files, "xl/workbook.xml")
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Equal(t, 4, strings.Count(sheet, "<row>"))
+	assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+	assert.Contains(t, sheet, `<t xml:space="preserve">=cmd</t>`)
+	assert.Contains(t, sheet, "<c><v>-1</v></c>")
+}
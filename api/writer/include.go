@@ -0,0 +1,131 @@
+package writer
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rancher/norman/name"
+	"github.com/rancher/norman/parse/builder"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/definition"
+	"github.com/sirupsen/logrus"
+)
+
+const includeParam = "include"
+
+func includes(apiContext *types.APIContext) []string {
+	if apiContext.Method != http.MethodGet {
+		return nil
+	}
+
+	var result []string
+	for _, value := range apiContext.Query[includeParam] {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field != "" {
+				result = append(result, field)
+			}
+		}
+	}
+	return result
+}
+
+// includeKey is an object referenced by an included field.
+type includeKey struct {
+	schema, id string
+}
+
+// includeCache holds the objects included in a response, so objects referenced by many resources are read once.
+// Objects that could not be read are cached as nil.
+type includeCache map[includeKey]*types.RawResource
+
+// addIncludes expands the reference fields listed in ?include= inline. Referenced objects are read through the
+// store of the referenced schema so the normal access control of the caller applies.
+func (j *EncodingResponseWriter) addIncludes(b *builder.Builder, schema *types.Schema, context *types.APIContext, rawResource *types.RawResource, included includeCache) {
+	for _, fieldName := range includes(context) {
+		field, ok := schema.ResourceFields[fieldName]
+		if !ok {
+			continue
+		}
+
+		key := includedName(fieldName)
+		if _, ok := schema.ResourceFields[key]; ok {
+			continue
+		}
+
+		switch {
+		case definition.IsReferenceType(field.Type):
+			refSchema := referencedSchema(context, schema, field.Type)
+			if refSchema == nil {
+				continue
+			}
+			id := convert.ToString(rawResource.Values[fieldName])
+			if obj := j.lookupInclude(b, context, refSchema, id, included); obj != nil {
+				rawResource.Values[key] = obj
+			}
+		case definition.IsArrayType(field.Type) && definition.IsReferenceType(definition.SubType(field.Type)):
+			refSchema := referencedSchema(context, schema, definition.SubType(field.Type))
+			if refSchema == nil {
+				continue
+			}
+			var objs []interface{}
+			for _, id := range convert.ToStringSlice(rawResource.Values[fieldName]) {
+				if obj := j.lookupInclude(b, context, refSchema, id, included); obj != nil {
+					objs = append(objs, obj)
+				}
+			}
+			if len(objs) > 0 {
+				rawResource.Values[key] = objs
+			}
+		}
+	}
+}
+
+func (j *EncodingResponseWriter) lookupInclude(b *builder.Builder, context *types.APIContext, schema *types.Schema, id string, included includeCache) *types.RawResource {
+	if id == "" {
+		return nil
+	}
+	key := includeKey{schema: schema.ID, id: id}
+	if obj, ok := included[key]; ok {
+		return obj
+	}
+	obj := j.readInclude(b, context, schema, id)
+	included[key] = obj
+	return obj
+}
+
+func (j *EncodingResponseWriter) readInclude(b *builder.Builder, context *types.APIContext, schema *types.Schema, id string) *types.RawResource {
+	if schema.Store == nil || schema.CanGet(context) != nil {
+		return nil
+	}
+
+	data, err := schema.Store.ByID(context, schema, id)
+	if err != nil {
+		logrus.Debugf("failed to include %s %s: %v", schema.ID, id, err)
+		return nil
+	}
+	if data == nil {
+		return nil
+	}
+
+	if _, ok := data["type"]; !ok {
+		data["type"] = schema.ID
+	}
+
+	return j.convertWithIncludes(b, context, data, nil)
+}
+
+func referencedSchema(context *types.APIContext, schema *types.Schema, fieldType string) *types.Schema {
+	return context.Schemas.Schema(&schema.Version, definition.SubType(fieldType))
+}
+
+func includedName(fieldName string) string {
+	switch {
+	case strings.HasSuffix(fieldName, "Ids"):
+		return name.GuessPluralName(strings.TrimSuffix(fieldName, "Ids"))
+	case strings.HasSuffix(fieldName, "Id"):
+		return strings.TrimSuffix(fieldName, "Id")
+	}
+	return fieldName + "Object"
+}
@@ -0,0 +1,89 @@
+package writer_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rancher/norman/api"
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type owner struct {
+	types.Resource
+	Name string `json:"name"`
+}
+
+type pet struct {
+	types.Resource
+	Name    string `json:"name"`
+	OwnerID string `json:"ownerId" norman:"type=reference[owner]"`
+}
+
+func TestIncludeReadsEachObjectOnce(t *testing.T) {
+	reads := map[string]int{}
+	counting := store.Wrap(memory.NewStore(), store.Middleware{
+		ByID: func(next store.ByIDFunc) store.ByIDFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+				reads[schema.ID+" "+id]++
+				return next(apiContext, schema, id)
+			}
+		},
+	})
+
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	server, err := api.NewServer(
+		api.WithSchemas(types.NewSchemas().MustImport(&version, owner{}).MustImport(&version, pet{})),
+		api.WithDefaultStore(counting),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, req := range []struct {
+		path, body string
+	}{
+		{"/v1/owners", `{"name":"alice"}`},
+		{"/v1/owners", `{"name":"bob"}`},
+		{"/v1/pets", `{"name":"a","ownerId":"alice"}`},
+		{"/v1/pets", `{"name":"b","ownerId":"alice"}`},
+		{"/v1/pets", `{"name":"c","ownerId":"bob"}`},
+		{"/v1/pets", `{"name":"d","ownerId":"missing"}`},
+		{"/v1/pets", `{"name":"e","ownerId":"missing"}`},
+	} {
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, req.path, strings.NewReader(req.body)))
+		assert.Equal(t, http.StatusCreated, rw.Code, req.body)
+	}
+
+	for k := range reads {
+		delete(reads, k)
+	}
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/pets?include=ownerId", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	var collection struct {
+		Data []struct {
+			Name  string `json:"name"`
+			Owner *owner `json:"owner"`
+		} `json:"data"`
+	}
+	if !assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &collection)) {
+		return
+	}
+
+	owners := map[string]string{}
+	for _, item := range collection.Data {
+		if item.Owner != nil {
+			owners[item.Name] = item.Owner.Name
+		}
+	}
+	assert.Equal(t, map[string]string{"a": "alice", "b": "alice", "c": "bob"}, owners)
+	assert.Equal(t, map[string]int{"owner alice": 1, "owner bob": 1, "owner missing": 1}, reads)
+}
@@ -45,7 +45,7 @@ func (j *EncodingResponseWriter) VersionBody(apiContext *types.APIContext, versi
 	case []map[string]interface{}:
 		output = j.writeMapSlice(builder, apiContext, v)
 	case map[string]interface{}:
-		output = j.convert(builder, apiContext, v)
+		output = j.convert(builder, apiContext, v, includeCache{})
 	case types.RawResource:
 		output = v
 	}
@@ -58,8 +58,9 @@ func (j *EncodingResponseWriter) VersionBody(apiContext *types.APIContext, versi
 }
 func (j *EncodingResponseWriter) writeMapSlice(builder *builder.Builder, apiContext *types.APIContext, input []map[string]interface{}) *types.GenericCollection {
 	collection := newCollection(apiContext)
+	included := includeCache{}
 	for _, value := range input {
-		converted := j.convert(builder, apiContext, value)
+		converted := j.convert(builder, apiContext, value, included)
 		if converted != nil {
 			collection.Data = append(collection.Data, converted)
 		}
@@ -74,10 +75,11 @@ func (j *EncodingResponseWriter) writeMapSlice(builder *builder.Builder, apiCont
 
 func (j *EncodingResponseWriter) writeInterfaceSlice(builder *builder.Builder, apiContext *types.APIContext, input []interface{}) *types.GenericCollection {
 	collection := newCollection(apiContext)
+	included := includeCache{}
 	for _, value := range input {
 		switch v := value.(type) {
 		case map[string]interface{}:
-			converted := j.convert(builder, apiContext, v)
+			converted := j.convert(builder, apiContext, v, included)
 			if converted != nil {
 				collection.Data = append(collection.Data, converted)
 			}
@@ -100,7 +102,14 @@ func toString(val interface{}) string {
 	return fmt.Sprint(val)
 }
 
-func (j *EncodingResponseWriter) convert(b *builder.Builder, context *types.APIContext, input map[string]interface{}) *types.RawResource {
+// convert builds the resource of input, expanding the included fields with the objects in included, which has to
+// be shared by all resources of a response.
+func (j *EncodingResponseWriter) convert(b *builder.Builder, context *types.APIContext, input map[string]interface{}, included includeCache) *types.RawResource {
+	return j.convertWithIncludes(b, context, input, included)
+}
+
+// convertWithIncludes builds the resource of input, without expanding included fields if included is nil.
+func (j *EncodingResponseWriter) convertWithIncludes(b *builder.Builder, context *types.APIContext, input map[string]interface{}, included includeCache) *types.RawResource {
 	schema := context.Schemas.Schema(context.Version, definition.GetFullType(input))
 	if schema == nil {
 		return nil
@@ -126,6 +135,9 @@ func (j *EncodingResponseWriter) convert(b *builder.Builder, context *types.APIC
 	}
 
 	j.addLinks(b, schema, context, input, rawResource)
+	if included != nil {
+		j.addIncludes(b, schema, context, rawResource, included)
+	}
 
 	if schema.Formatter != nil {
 		schema.Formatter(context, rawResource)
@@ -149,15 +161,28 @@ func (j *EncodingResponseWriter) addLinks(b *builder.Builder, schema *types.Sche
 	}
 
 	subContextVersion := context.Schemas.SubContextVersionForSchema(schema)
-	for _, backRef := range context.Schemas.References(schema) {
-		if backRef.Schema.CanList(context) != nil {
-			continue
-		}
+	if !schema.NoReferenceLinks {
+		for _, backRef := range context.Schemas.References(schema) {
+			if backRef.Schema.NoReferenceLinks || backRef.Schema.CanList(context) != nil {
+				continue
+			}
 
-		if subContextVersion == nil {
-			rawResource.Links[backRef.Schema.PluralName] = context.URLBuilder.FilterLink(backRef.Schema, backRef.FieldName, rawResource.ID)
-		} else {
-			rawResource.Links[backRef.Schema.PluralName] = context.URLBuilder.SubContextCollection(schema, rawResource.ID, backRef.Schema)
+			linkName := backRef.Schema.PluralName
+			if name, ok := schema.ReferenceLinkNames[backRef.Schema.ID+"."+backRef.FieldName]; ok {
+				linkName = name
+			}
+			if linkName == "" {
+				continue
+			}
+			if _, ok := rawResource.Links[linkName]; ok {
+				continue
+			}
+
+			if subContextVersion == nil {
+				rawResource.Links[linkName] = context.URLBuilder.FilterLink(backRef.Schema, backRef.FieldName, rawResource.ID)
+			} else {
+				rawResource.Links[linkName] = context.URLBuilder.SubContextCollection(schema, rawResource.ID, backRef.Schema)
+			}
 		}
 	}
 
@@ -198,8 +223,12 @@ func newCollection(apiContext *types.APIContext) *types.GenericCollection {
 	result.Filters = map[string][]types.Condition{}
 
 	for _, cond := range opts.Conditions {
-		filters := result.Filters[cond.Field]
-		result.Filters[cond.Field] = append(filters, cond.ToCondition())
+		// Grouped conditions of a filter expression have no field and are listed under the expression parameter
+		field := cond.Field
+		if field == "" {
+			field = parse.FilterParam
+		}
+		result.Filters[field] = append(result.Filters[field], cond.ToCondition())
 	}
 
 	for name := range apiContext.Schema.CollectionFilters {
@@ -0,0 +1,68 @@
+package writer
+
+import (
+	"net/http"
+
+	"github.com/rancher/norman/parse/builder"
+	"github.com/rancher/norman/types"
+)
+
+// NDJSONResponseWriter writes collections as newline delimited JSON, one resource per line, flushing after every
+// item so clients can process them as they arrive. The page is still listed in full from the store first. The
+// last line is the collection without its data, holding the links and the pagination with the marker of the next
+// page.
+type NDJSONResponseWriter struct {
+	EncodingResponseWriter
+}
+
+func (n *NDJSONResponseWriter) Write(apiContext *types.APIContext, code int, obj interface{}) {
+	n.start(apiContext, code, obj)
+
+	switch v := obj.(type) {
+	case []map[string]interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			items = append(items, item)
+		}
+		n.stream(apiContext, items)
+		n.trailer(apiContext)
+	case []interface{}:
+		n.stream(apiContext, v)
+		n.trailer(apiContext)
+	default:
+		n.Body(apiContext, apiContext.Response, obj)
+	}
+}
+
+func (n *NDJSONResponseWriter) stream(apiContext *types.APIContext, input []interface{}) {
+	b := builder.NewBuilder(apiContext)
+	b.Version = apiContext.Version
+
+	flusher, _ := apiContext.Response.(http.Flusher)
+	included := includeCache{}
+	for _, value := range input {
+		var output interface{} = value
+		if data, ok := value.(map[string]interface{}); ok {
+			converted := n.convert(b, apiContext, data, included)
+			if converted == nil {
+				continue
+			}
+			output = converted
+		}
+
+		if err := n.Encoder(apiContext.Response, output); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// trailer writes the metadata of the collection as the last line.
+func (n *NDJSONResponseWriter) trailer(apiContext *types.APIContext) {
+	if apiContext.Schema == nil {
+		return
+	}
+	n.Encoder(apiContext.Response, newCollection(apiContext).Collection)
+}
@@ -0,0 +1,69 @@
+package writer_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rancher/norman/api"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type widget struct {
+	types.Resource
+	Name string `json:"name"`
+}
+
+func TestNDJSON(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	server, err := api.NewServer(
+		api.WithSchemas(types.NewSchemas().MustImport(&version, widget{})),
+		api.WithDefaultStore(memory.NewStore()),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/widgets", strings.NewReader(`{"name":"`+name+`"}`)))
+		assert.Equal(t, http.StatusCreated, rw.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/widgets?limit=2", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, req)
+	assert.Equal(t, "application/x-ndjson", rw.Header().Get("Content-Type"))
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(rw.Body)
+	for scanner.Scan() {
+		line := map[string]interface{}{}
+		if !assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line)) {
+			return
+		}
+		lines = append(lines, line)
+	}
+	if !assert.Len(t, lines, 3) {
+		return
+	}
+
+	assert.Equal(t, "widget", lines[0]["type"])
+	assert.Equal(t, "a", lines[0]["name"])
+	assert.Equal(t, "b", lines[1]["name"])
+
+	trailer := lines[2]
+	assert.Equal(t, "collection", trailer["type"])
+	assert.Nil(t, trailer["data"])
+	pagination, _ := trailer["pagination"].(map[string]interface{})
+	if assert.NotNil(t, pagination) {
+		assert.Equal(t, true, pagination["partial"])
+		assert.Contains(t, pagination["next"], "marker=")
+	}
+}
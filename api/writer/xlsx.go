@@ -0,0 +1,121 @@
+package writer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/rancher/norman/types"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// XLSXResponseWriter exports collections as an Excel workbook with a single sheet, in the same layout as
+// CSVResponseWriter. Text is written as inline strings, which Excel never evaluates as formulas, so it is not
+// escaped like CSV. Anything that is not a collection is written as JSON with the embedded encoder instead.
+type XLSXResponseWriter struct {
+	EncodingResponseWriter
+}
+
+func (x *XLSXResponseWriter) Write(apiContext *types.APIContext, code int, obj interface{}) {
+	items, ok := exportItems(obj)
+	if !ok {
+		writeJSON(&x.EncodingResponseWriter, apiContext, code, obj)
+		return
+	}
+
+	AddCommonResponseHeader(apiContext)
+	apiContext.Response.Header().Set("content-type", x.ContentType)
+	if apiContext.Schema != nil {
+		apiContext.Response.Header().Set("Content-Disposition", `attachment; filename="`+apiContext.Schema.PluralName+`.xlsx"`)
+	}
+	apiContext.Response.WriteHeader(code)
+
+	w := zip.NewWriter(apiContext.Response)
+	defer w.Close()
+
+	for _, part := range []struct {
+		name, content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		f, err := w.Create(part.name)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return
+		}
+	}
+
+	sheet, err := w.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return
+	}
+	if _, err := io.WriteString(sheet, xlsxSheetStart); err != nil {
+		return
+	}
+	failed := false
+	x.export(apiContext, items, func(cells []exportCell) error {
+		err := xlsxRow(sheet, cells)
+		failed = err != nil
+		return err
+	})
+	if !failed {
+		io.WriteString(sheet, xlsxSheetEnd)
+	}
+}
+
+func xlsxRow(w io.Writer, cells []exportCell) error {
+	if _, err := io.WriteString(w, "<row>"); err != nil {
+		return err
+	}
+	for _, cell := range cells {
+		if cell.number {
+			if _, err := strconv.ParseFloat(cell.value, 64); err == nil {
+				if _, err := io.WriteString(w, "<c><v>"+cell.value+"</v></c>"); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if _, err := io.WriteString(w, `<c t="inlineStr"><is><t xml:space="preserve">`); err != nil {
+			return err
+		}
+		if err := xml.EscapeText(w, []byte(cell.value)); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "</t></is></c>"); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "</row>")
+	return err
+}
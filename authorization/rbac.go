@@ -0,0 +1,364 @@
+package authorization
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/sirupsen/logrus"
+	authzv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	rbacinformers "k8s.io/client-go/informers/rbac/v1"
+	authzclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1"
+	"k8s.io/kubernetes/pkg/registry/rbac/validation"
+	"k8s.io/kubernetes/plugin/pkg/auth/authorizer/rbac"
+)
+
+const (
+	rbacCacheSize = 1000
+	// DefaultRBACCacheTTL is how long decisions and rules are cached if NewRBACAccess is given no TTL.
+	DefaultRBACCacheTTL = 10 * time.Second
+)
+
+// RBACListers are the cached Roles, ClusterRoles and their bindings used to filter lists, see RBACListersFor.
+type RBACListers struct {
+	Roles               rbaclisters.RoleLister
+	RoleBindings        rbaclisters.RoleBindingLister
+	ClusterRoles        rbaclisters.ClusterRoleLister
+	ClusterRoleBindings rbaclisters.ClusterRoleBindingLister
+}
+
+// RBACListersFor returns the listers of the RBAC informers, which have to be started by the caller.
+func RBACListersFor(informers rbacinformers.Interface) *RBACListers {
+	return &RBACListers{
+		Roles:               informers.Roles().Lister(),
+		RoleBindings:        informers.RoleBindings().Lister(),
+		ClusterRoles:        informers.ClusterRoles().Lister(),
+		ClusterRoleBindings: informers.ClusterRoleBindings().Lister(),
+	}
+}
+
+// RBACAccess authorizes requests with SubjectAccessReviews, so the API honors the same RBAC rules as the
+// cluster. The user is APIContext.Identity or, if the server does not authenticate, the Impersonate-User and
+// Impersonate-Group headers. Actions are authorized with the action name as verb. It is also the store authorizer
+// returned by authz.NewSubjectAccessReview.
+type RBACAccess struct {
+	AllAccess
+
+	// Resource returns the API group and resource authorized for the schema. Defaults to the group of the schema
+	// version and the plural name of the schema.
+	Resource func(schema *types.Schema) (group, resource string)
+
+	client     authzclient.SubjectAccessReviewInterface
+	rules      validation.AuthorizationRuleResolver
+	cache      *cache.LRUExpireCache
+	rulesCache *cache.LRUExpireCache
+	ttl        time.Duration
+}
+
+// NewRBACAccess returns an access control checking every request with a SubjectAccessReview. Decisions, and the
+// rules of users evaluated by FilterList, are cached for ttl, DefaultRBACCacheTTL if zero. Without listers lists
+// are only filtered by namespace.
+func NewRBACAccess(client authzclient.SubjectAccessReviewsGetter, listers *RBACListers, ttl time.Duration) *RBACAccess {
+	if ttl <= 0 {
+		ttl = DefaultRBACCacheTTL
+	}
+	r := &RBACAccess{
+		client:     client.SubjectAccessReviews(),
+		cache:      cache.NewLRUExpireCache(rbacCacheSize),
+		rulesCache: cache.NewLRUExpireCache(rbacCacheSize),
+		ttl:        ttl,
+	}
+	if listers != nil {
+		r.rules = validation.NewDefaultRuleResolver(
+			&rbac.RoleGetter{Lister: listers.Roles},
+			&rbac.RoleBindingLister{Lister: listers.RoleBindings},
+			&rbac.ClusterRoleGetter{Lister: listers.ClusterRoles},
+			&rbac.ClusterRoleBindingLister{Lister: listers.ClusterRoleBindings},
+		)
+	}
+	return r
+}
+
+// CanCreate reviews the create in the namespace of the request, as the body is not parsed yet. Stores wrapped with
+// authz.Wrap review it again in the namespace of the object created.
+func (r *RBACAccess) CanCreate(apiContext *types.APIContext, schema *types.Schema) error {
+	if err := r.AllAccess.CanCreate(apiContext, schema); err != nil {
+		return err
+	}
+	return r.check(apiContext, schema, "create", nil)
+}
+
+func (r *RBACAccess) CanGet(apiContext *types.APIContext, schema *types.Schema) error {
+	if err := r.AllAccess.CanGet(apiContext, schema); err != nil {
+		return err
+	}
+	return r.check(apiContext, schema, "get", nil)
+}
+
+func (r *RBACAccess) CanList(apiContext *types.APIContext, schema *types.Schema) error {
+	if err := r.AllAccess.CanList(apiContext, schema); err != nil {
+		return err
+	}
+	return r.check(apiContext, schema, "list", nil)
+}
+
+func (r *RBACAccess) CanUpdate(apiContext *types.APIContext, obj map[string]interface{}, schema *types.Schema) error {
+	if err := r.AllAccess.CanUpdate(apiContext, obj, schema); err != nil {
+		return err
+	}
+	return r.check(apiContext, schema, "update", obj)
+}
+
+func (r *RBACAccess) CanDelete(apiContext *types.APIContext, obj map[string]interface{}, schema *types.Schema) error {
+	if err := r.AllAccess.CanDelete(apiContext, obj, schema); err != nil {
+		return err
+	}
+	return r.check(apiContext, schema, "delete", obj)
+}
+
+func (r *RBACAccess) CanDo(apiGroup, resource, verb string, apiContext *types.APIContext, obj map[string]interface{}, schema *types.Schema) error {
+	namespace, name := target(apiContext, schema, obj)
+	return r.review(apiContext, authzv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      strings.ToLower(verb),
+		Group:     apiGroup,
+		Resource:  resource,
+		Name:      name,
+	})
+}
+
+// Authorize reviews verb on the object with id, so RBACAccess can also authorize stores, see authz.Wrap.
+func (r *RBACAccess) Authorize(apiContext *types.APIContext, schema *types.Schema, verb, id string) error {
+	group, resource := r.resource(schema, nil)
+	namespace, name := splitID(apiContext, schema, id)
+	return r.review(apiContext, authzv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      verb,
+		Group:     group,
+		Resource:  resource,
+		Name:      name,
+	})
+}
+
+func (r *RBACAccess) CanAction(apiContext *types.APIContext, schema *types.Schema, action string) error {
+	return r.check(apiContext, schema, action, nil)
+}
+
+// Filter hides obj unless the caller may get it.
+func (r *RBACAccess) Filter(apiContext *types.APIContext, schema *types.Schema, obj map[string]interface{}, context map[string]string) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	if result := r.FilterList(apiContext, schema, []map[string]interface{}{obj}, context); len(result) == 0 {
+		return nil
+	}
+	return obj
+}
+
+// FilterList keeps the objects the caller may get. If the caller may not list all objects it keeps the objects of
+// the namespaces the caller may list, reviewing each namespace once, and the objects the cached RBAC rules of the
+// caller allow to get by name, so large lists never cause a review per object.
+func (r *RBACAccess) FilterList(apiContext *types.APIContext, schema *types.Schema, obj []map[string]interface{}, context map[string]string) []map[string]interface{} {
+	if len(obj) == 0 {
+		return obj
+	}
+
+	group, resource := r.resource(schema, context)
+	if r.review(apiContext, authzv1.ResourceAttributes{Verb: "list", Group: group, Resource: resource}) == nil {
+		return obj
+	}
+
+	namespaces := map[string]bool{}
+	rules := map[string][]rbacv1.PolicyRule{}
+	var result []map[string]interface{}
+	for _, item := range obj {
+		namespace := convert.ToString(item["namespaceId"])
+		if namespace != "" {
+			allowed, ok := namespaces[namespace]
+			if !ok {
+				allowed = r.review(apiContext, authzv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      "list",
+					Group:     group,
+					Resource:  resource,
+				}) == nil
+				namespaces[namespace] = allowed
+			}
+			if allowed {
+				result = append(result, item)
+				continue
+			}
+		}
+
+		if r.rules == nil {
+			continue
+		}
+		namespaceRules, ok := rules[namespace]
+		if !ok {
+			namespaceRules = r.rulesFor(apiContext, namespace)
+			rules[namespace] = namespaceRules
+		}
+		if rbac.RulesAllow(authorizer.AttributesRecord{
+			Verb:            "get",
+			Namespace:       namespace,
+			APIGroup:        group,
+			Resource:        resource,
+			Name:            convert.ToString(item["name"]),
+			ResourceRequest: true,
+		}, namespaceRules...) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// rulesFor returns the cached RBAC rules of the caller in namespace, only the cluster wide ones if empty.
+func (r *RBACAccess) rulesFor(apiContext *types.APIContext, namespace string) []rbacv1.PolicyRule {
+	spec := reviewSpec(apiContext)
+	if spec.User == "" && len(spec.Groups) == 0 {
+		return nil
+	}
+
+	key := cacheKey(spec) + "\x00" + namespace
+	if rules, ok := r.rulesCache.Get(key); ok {
+		return rules.([]rbacv1.PolicyRule)
+	}
+
+	rules, err := r.rules.RulesFor(&user.DefaultInfo{
+		Name:   spec.User,
+		UID:    spec.UID,
+		Groups: spec.Groups,
+		Extra:  extra(spec.Extra),
+	}, namespace)
+	if err != nil {
+		// The rules that could be resolved are still valid, rules only grant access
+		logrus.Debugf("Failed to resolve all RBAC rules of %s: %v", spec.User, err)
+	}
+	r.rulesCache.Add(key, rules, r.ttl)
+	return rules
+}
+
+func (r *RBACAccess) check(apiContext *types.APIContext, schema *types.Schema, verb string, obj map[string]interface{}) error {
+	group, resource := r.resource(schema, nil)
+	return r.CanDo(group, resource, verb, apiContext, obj, schema)
+}
+
+// resource returns the group and resource of the schema, preferring the ones of the store passed as context.
+func (r *RBACAccess) resource(schema *types.Schema, context map[string]string) (string, string) {
+	if context["resource"] != "" {
+		return context["apiGroup"], context["resource"]
+	}
+	if r.Resource != nil {
+		return r.Resource(schema)
+	}
+	return schema.Version.Group, strings.ToLower(schema.PluralName)
+}
+
+func (r *RBACAccess) review(apiContext *types.APIContext, attrs authzv1.ResourceAttributes) error {
+	spec := reviewSpec(apiContext)
+	spec.ResourceAttributes = &attrs
+
+	if spec.User == "" && len(spec.Groups) == 0 {
+		return httperror.NewAPIError(httperror.Unauthorized, "no user identity on request")
+	}
+	denied := httperror.NewAPIError(httperror.PermissionDenied,
+		fmt.Sprintf("can not %s %s", attrs.Verb, attrs.Resource))
+
+	key := cacheKey(spec)
+	if allowed, ok := r.cache.Get(key); ok {
+		if allowed.(bool) {
+			return nil
+		}
+		return denied
+	}
+
+	review, err := r.client.Create(&authzv1.SubjectAccessReview{
+		Spec: spec,
+	})
+	if err != nil {
+		return httperror.WrapAPIError(err, httperror.ServerError, "failed to review access")
+	}
+
+	r.cache.Add(key, review.Status.Allowed, r.ttl)
+	if !review.Status.Allowed {
+		return denied
+	}
+	return nil
+}
+
+// reviewSpec returns a review spec for the caller, without attributes.
+func reviewSpec(apiContext *types.APIContext) authzv1.SubjectAccessReviewSpec {
+	var spec authzv1.SubjectAccessReviewSpec
+	if identity := apiContext.Identity; identity != nil {
+		spec.User = identity.Name
+		spec.UID = identity.UID
+		spec.Groups = identity.Groups
+		for k, v := range identity.Extra {
+			if spec.Extra == nil {
+				spec.Extra = map[string]authzv1.ExtraValue{}
+			}
+			spec.Extra[k] = v
+		}
+	} else if apiContext.Request != nil {
+		spec.User = apiContext.Request.Header.Get("Impersonate-User")
+		spec.Groups = apiContext.Request.Header[http.CanonicalHeaderKey("Impersonate-Group")]
+	}
+	return spec
+}
+
+func extra(values map[string]authzv1.ExtraValue) map[string][]string {
+	if len(values) == 0 {
+		return nil
+	}
+	result := map[string][]string{}
+	for k, v := range values {
+		result[k] = v
+	}
+	return result
+}
+
+// target returns the namespace and name of obj, or of the ID of the request.
+func target(apiContext *types.APIContext, schema *types.Schema, obj map[string]interface{}) (string, string) {
+	if obj != nil {
+		return convert.ToString(obj["namespaceId"]), convert.ToString(obj["name"])
+	}
+	return splitID(apiContext, schema, apiContext.ID)
+}
+
+// splitID returns the namespace and name of an ID formatted as namespace:name, where "namespace:" names a
+// namespace and ":" all of them. Without a colon in the ID the namespace of the request is used, taken from the
+// subcontext before the namespaceId query parameter like the stores do.
+func splitID(apiContext *types.APIContext, schema *types.Schema, id string) (string, string) {
+	namespace, name := "", id
+	if schema.Scope == types.NamespaceScope {
+		if parts := strings.SplitN(id, ":", 2); len(parts) == 2 {
+			return parts[0], parts[1]
+		}
+		namespace = apiContext.SubContext["namespaces"]
+		if namespace == "" && apiContext.Query != nil {
+			namespace = apiContext.Query.Get("namespaceId")
+		}
+	}
+	return namespace, name
+}
+
+func cacheKey(spec authzv1.SubjectAccessReviewSpec) string {
+	key := []string{
+		spec.User,
+		strings.Join(spec.Groups, ","),
+		fmt.Sprint(spec.Extra),
+	}
+	if attrs := spec.ResourceAttributes; attrs != nil {
+		key = append(key, attrs.Verb, attrs.Group, attrs.Resource, attrs.Namespace, attrs.Name)
+	}
+	return strings.Join(key, "\x00")
+}
@@ -0,0 +1,178 @@
+package authorization
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	authzv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	authzclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+type fakeReviews struct {
+	reviews []authzv1.SubjectAccessReviewSpec
+}
+
+func (f *fakeReviews) SubjectAccessReviews() authzclient.SubjectAccessReviewInterface {
+	return f
+}
+
+func (f *fakeReviews) Create(sar *authzv1.SubjectAccessReview) (*authzv1.SubjectAccessReview, error) {
+	f.reviews = append(f.reviews, sar.Spec)
+	sar.Status.Allowed = sar.Spec.ResourceAttributes.Verb == "get"
+	return sar, nil
+}
+
+func TestRBACAccess(t *testing.T) {
+	reviews := &fakeReviews{}
+	access := NewRBACAccess(reviews, nil, time.Minute)
+
+	schema := &types.Schema{
+		ID:                "pod",
+		PluralName:        "pods",
+		Scope:             types.NamespaceScope,
+		Version:           types.APIVersion{Group: "core"},
+		ResourceMethods:   []string{http.MethodGet, http.MethodDelete},
+		CollectionMethods: []string{http.MethodGet},
+	}
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	apiContext := &types.APIContext{
+		ID:       "default:nginx",
+		Request:  req,
+		Identity: &types.Identity{Name: "alice", Groups: []string{"devs"}},
+	}
+
+	assert.NoError(t, access.CanGet(apiContext, schema))
+	assert.NoError(t, access.CanGet(apiContext, schema))
+	assert.Error(t, access.CanDelete(apiContext, nil, schema))
+	assert.Error(t, access.CanCreate(apiContext, schema))
+
+	if assert.Len(t, reviews.reviews, 2) {
+		attrs := reviews.reviews[0].ResourceAttributes
+		assert.Equal(t, "alice", reviews.reviews[0].User)
+		assert.Equal(t, "default", attrs.Namespace)
+		assert.Equal(t, "nginx", attrs.Name)
+		assert.Equal(t, "pods", attrs.Resource)
+		assert.Equal(t, "core", attrs.Group)
+	}
+}
+
+func TestRBACAccessFilterList(t *testing.T) {
+	reviews := &namespaceReviews{allowed: "default"}
+	access := NewRBACAccess(reviews, nil, time.Minute)
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	apiContext := &types.APIContext{
+		Request:  req,
+		Identity: &types.Identity{Name: "alice"},
+	}
+
+	result := access.FilterList(apiContext, &types.Schema{PluralName: "pods"}, []map[string]interface{}{
+		{"name": "a", "namespaceId": "default"},
+		{"name": "b", "namespaceId": "system"},
+		{"name": "c", "namespaceId": "default"},
+	}, map[string]string{"resource": "pods"})
+
+	assert.Len(t, result, 2)
+	// one cluster wide review and one per namespace
+	assert.Equal(t, 3, reviews.count)
+}
+
+type namespaceReviews struct {
+	allowed string
+	count   int
+}
+
+func (n *namespaceReviews) SubjectAccessReviews() authzclient.SubjectAccessReviewInterface {
+	return n
+}
+
+func (n *namespaceReviews) Create(sar *authzv1.SubjectAccessReview) (*authzv1.SubjectAccessReview, error) {
+	n.count++
+	sar.Status.Allowed = sar.Spec.ResourceAttributes.Namespace == n.allowed
+	return sar, nil
+}
+
+func TestRBACAccessFilterListRules(t *testing.T) {
+	reviews := &namespaceReviews{allowed: "default"}
+	listers := &RBACListers{
+		Roles:        rbaclisters.NewRoleLister(newIndexer()),
+		RoleBindings: rbaclisters.NewRoleBindingLister(newIndexer()),
+		ClusterRoles: rbaclisters.NewClusterRoleLister(newIndexer(&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a-viewer"},
+			Rules: []rbacv1.PolicyRule{{
+				Verbs:         []string{"get"},
+				APIGroups:     []string{""},
+				Resources:     []string{"nodes"},
+				ResourceNames: []string{"a"},
+			}},
+		})),
+		ClusterRoleBindings: rbaclisters.NewClusterRoleBindingLister(newIndexer(&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "devs-node-a-viewer"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "devs"}},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "node-a-viewer"},
+		})),
+	}
+	access := NewRBACAccess(reviews, listers, 0)
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	apiContext := &types.APIContext{
+		Request:  req,
+		Identity: &types.Identity{Name: "alice", Groups: []string{"devs"}},
+	}
+
+	nodes := []map[string]interface{}{
+		{"name": "a"},
+		{"name": "b"},
+		{"name": "c"},
+	}
+	for i := 0; i < 2; i++ {
+		result := access.FilterList(apiContext, &types.Schema{PluralName: "nodes"}, nodes, map[string]string{"resource": "nodes"})
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, "a", result[0]["name"])
+		}
+	}
+	// only the cached cluster wide review, the objects are checked against the rules
+	assert.Equal(t, 1, reviews.count)
+}
+
+func newIndexer(objs ...runtime.Object) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		indexer.Add(obj)
+	}
+	return indexer
+}
+
+func TestRBACAccessAuthorize(t *testing.T) {
+	reviews := &fakeReviews{}
+	access := NewRBACAccess(reviews, nil, 0)
+	schema := &types.Schema{PluralName: "pods", Scope: types.NamespaceScope}
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Impersonate-User", "bob")
+	req.Header.Add("Impersonate-Group", "devs")
+	apiContext := &types.APIContext{Request: req}
+
+	assert.NoError(t, access.Authorize(apiContext, schema, "get", "default:nginx"))
+	assert.Error(t, access.Authorize(apiContext, schema, "delete", "default:nginx"))
+	if assert.Len(t, reviews.reviews, 2) {
+		assert.Equal(t, "bob", reviews.reviews[0].User)
+		assert.Equal(t, []string{"devs"}, reviews.reviews[0].Groups)
+		assert.Equal(t, "default", reviews.reviews[0].ResourceAttributes.Namespace)
+		assert.Equal(t, "nginx", reviews.reviews[0].ResourceAttributes.Name)
+	}
+
+	err := access.Authorize(&types.APIContext{Request: httptest.NewRequest(http.MethodGet, "/", nil)}, schema, "get", "")
+	assert.True(t, httperror.IsAPIError(err))
+	assert.Equal(t, httperror.Unauthorized, err.(*httperror.APIError).Code)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/rancher/norman/api"
@@ -16,7 +17,9 @@ import (
 	"github.com/rancher/norman/types"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 type serverContextKey struct{}
@@ -42,7 +45,8 @@ func (c *Config) Build(ctx context.Context, opts *Options) (context.Context, *Se
 	}
 
 	r := &Runtime{
-		AllSchemas: types.NewSchemas(),
+		AllSchemas:    types.NewSchemas(),
+		HealthHandler: controller.HealthHandler(c.HealthMaxConsecutiveErrors),
 	}
 
 	server := &Server{
@@ -68,6 +72,12 @@ func (c *Config) Build(ctx context.Context, opts *Options) (context.Context, *Se
 		return ctx, nil, err
 	}
 
+	var factories []controller.ClientFactory
+	for _, clientFactory := range c.Clients {
+		factories = append(factories, controller.ClientFactory(clientFactory))
+	}
+	r.Clusters = controller.NewClusters(c.Threadiness, factories...)
+
 	if c.CustomizeSchemas != nil {
 		if err := c.CustomizeSchemas(ctx, c.ClientGetter, r.AllSchemas); err != nil {
 			return ctx, nil, err
@@ -114,6 +124,8 @@ func (c *Config) apiServer(ctx context.Context, r *Runtime) error {
 	}
 
 	server := api.NewAPIServer()
+	server.CORS = c.CORS
+	server.Middleware = append(server.Middleware, c.healthMiddleware(r.HealthHandler))
 	if err := server.AddSchemas(r.AllSchemas); err != nil {
 		return err
 	}
@@ -129,6 +141,24 @@ func (c *Config) apiServer(ctx context.Context, r *Runtime) error {
 	return nil
 }
 
+// healthMiddleware serves the controller health at the HealthPath, before requests are authenticated so probes
+// don't need credentials.
+func (c *Config) healthMiddleware(health http.Handler) func(http.Handler) http.Handler {
+	path := c.HealthPath
+	if path == "" {
+		path = "/healthz"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == path {
+				health.ServeHTTP(rw, req)
+				return
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
 func (c *Config) registerControllers(ctx context.Context, controllers []ControllerRegister) error {
 	for _, controller := range controllers {
 		if err := controller(ctx); err != nil {
@@ -140,7 +170,7 @@ func (c *Config) registerControllers(ctx context.Context, controllers []Controll
 }
 
 func (c *Config) masterControllers(ctx context.Context, starters []controller.Starter, r *Runtime) {
-	leader.RunOrDie(ctx, c.LeaderLockNamespace, c.Name, c.K8sClient, func(ctx context.Context) {
+	leader.RunWithConfigOrDie(ctx, c.LeaderLockNamespace, c.Name, c.K8sClient, c.LeaderElection, func(ctx context.Context) {
 		var (
 			err error
 		)
@@ -162,6 +192,7 @@ func (c *Config) masterControllers(ctx context.Context, starters []controller.St
 		}
 
 		<-ctx.Done()
+		controller.WaitForDrain(starters...)
 	})
 }
 
@@ -248,6 +279,12 @@ func (c *Config) clients(ctx context.Context, r *Runtime) (context.Context, []co
 		}
 	}
 
+	eventScheme, err := c.eventScheme()
+	if err != nil {
+		return ctx, nil, err
+	}
+	ctx = controller.WithEventRecorder(ctx, controller.NewEventRecorder(c.K8sClient, c.Name, eventScheme))
+
 	for _, clientFactory := range c.Clients {
 		ctx, starter, err = clientFactory(ctx, *c.Config)
 		if err != nil {
@@ -258,3 +295,17 @@ func (c *Config) clients(ctx context.Context, r *Runtime) (context.Context, []co
 
 	return ctx, starters, nil
 }
+
+// eventScheme returns the client-go scheme extended with the generated types of AddToSchemes.
+func (c *Config) eventScheme() (*runtime.Scheme, error) {
+	eventScheme := runtime.NewScheme()
+	if err := scheme.AddToScheme(eventScheme); err != nil {
+		return nil, err
+	}
+	for _, addToScheme := range c.AddToSchemes {
+		if err := addToScheme(eventScheme); err != nil {
+			return nil, err
+		}
+	}
+	return eventScheme, nil
+}
@@ -0,0 +1,95 @@
+package clientbase
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+)
+
+// ResponseCache stores response bodies with their ETag.
+type ResponseCache interface {
+	Get(key string) (etag string, body []byte, ok bool)
+	Set(key, etag string, body []byte)
+}
+
+type cacheEntry struct {
+	key  string
+	etag string
+	body []byte
+}
+
+// MemoryCache is a ResponseCache keeping the most recently used responses in memory.
+type MemoryCache struct {
+	sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+func (m *MemoryCache) Get(key string) (string, []byte, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return "", nil, false
+	}
+	m.lru.MoveToFront(element)
+	entry := element.Value.(*cacheEntry)
+	return entry.etag, entry.body, true
+}
+
+func (m *MemoryCache) Set(key, etag string, body []byte) {
+	m.Lock()
+	defer m.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.lru.MoveToFront(element)
+		element.Value = &cacheEntry{key: key, etag: etag, body: body}
+		return
+	}
+
+	m.entries[key] = m.lru.PushFront(&cacheEntry{key: key, etag: etag, body: body})
+	for m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cachedResponse returns the cache key of req and the cached body, setting If-None-Match if there is one. Keys
+// include the credentials so users sharing a cache never see each other's responses.
+func (a *APIOperations) cachedResponse(req *http.Request) (string, []byte) {
+	if a.Opts == nil || a.Opts.Cache == nil {
+		return "", nil
+	}
+
+	auth := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	key := req.URL.String() + " " + hex.EncodeToString(auth[:8])
+
+	etag, body, ok := a.Opts.Cache.Get(key)
+	if !ok {
+		return key, nil
+	}
+	req.Header.Set("If-None-Match", etag)
+	return key, body
+}
+
+func (a *APIOperations) cacheResponse(key string, resp *http.Response, body []byte) {
+	if key == "" {
+		return
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		a.Opts.Cache.Set(key, etag, body)
+	}
+}
@@ -2,8 +2,7 @@ package clientbase
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -12,11 +11,13 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
 	"github.com/rancher/norman/types"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -28,6 +29,8 @@ var (
 	Debug = false
 )
 
+//go:generate moq -out fakes/zz_generated_api_base_client_mock.go -pkg fakes . APIBaseClientInterface
+
 type APIBaseClientInterface interface {
 	Websocket(url string, headers map[string][]string) (*websocket.Conn, *http.Response, error)
 	List(schemaType string, opts *types.ListOpts, respObject interface{}) error
@@ -58,9 +61,52 @@ type ClientOpts struct {
 	WSDialer   *websocket.Dialer
 	CACerts    string
 	Insecure   bool
+	Retry      RetryPolicy
+	// RetryTooManyRequests is how often a request answered with 429 or 503 and a Retry-After is retried after
+	// waiting as asked, even if Retry does not retry it.
+	RetryTooManyRequests int
+
+	// CACertsFile is a file with PEM encoded CAs trusted in addition to CACerts.
+	CACertsFile string
+	// ClientCert and ClientKey are a PEM encoded certificate and key for mutual TLS, or set the files.
+	ClientCert     string
+	ClientKey      string
+	ClientCertFile string
+	ClientKeyFile  string
+	// TLSMinVersion and CipherSuites restrict the TLS connection, see the constants of crypto/tls.
+	TLSMinVersion uint16
+	CipherSuites  []uint16
+	// ServerSPIFFEID requires the server certificate to carry this SPIFFE ID, like spiffe://example.org/server,
+	// as URI SAN.
+	ServerSPIFFEID string
+	// DebugRequests logs every request with its latency and status, and a curl command reproducing failed
+	// requests. Credentials are redacted.
+	DebugRequests bool
+	// QPS limits how many requests per second the client sends, allowing bursts of Burst requests. Zero means no
+	// limit. Burst defaults to 10.
+	QPS   float32
+	Burst int
+	// Cache stores GET responses with their ETag to revalidate them with If-None-Match, see NewMemoryCache.
+	Cache ResponseCache
+	// CredentialProvider replaces the static keys with credentials that are refreshed while the client is used.
+	CredentialProvider CredentialProvider
+	// MaxIdleConnsPerHost and IdleConnTimeout tune how many connections are kept open for reuse and for how long.
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	// DisableHTTP2 keeps the client on HTTP/1.1 even if the server supports HTTP/2.
+	DisableHTTP2 bool
+	// ProxyURL sends all requests through the proxy, Proxy selects the proxy per request. The proxy of the
+	// environment is used if neither is set.
+	ProxyURL string
+	Proxy    func(*http.Request) (*url.URL, error)
+
+	credentials *credentialCache
 }
 
 func (c *ClientOpts) getAuthHeader() string {
+	if c.credentials != nil {
+		return c.credentialsAuthHeader()
+	}
 	if c.TokenKey != "" {
 		return "Bearer " + c.TokenKey
 	}
@@ -77,21 +123,16 @@ type APIError struct {
 	Msg        string
 	Status     string
 	Body       string
+	// Code is the machine readable code of the API error, like NotFound or AlreadyExists.
+	Code string
+	// RetryAfter is how long the server asked to wait before retrying, set for 429 and 503.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
 	return e.Msg
 }
 
-func IsNotFound(err error) bool {
-	apiError, ok := err.(*APIError)
-	if !ok {
-		return false
-	}
-
-	return apiError.StatusCode == http.StatusNotFound
-}
-
 func NewAPIError(resp *http.Response, url string) *APIError {
 	contents, err := ioutil.ReadAll(resp.Body)
 	var body string
@@ -101,8 +142,10 @@ func NewAPIError(resp *http.Response, url string) *APIError {
 		body = string(contents)
 	}
 
+	var code string
 	data := map[string]interface{}{}
 	if json.Unmarshal(contents, &data) == nil {
+		code, _ = data["code"].(string)
 		delete(data, "id")
 		delete(data, "links")
 		delete(data, "actions")
@@ -122,13 +165,18 @@ func NewAPIError(resp *http.Response, url string) *APIError {
 	}
 	formattedMsg := fmt.Sprintf("Bad response statusCode [%d]. Status [%s]. Body: [%s] from [%s]",
 		resp.StatusCode, resp.Status, body, url)
-	return &APIError{
+	apiError := &APIError{
 		URL:        url,
 		Msg:        formattedMsg,
 		StatusCode: resp.StatusCode,
 		Status:     resp.Status,
 		Body:       body,
+		Code:       code,
+	}
+	if throttled(resp) {
+		apiError.RetryAfter = retryAfter(resp.Header.Get("Retry-After"))
 	}
+	return apiError
 }
 
 func contains(array []string, item string) bool {
@@ -184,27 +232,20 @@ func NewAPIClient(opts *ClientOpts) (APIBaseClient, error) {
 
 	client.Timeout = opts.Timeout
 
-	if opts.CACerts != "" {
-		roots := x509.NewCertPool()
-		ok := roots.AppendCertsFromPEM([]byte(opts.CACerts))
-		if !ok {
-			return result, err
-		}
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: roots,
-			},
-		}
-		client.Transport = tr
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return result, err
+	}
+	transport, err := opts.transport(tlsConfig)
+	if err != nil {
+		return result, err
+	}
+	if transport != nil {
+		client.Transport = transport
 	}
 
-	if opts.Insecure {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: opts.Insecure,
-			},
-		}
-		client.Transport = tr
+	if err := opts.setupCredentials(client); err != nil {
+		return result, err
 	}
 
 	req, err := http.NewRequest("GET", opts.URL, nil)
@@ -230,6 +271,7 @@ func NewAPIClient(opts *ClientOpts) (APIBaseClient, error) {
 		return result, errors.New("Failed to find schema at [" + opts.URL + "]")
 	}
 
+	schemasURLs = resolveURL(opts.URL, schemasURLs)
 	if schemasURLs != opts.URL {
 		req, err = http.NewRequest("GET", schemasURLs, nil)
 		if err != nil {
@@ -277,17 +319,28 @@ func NewAPIClient(opts *ClientOpts) (APIBaseClient, error) {
 	result.Ops = &APIOperations{
 		Opts:   opts,
 		Client: client,
-		Dialer: &websocket.Dialer{},
+		Dialer: &websocket.Dialer{EnableCompression: true},
 		Types:  result.Types,
 	}
 
+	if opts.QPS > 0 {
+		burst := opts.Burst
+		if burst <= 0 {
+			burst = 10
+		}
+		result.Ops.limiter = rate.NewLimiter(rate.Limit(opts.QPS), burst)
+	}
+
 	if result.Opts.WSDialer != nil {
 		result.Ops.Dialer = result.Opts.WSDialer
 	}
 
 	ht, ok := client.Transport.(*http.Transport)
-	if ok {
-		result.Ops.Dialer.TLSClientConfig = ht.TLSClientConfig
+	if ok && ht.TLSClientConfig != nil {
+		// Websockets need HTTP/1.1, so h2 must not be offered
+		tlsConfig := ht.TLSClientConfig.Clone()
+		tlsConfig.NextProtos = nil
+		result.Ops.Dialer.TLSClientConfig = tlsConfig
 	}
 
 	return result, nil
@@ -300,8 +353,11 @@ func NewListOpts() *types.ListOpts {
 }
 
 func (a *APIBaseClient) Websocket(url string, headers map[string][]string) (*websocket.Conn, *http.Response, error) {
+	if a.Opts != nil {
+		url = resolveURL(strings.Replace(a.Opts.URL, "http", "ws", 1), url)
+	}
 	httpHeaders := http.Header{}
-	for k, v := range httpHeaders {
+	for k, v := range headers {
 		httpHeaders[k] = v
 	}
 
@@ -316,6 +372,13 @@ func (a *APIBaseClient) Websocket(url string, headers map[string][]string) (*web
 	return a.Ops.Dialer.Dial(url, http.Header(httpHeaders))
 }
 
+// WithContext returns a copy of the client sending every request with ctx. Websocket connections are not bound to
+// ctx.
+func (a APIBaseClient) WithContext(ctx context.Context) APIBaseClient {
+	a.Ops = a.Ops.WithContext(ctx)
+	return a
+}
+
 func (a *APIBaseClient) List(schemaType string, opts *types.ListOpts, respObject interface{}) error {
 	return a.Ops.DoList(schemaType, opts, respObject)
 }
@@ -365,6 +428,10 @@ func (a *APIBaseClient) Reload(existing *types.Resource, output interface{}) err
 	return a.Ops.DoGet(selfURL, NewListOpts(), output)
 }
 
+// Action runs an action with untyped input and output.
+//
+// Deprecated: use the typed Action methods of the generated clients, or DynamicClient for schemas only known at
+// runtime.
 func (a *APIBaseClient) Action(schemaType string, action string,
 	existing *types.Resource, inputObject, respObject interface{}) error {
 	return a.Ops.DoAction(schemaType, action, existing, inputObject, respObject)
@@ -376,3 +443,16 @@ func init() {
 		fmt.Println("Rancher client debug on")
 	}
 }
+
+// resolveURL resolves link, which may be relative if the server emits relative links, against base.
+func resolveURL(base, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.IsAbs() {
+		return link
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return link
+	}
+	return baseURL.ResolveReference(u).String()
+}
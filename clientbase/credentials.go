@@ -0,0 +1,148 @@
+package clientbase
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+)
+
+// expiryMargin is how long before their expiry credentials are refreshed.
+const expiryMargin = time.Minute
+
+// Credentials are returned by a CredentialProvider. Only the set fields are used.
+type Credentials struct {
+	TokenKey    string
+	AccessKey   string
+	SecretKey   string
+	Certificate *tls.Certificate
+	// Expiry is when the credentials stop being valid, zero meaning they are only refreshed on 401.
+	Expiry time.Time
+}
+
+// CredentialProvider returns fresh credentials. It is called for the first request, shortly before the
+// credentials expire and when the server answered 401.
+type CredentialProvider func() (*Credentials, error)
+
+type credentialCache struct {
+	sync.Mutex
+	provider    CredentialProvider
+	credentials *Credentials
+}
+
+func (c *credentialCache) get() (*Credentials, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.credentials != nil && (c.credentials.Expiry.IsZero() || time.Until(c.credentials.Expiry) > expiryMargin) {
+		return c.credentials, nil
+	}
+
+	credentials, err := c.provider()
+	if err != nil {
+		return nil, err
+	}
+	c.credentials = credentials
+	return credentials, nil
+}
+
+func (c *credentialCache) invalidate() {
+	c.Lock()
+	defer c.Unlock()
+	c.credentials = nil
+}
+
+func (c *ClientOpts) credentialsAuthHeader() string {
+	credentials, err := c.credentials.get()
+	if err != nil {
+		logrus.Errorf("Failed to get client credentials: %v", err)
+		return ""
+	}
+	if credentials.TokenKey != "" {
+		return "Bearer " + credentials.TokenKey
+	}
+	if credentials.AccessKey != "" && credentials.SecretKey != "" {
+		s := credentials.AccessKey + ":" + credentials.SecretKey
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	return ""
+}
+
+// setupCredentials makes the client use the certificates of the credential provider, falling back to the
+// certificates configured on its transport when the provider returns none.
+func (c *ClientOpts) setupCredentials(client *http.Client) error {
+	if c.CredentialProvider == nil {
+		return nil
+	}
+	c.credentials = &credentialCache{
+		provider: c.CredentialProvider,
+	}
+
+	if client.Transport == nil {
+		tr := newTransport()
+		tr.TLSClientConfig = &tls.Config{}
+		if err := http2.ConfigureTransport(tr); err != nil {
+			return errors.Wrap(err, "failed to enable HTTP/2")
+		}
+		client.Transport = tr
+	}
+	tr, ok := client.Transport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+
+	var tlsConfig *tls.Config
+	if tr.TLSClientConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tr.TLSClientConfig.Clone()
+	}
+	getClientCertificate := tlsConfig.GetClientCertificate
+	certificates := tlsConfig.Certificates
+	tlsConfig.GetClientCertificate = func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		credentials, err := c.credentials.get()
+		if err != nil {
+			return nil, err
+		}
+		if credentials.Certificate != nil {
+			return credentials.Certificate, nil
+		}
+		if getClientCertificate != nil {
+			return getClientCertificate(info)
+		}
+		if len(certificates) > 0 {
+			return &certificates[0], nil
+		}
+		return &tls.Certificate{}, nil
+	}
+	tr.TLSClientConfig = tlsConfig
+	return nil
+}
+
+// refreshOnUnauthorized sends req again with fresh credentials if the server answered 401.
+func (a *APIOperations) refreshOnUnauthorized(req *http.Request, resp *http.Response, err error) (*http.Response, error) {
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || a.Opts == nil || a.Opts.credentials == nil {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, err
+	}
+
+	resp.Body.Close()
+	a.Opts.credentials.invalidate()
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	req.Header.Set("Authorization", a.Opts.getAuthHeader())
+	return a.send(req)
+}
@@ -0,0 +1,55 @@
+package clientbase
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupCredentialsCertificates(t *testing.T) {
+	static := tls.Certificate{Certificate: [][]byte{[]byte("static")}}
+	provided := &tls.Certificate{Certificate: [][]byte{[]byte("provided")}}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{static}}
+
+	credentials := &Credentials{TokenKey: "token"}
+	opts := &ClientOpts{
+		CredentialProvider: func() (*Credentials, error) {
+			return credentials, nil
+		},
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	if !assert.NoError(t, opts.setupCredentials(client)) {
+		return
+	}
+	assert.Nil(t, tlsConfig.GetClientCertificate)
+
+	getClientCertificate := client.Transport.(*http.Transport).TLSClientConfig.GetClientCertificate
+	cert, err := getClientCertificate(&tls.CertificateRequestInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, &static, cert)
+
+	credentials.Certificate = provided
+	opts.credentials.invalidate()
+	cert, err = getClientCertificate(&tls.CertificateRequestInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, provided, cert)
+}
+
+func TestSetupCredentialsDefaultTransport(t *testing.T) {
+	opts := &ClientOpts{
+		CredentialProvider: func() (*Credentials, error) {
+			return &Credentials{}, nil
+		},
+	}
+	client := &http.Client{}
+	if !assert.NoError(t, opts.setupCredentials(client)) {
+		return
+	}
+	tr := client.Transport.(*http.Transport)
+	assert.NotNil(t, tr.Proxy)
+	assert.NotNil(t, tr.DialContext)
+	assert.NotZero(t, tr.TLSHandshakeTimeout)
+	assert.NotNil(t, tr.TLSClientConfig.GetClientCertificate)
+}
@@ -0,0 +1,101 @@
+package clientbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "REDACTED"
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization":      true,
+	"Cookie":             true,
+	"X-Api-Tunnel-Token": true,
+}
+
+func logRequest(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+	headers := sanitizedHeaders(req.Header)
+	switch {
+	case err != nil:
+		logrus.Infof("%s %s %v failed after %v: %v", req.Method, req.URL, headers, latency, err)
+	default:
+		logrus.Infof("%s %s %v %d in %v", req.Method, req.URL, headers, resp.StatusCode, latency)
+	}
+
+	if err != nil || resp.StatusCode >= 400 {
+		logrus.Infof("Reproduce with: %s", curlCommand(req))
+	}
+}
+
+func sanitizedHeaders(header http.Header) map[string]string {
+	result := map[string]string{}
+	for k, v := range header {
+		if sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			result[k] = redacted
+		} else {
+			result[k] = strings.Join(v, ",")
+		}
+	}
+	return result
+}
+
+// curlCommand returns a curl command line sending req, with credentials in headers and the body redacted.
+func curlCommand(req *http.Request) string {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "curl -X %s", req.Method)
+
+	headers := sanitizedHeaders(req.Header)
+	var keys []string
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, " -H %s", shellQuote(k+": "+headers[k]))
+	}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, err := ioutil.ReadAll(body)
+			body.Close()
+			if err == nil && len(data) > 0 {
+				fmt.Fprintf(buf, " --data-binary %s", shellQuote(string(redactBody(data))))
+			}
+		}
+	}
+
+	fmt.Fprintf(buf, " %s", shellQuote(req.URL.String()))
+	return buf.String()
+}
+
+// redactBody replaces the values of top level JSON fields that look like credentials.
+func redactBody(data []byte) []byte {
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return data
+	}
+
+	for k := range obj {
+		lower := strings.ToLower(k)
+		if strings.Contains(lower, "password") || strings.Contains(lower, "secret") || strings.Contains(lower, "token") {
+			obj[k] = redacted
+		}
+	}
+
+	result, err := json.Marshal(obj)
+	if err != nil {
+		return data
+	}
+	return result
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,126 @@
+package clientbase
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+)
+
+// DynamicClient works on resources as maps using the schemas loaded from the server, for tools that do not know
+// the types of the server at compile time.
+type DynamicClient struct {
+	APIBaseClient
+}
+
+// DynamicCollection is a page of resources listed by a DynamicClient.
+type DynamicCollection struct {
+	types.Collection
+	Data   []map[string]interface{} `json:"data,omitempty"`
+	client *DynamicClient
+}
+
+func NewDynamicClient(opts *ClientOpts) (*DynamicClient, error) {
+	baseClient, err := NewAPIClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &DynamicClient{
+		APIBaseClient: baseClient,
+	}, nil
+}
+
+// SchemaTypes returns the IDs of all schemas of the server.
+func (d *DynamicClient) SchemaTypes() []string {
+	var result []string
+	for id := range d.Types {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (d *DynamicClient) Schema(schemaType string) (types.Schema, bool) {
+	schema, ok := d.Types[schemaType]
+	return schema, ok
+}
+
+func (d *DynamicClient) List(schemaType string, opts *types.ListOpts) (*DynamicCollection, error) {
+	resp := &DynamicCollection{}
+	err := d.Ops.DoList(schemaType, opts, resp)
+	resp.client = d
+	return resp, err
+}
+
+// Next returns the next page of the collection or nil if this is the last page.
+func (c *DynamicCollection) Next() (*DynamicCollection, error) {
+	if c == nil || c.Pagination == nil || c.Pagination.Next == "" {
+		return nil, nil
+	}
+	resp := &DynamicCollection{}
+	err := c.client.Ops.DoNext(c.Pagination.Next, resp)
+	resp.client = c.client
+	return resp, err
+}
+
+func (d *DynamicClient) ByID(schemaType, id string) (map[string]interface{}, error) {
+	resp := map[string]interface{}{}
+	err := d.Ops.DoByID(schemaType, id, &resp)
+	return resp, err
+}
+
+func (d *DynamicClient) Create(schemaType string, obj map[string]interface{}) (map[string]interface{}, error) {
+	resp := map[string]interface{}{}
+	err := d.Ops.DoCreate(schemaType, obj, &resp)
+	return resp, err
+}
+
+// Update updates the fields in updates of existing, as returned by the other methods.
+func (d *DynamicClient) Update(existing, updates map[string]interface{}) (map[string]interface{}, error) {
+	resource := toResource(existing)
+	resp := map[string]interface{}{}
+	err := d.Ops.DoUpdate(resource.Type, &resource, updates, &resp)
+	return resp, err
+}
+
+func (d *DynamicClient) Delete(existing map[string]interface{}) error {
+	resource := toResource(existing)
+	return d.Ops.DoResourceDelete(resource.Type, &resource)
+}
+
+func (d *DynamicClient) Action(existing map[string]interface{}, action string, input interface{}) (map[string]interface{}, error) {
+	resource := toResource(existing)
+	resp := map[string]interface{}{}
+	err := d.Ops.DoAction(resource.Type, action, &resource, input, &resp)
+	return resp, err
+}
+
+// Follow gets the link of existing, which may be a resource or a collection.
+func (d *DynamicClient) Follow(existing map[string]interface{}, link string) (map[string]interface{}, error) {
+	resource := toResource(existing)
+	url, ok := resource.Links[link]
+	if !ok {
+		return nil, fmt.Errorf("failed to find link: %s", link)
+	}
+
+	resp := map[string]interface{}{}
+	err := d.Ops.DoGet(url, nil, &resp)
+	return resp, err
+}
+
+func toResource(obj map[string]interface{}) types.Resource {
+	resource := types.Resource{
+		ID:      convert.ToString(obj["id"]),
+		Type:    convert.ToString(obj["type"]),
+		Links:   map[string]string{},
+		Actions: map[string]string{},
+	}
+	for k, v := range convert.ToMapInterface(obj["links"]) {
+		resource.Links[k] = convert.ToString(v)
+	}
+	for k, v := range convert.ToMapInterface(obj["actions"]) {
+		resource.Actions[k] = convert.ToString(v)
+	}
+	return resource
+}
@@ -0,0 +1,73 @@
+package clientbase
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors an *APIError matches by status code, with errors.Is on Go versions that have it.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+var statusErrors = map[int]error{
+	http.StatusNotFound:        ErrNotFound,
+	http.StatusConflict:        ErrConflict,
+	http.StatusForbidden:       ErrForbidden,
+	http.StatusUnauthorized:    ErrUnauthorized,
+	http.StatusTooManyRequests: ErrRateLimited,
+}
+
+func (e *APIError) Is(target error) bool {
+	return target != nil && statusErrors[e.StatusCode] == target
+}
+
+// AsAPIError returns the *APIError err was caused by, following errors wrapped with github.com/pkg/errors.
+func AsAPIError(err error) (*APIError, bool) {
+	apiError, ok := errors.Cause(err).(*APIError)
+	return apiError, ok && apiError != nil
+}
+
+func hasStatus(err error, code int) bool {
+	apiError, ok := AsAPIError(err)
+	return ok && apiError.StatusCode == code
+}
+
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// TooManyRequests returns how long to wait if err is a 429 or 503 with a Retry-After from the server.
+func TooManyRequests(err error) (time.Duration, bool) {
+	apiError, ok := AsAPIError(err)
+	if !ok {
+		return 0, false
+	}
+	switch apiError.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return apiError.RetryAfter, true
+	}
+	return 0, false
+}
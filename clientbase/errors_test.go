@@ -0,0 +1,24 @@
+package clientbase
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	err := errors.Wrap(&APIError{StatusCode: http.StatusNotFound}, "lookup failed")
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsConflict(err))
+
+	apiError, ok := AsAPIError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, apiError.StatusCode)
+	}
+	assert.True(t, apiError.Is(ErrNotFound))
+
+	assert.True(t, IsRateLimited(&APIError{StatusCode: http.StatusTooManyRequests}))
+	assert.False(t, IsNotFound(nil))
+}
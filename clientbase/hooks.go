@@ -0,0 +1,24 @@
+package clientbase
+
+import (
+	"net/http"
+)
+
+// RequestHook is called before a request is sent, for example to add headers. Returning an error fails the
+// request without sending it.
+type RequestHook func(req *http.Request) error
+
+// ResponseHook is called with the result of a request, after retries, and returns the result passed on to the
+// caller. Hooks may replace the response, for example by sending the request again after refreshing credentials.
+type ResponseHook func(req *http.Request, resp *http.Response, err error) (*http.Response, error)
+
+// AddRequestHook adds hooks called in order before every request of the client. Websocket connections are not
+// passed to hooks.
+func (a *APIBaseClient) AddRequestHook(hooks ...RequestHook) {
+	a.Ops.RequestHooks = append(a.Ops.RequestHooks, hooks...)
+}
+
+// AddResponseHook adds hooks called in order with the result of every request of the client.
+func (a *APIBaseClient) AddResponseHook(hooks ...ResponseHook) {
+	a.Ops.ResponseHooks = append(a.Ops.ResponseHooks, hooks...)
+}
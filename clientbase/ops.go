@@ -2,6 +2,7 @@ package clientbase
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,28 +14,59 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
 	"github.com/rancher/norman/types"
+	"golang.org/x/time/rate"
 )
 
 type APIOperations struct {
-	Opts   *ClientOpts
-	Types  map[string]types.Schema
-	Client *http.Client
-	Dialer *websocket.Dialer
+	Opts          *ClientOpts
+	Types         map[string]types.Schema
+	Client        *http.Client
+	Dialer        *websocket.Dialer
+	RequestHooks  []RequestHook
+	ResponseHooks []ResponseHook
+	ctx           context.Context
+	callOptions   *callOptions
+	limiter       *rate.Limiter
+}
+
+// WithContext returns a copy of the operations sending every request with ctx, so callers can set deadlines and
+// cancel requests in flight.
+func (a *APIOperations) WithContext(ctx context.Context) *APIOperations {
+	ops := *a
+	ops.ctx = ctx
+	return &ops
 }
 
 func (a *APIOperations) SetupRequest(req *http.Request) {
-	req.Header.Add("Authorization", a.Opts.getAuthHeader())
+	req.Header.Set("Authorization", a.Opts.getAuthHeader())
+}
+
+func (a *APIOperations) newRequest(method, url string, body io.Reader) (*http.Request, error) {
+	if a.Opts != nil {
+		url = resolveURL(a.Opts.URL, url)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if a.ctx != nil {
+		req = req.WithContext(a.ctx)
+	}
+	if a.callOptions != nil {
+		a.callOptions.apply(req)
+	}
+	return req, nil
 }
 
 func (a *APIOperations) DoDelete(url string) error {
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := a.newRequest("DELETE", url, nil)
 	if err != nil {
 		return err
 	}
 
 	a.SetupRequest(req)
 
-	resp, err := a.Client.Do(req)
+	resp, err := a.do(req)
 	if err != nil {
 		return err
 	}
@@ -63,27 +95,34 @@ func (a *APIOperations) DoGet(url string, opts *types.ListOpts, respObject inter
 		fmt.Println("GET " + url)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := a.newRequest("GET", url, nil)
 	if err != nil {
 		return err
 	}
 
 	a.SetupRequest(req)
 
-	resp, err := a.Client.Do(req)
+	cacheKey, cached := a.cachedResponse(req)
+
+	resp, err := a.do(req)
 	if err != nil {
 		return err
 	}
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
+	var byteContent []byte
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		byteContent = cached
+	case resp.StatusCode != 200:
 		return NewAPIError(resp, url)
-	}
-
-	byteContent, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	default:
+		byteContent, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		a.cacheResponse(cacheKey, resp, byteContent)
 	}
 
 	if Debug {
@@ -136,7 +175,7 @@ func (a *APIOperations) DoModify(method string, url string, createObj interface{
 		fmt.Println("Request => " + string(bodyContent))
 	}
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(bodyContent))
+	req, err := a.newRequest(method, url, bytes.NewBuffer(bodyContent))
 	if err != nil {
 		return err
 	}
@@ -144,7 +183,7 @@ func (a *APIOperations) DoModify(method string, url string, createObj interface{
 	a.SetupRequest(req)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := a.Client.Do(req)
+	resp, err := a.do(req)
 	if err != nil {
 		return err
 	}
@@ -313,6 +352,47 @@ func (a *APIOperations) DoCollectionAction(schemaType string, action string,
 	return a.doAction(schemaType, action, actionURL, inputObject, respObject)
 }
 
+// DoActionStream runs an action whose output is a binary stream. The caller has to close the returned body.
+func (a *APIOperations) DoActionStream(schemaType string, action string,
+	existing *types.Resource, inputObject interface{}) (io.ReadCloser, error) {
+
+	if existing == nil {
+		return nil, errors.New("Existing object is nil")
+	}
+
+	actionURL, ok := existing.Actions[action]
+	if !ok {
+		return nil, fmt.Errorf("action [%v] not available on [%v]", action, existing)
+	}
+
+	resp, err := a.sendAction(schemaType, actionURL, inputObject)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// DoCollectionActionStream runs a collection action whose output is a binary stream. The caller has to close the
+// returned body.
+func (a *APIOperations) DoCollectionActionStream(schemaType string, action string,
+	existing *types.Collection, inputObject interface{}) (io.ReadCloser, error) {
+
+	if existing == nil {
+		return nil, errors.New("Existing object is nil")
+	}
+
+	actionURL, ok := existing.Actions[action]
+	if !ok {
+		return nil, fmt.Errorf("action [%v] not available on [%v]", action, existing)
+	}
+
+	resp, err := a.sendAction(schemaType, actionURL, inputObject)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 func (a *APIOperations) doAction(
 	schemaType string,
 	action string,
@@ -320,9 +400,33 @@ func (a *APIOperations) doAction(
 	inputObject interface{},
 	respObject interface{},
 ) error {
+	resp, err := a.sendAction(schemaType, actionURL, inputObject)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	byteContent, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if Debug {
+		fmt.Println("Response <= " + string(byteContent))
+	}
+
+	if nil != respObject {
+		return json.Unmarshal(byteContent, respObject)
+	}
+	return nil
+}
+
+// sendAction posts inputObject to actionURL and returns the successful response with the body still unread.
+func (a *APIOperations) sendAction(schemaType string, actionURL string, inputObject interface{}) (*http.Response, error) {
 	_, ok := a.Types[schemaType]
 	if !ok {
-		return errors.New("Unknown schema type [" + schemaType + "]")
+		return nil, errors.New("Unknown schema type [" + schemaType + "]")
 	}
 
 	var input io.Reader
@@ -334,7 +438,7 @@ func (a *APIOperations) doAction(
 	if inputObject != nil {
 		bodyContent, err := json.Marshal(inputObject)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if Debug {
 			fmt.Println("Request => " + string(bodyContent))
@@ -342,37 +446,24 @@ func (a *APIOperations) doAction(
 		input = bytes.NewBuffer(bodyContent)
 	}
 
-	req, err := http.NewRequest("POST", actionURL, input)
+	req, err := a.newRequest("POST", actionURL, input)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	a.SetupRequest(req)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Content-Length", "0")
 
-	resp, err := a.Client.Do(req)
+	resp, err := a.do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer resp.Body.Close()
-
 	if resp.StatusCode >= 300 {
-		return NewAPIError(resp, actionURL)
-	}
-
-	byteContent, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
-	if Debug {
-		fmt.Println("Response <= " + string(byteContent))
+		defer resp.Body.Close()
+		return nil, NewAPIError(resp, actionURL)
 	}
 
-	if nil != respObject {
-		return json.Unmarshal(byteContent, respObject)
-	}
-	return nil
+	return resp, nil
 }
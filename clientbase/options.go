@@ -0,0 +1,137 @@
+package clientbase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CallOption changes a single call of a client, see WithHeader, WithQuery and WithTimeout.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	header  http.Header
+	query   url.Values
+	timeout time.Duration
+}
+
+// WithHeader sends the header with the call, for example a trace ID or an impersonation header.
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithQuery adds the query parameter to the URL of the call.
+func WithQuery(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.query == nil {
+			o.query = url.Values{}
+		}
+		o.query.Add(key, value)
+	}
+}
+
+// WithImpersonation makes the call act as user and groups, if the server allows the caller to impersonate them.
+func WithImpersonation(user string, groups ...string) CallOption {
+	return func(o *callOptions) {
+		WithHeader("Impersonate-User", user)(o)
+		for _, group := range groups {
+			WithHeader("Impersonate-Group", group)(o)
+		}
+	}
+}
+
+// WithTimeout limits how long the call, including reading the response, may take.
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithOptions returns a copy of the operations applying opts to every request.
+func (a *APIOperations) WithOptions(opts ...CallOption) *APIOperations {
+	if len(opts) == 0 {
+		return a
+	}
+
+	ops := *a
+	ops.callOptions = a.callOptions.copy()
+	for _, opt := range opts {
+		opt(ops.callOptions)
+	}
+	return &ops
+}
+
+// copy returns a deep copy of o, so options added to the copy don't change the operations it was copied from.
+func (o *callOptions) copy() *callOptions {
+	result := &callOptions{}
+	if o == nil {
+		return result
+	}
+	result.timeout = o.timeout
+	if o.header != nil {
+		result.header = http.Header{}
+		for k, v := range o.header {
+			result.header[k] = append([]string{}, v...)
+		}
+	}
+	if o.query != nil {
+		result.query = url.Values{}
+		for k, v := range o.query {
+			result.query[k] = append([]string{}, v...)
+		}
+	}
+	return result
+}
+
+func (o *callOptions) apply(req *http.Request) {
+	for k, v := range o.header {
+		for _, value := range v {
+			req.Header.Add(k, value)
+		}
+	}
+	if len(o.query) > 0 {
+		q := req.URL.Query()
+		for k, v := range o.query {
+			for _, value := range v {
+				q.Add(k, value)
+			}
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+// withTimeout applies the timeout of the call to req. The returned response has to be closed to release it.
+func (o *callOptions) withTimeout(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if o == nil || o.timeout <= 0 {
+		return send(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), o.timeout)
+	resp, err := send(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{
+		ReadCloser: resp.Body,
+		cancel:     cancel,
+	}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
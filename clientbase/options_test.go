@@ -0,0 +1,25 @@
+package clientbase
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithOptionsCopies(t *testing.T) {
+	parent := (&APIOperations{}).WithOptions(WithHeader("X-Trace", "a"), WithQuery("q", "a"))
+	child := parent.WithOptions(WithHeader("X-Trace", "b"), WithHeader("X-Other", "b"), WithQuery("q", "b"))
+
+	req := httptest.NewRequest("GET", "/v1", nil)
+	parent.callOptions.apply(req)
+	assert.Equal(t, []string{"a"}, req.Header["X-Trace"])
+	assert.Empty(t, req.Header.Get("X-Other"))
+	assert.Equal(t, "q=a", req.URL.RawQuery)
+
+	req = httptest.NewRequest("GET", "/v1", nil)
+	child.callOptions.apply(req)
+	assert.Equal(t, []string{"a", "b"}, req.Header["X-Trace"])
+	assert.Equal(t, "b", req.Header.Get("X-Other"))
+	assert.Equal(t, "q=a&q=b", req.URL.RawQuery)
+}
@@ -0,0 +1,163 @@
+package clientbase
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures retrying requests that failed with a connection error, 429 or a 5xx status. Requests
+// that are not idempotent (POST) are only retried on 429 and 503, when the server did not handle them.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per request, retrying is disabled unless it is above one.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on every further attempt. Defaults to 500ms.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts. Defaults to 30s.
+	MaxBackoff time.Duration
+}
+
+func (r RetryPolicy) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	idempotent := req.Method != http.MethodPost
+	if err != nil {
+		return idempotent && req.Context().Err() == nil
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return true
+	case resp.StatusCode >= 500:
+		return idempotent
+	}
+	return false
+}
+
+// delay returns the jittered exponential backoff for attempt, or the Retry-After of the response if set.
+func (r RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	maxBackoff := r.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	if resp != nil {
+		if after := retryAfter(resp.Header.Get("Retry-After")); after > 0 {
+			if after > maxBackoff {
+				return maxBackoff
+			}
+			return after
+		}
+	}
+
+	backoff := r.Backoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// throttled returns true if the server asked to retry later.
+func throttled(resp *http.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) &&
+		resp.Header.Get("Retry-After") != ""
+}
+
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// do sends req through the hooks, retrying according to the retry policy of the client options.
+func (a *APIOperations) do(req *http.Request) (*http.Response, error) {
+	return a.callOptions.withTimeout(req, a.doHooks)
+}
+
+func (a *APIOperations) doHooks(req *http.Request) (*http.Response, error) {
+	for _, hook := range a.RequestHooks {
+		if err := hook(req); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := a.send(req)
+	resp, err = a.refreshOnUnauthorized(req, resp, err)
+	if a.Opts != nil && a.Opts.DebugRequests {
+		logRequest(req, resp, err, time.Since(start))
+	}
+	for _, hook := range a.ResponseHooks {
+		resp, err = hook(req, resp, err)
+	}
+	return resp, err
+}
+
+func (a *APIOperations) send(req *http.Request) (*http.Response, error) {
+	var (
+		policy       RetryPolicy
+		throttleWait int
+	)
+	if a.Opts != nil {
+		policy = a.Opts.Retry
+		throttleWait = a.Opts.RetryTooManyRequests
+	}
+
+	for attempt := 1; ; attempt++ {
+		if a.limiter != nil {
+			if err := a.limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := a.Client.Do(req)
+		maxAttempts := policy.MaxAttempts
+		if throttled(resp) && throttleWait+1 > maxAttempts {
+			maxAttempts = throttleWait + 1
+		}
+		if attempt >= maxAttempts || !policy.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		delay := policy.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package clientbase
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempts++
+		if attempts < 3 {
+			rw.Header().Set("Retry-After", "0")
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.Write([]byte(`{"id":"test"}`))
+	}))
+	defer server.Close()
+
+	ops := &APIOperations{
+		Opts: &ClientOpts{
+			Retry: RetryPolicy{
+				MaxAttempts: 3,
+				Backoff:     time.Millisecond,
+			},
+		},
+		Client: server.Client(),
+	}
+
+	resp := map[string]interface{}{}
+	err := ops.DoModify(http.MethodPost, server.URL, map[string]string{"name": "test"}, &resp)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, "test", resp["id"])
+
+	attempts = -10
+	err = ops.DoGet(server.URL, nil, &resp)
+	assert.NotNil(t, err)
+	assert.Equal(t, -7, attempts)
+}
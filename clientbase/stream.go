@@ -0,0 +1,152 @@
+package clientbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/rancher/norman/types"
+)
+
+// ItemFunc is called with a pointer to every item of a streamed collection.
+type ItemFunc func(item interface{}) error
+
+// DoListStream lists like DoList but decodes the response while reading it. respObject must point to a collection
+// struct with a Data slice. If each is nil the items are appended to Data, otherwise each is called for every
+// item and Data is left empty, so the collection is never held in memory.
+func (a *APIOperations) DoListStream(schemaType string, opts *types.ListOpts, respObject interface{}, each ItemFunc) error {
+	schema, ok := a.Types[schemaType]
+	if !ok {
+		return errors.New("Unknown schema type [" + schemaType + "]")
+	}
+
+	if !contains(schema.CollectionMethods, "GET") {
+		return errors.New("Resource type [" + schemaType + "] is not listable")
+	}
+
+	collectionURL, ok := schema.Links["collection"]
+	if !ok {
+		return errors.New("Resource type [" + schemaType + "] does not have a collection URL")
+	}
+
+	return a.DoGetStream(collectionURL, opts, respObject, each)
+}
+
+func (a *APIOperations) DoNextStream(nextURL string, respObject interface{}, each ItemFunc) error {
+	return a.DoGetStream(nextURL, nil, respObject, each)
+}
+
+// DoGetStream gets a collection like DoGet, see DoListStream.
+func (a *APIOperations) DoGetStream(url string, opts *types.ListOpts, respObject interface{}, each ItemFunc) error {
+	if opts == nil {
+		opts = NewListOpts()
+	}
+	url, err := appendFilters(url, opts.Filters)
+	if err != nil {
+		return err
+	}
+
+	if Debug {
+		fmt.Println("GET " + url)
+	}
+
+	req, err := a.newRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	a.SetupRequest(req)
+
+	resp, err := a.do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return NewAPIError(resp, url)
+	}
+
+	return errors.Wrapf(decodeCollection(json.NewDecoder(resp.Body), respObject, each), "Failed to parse response of %s", url)
+}
+
+func decodeCollection(decoder *json.Decoder, respObject interface{}, each ItemFunc) error {
+	value := reflect.ValueOf(respObject)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected pointer to collection, got %T", respObject)
+	}
+	data := value.Elem().FieldByName("Data")
+	if !data.IsValid() || data.Kind() != reflect.Slice {
+		return fmt.Errorf("collection %T has no Data slice", respObject)
+	}
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	// Everything but the items is small, so it is collected and decoded at the end
+	rest := map[string]json.RawMessage{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+
+		if key != "data" {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return err
+			}
+			rest[key] = raw
+			continue
+		}
+
+		if err := expectDelim(decoder, '['); err != nil {
+			return err
+		}
+		for decoder.More() {
+			item := reflect.New(data.Type().Elem())
+			if err := decoder.Decode(item.Interface()); err != nil {
+				return err
+			}
+			if each == nil {
+				data.Set(reflect.Append(data, item.Elem()))
+			} else if err := each(item.Interface()); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return err
+		}
+	}
+
+	if err := expectDelim(decoder, '}'); err != nil {
+		return err
+	}
+
+	items := data.Interface()
+	restBytes, err := json.Marshal(rest)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(restBytes, respObject); err != nil {
+		return err
+	}
+	data.Set(reflect.ValueOf(items))
+	return nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package clientbase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+type testCollection struct {
+	types.Collection
+	Data []testItem `json:"data,omitempty"`
+}
+
+func TestDecodeCollection(t *testing.T) {
+	input := `{"type":"collection","data":[{"name":"a"},{"name":"b"}],"pagination":{"next":"http://next"}}`
+
+	collection := &testCollection{}
+	err := decodeCollection(json.NewDecoder(strings.NewReader(input)), collection, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []testItem{{Name: "a"}, {Name: "b"}}, collection.Data)
+	assert.Equal(t, "http://next", collection.Pagination.Next)
+
+	var names []string
+	collection = &testCollection{}
+	err = decodeCollection(json.NewDecoder(strings.NewReader(input)), collection, func(item interface{}) error {
+		names = append(names, item.(*testItem).Name)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.Empty(t, collection.Data)
+}
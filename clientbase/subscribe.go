@@ -0,0 +1,168 @@
+package clientbase
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/rancher/norman/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	EventChange = "resource.change"
+	EventRemove = "resource.remove"
+	// EventResync is sent when changes were lost, so the receiver should list the resources again: the server
+	// dropped events of the slow client, the revision to resume after expired, or the subscription reconnected
+	// before receiving a revision to resume after.
+	EventResync = "resource.resync"
+)
+
+// Event is a message received on the subscribe endpoint of the API.
+type Event struct {
+	Name string `json:"name"`
+	// Revision of the resource, subscriptions can resume after it with the revision filter as type:revision.
+	Revision string          `json:"revision,omitempty"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Subscribe streams the changes of resources of schemaType until ctx is done, reconnecting with backoff when the
+// connection is lost. Filters of opts are applied by the server, which also understands the namespaceId,
+// labelSelector, fieldSelector and fields (a comma separated field mask) filters. Reconnected subscriptions resume
+// after the revision of the last event received, so no changes are lost unless the server reports the revision
+// expired with an EventResync.
+func (a *APIBaseClient) Subscribe(ctx context.Context, schemaType string, opts *types.ListOpts) (<-chan Event, error) {
+	subscribeURL, err := a.subscribeURL(schemaType, opts, "")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, _, err := a.Websocket(subscribeURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(chan Event)
+	go func() {
+		defer close(result)
+
+		var revision string
+		backoff := time.Second
+		for {
+			if last := a.readEvents(ctx, conn, result); last != "" {
+				revision = last
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+
+				subscribeURL, err = a.subscribeURL(schemaType, opts, revision)
+				if err == nil {
+					conn, _, err = a.Websocket(subscribeURL, nil)
+				}
+				if err == nil {
+					break
+				}
+				logrus.Debugf("Failed to reconnect subscription to %s: %v", subscribeURL, err)
+				if backoff < 30*time.Second {
+					backoff *= 2
+				}
+			}
+			backoff = time.Second
+
+			if revision != "" {
+				continue
+			}
+			// Without a revision, for example for stores without revisions, the changes in between are lost
+			select {
+			case result <- Event{Name: EventResync}:
+			case <-ctx.Done():
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+// subscribeURL returns the URL subscribing to schemaType, resuming after revision if set.
+func (a *APIBaseClient) subscribeURL(schemaType string, opts *types.ListOpts, revision string) (string, error) {
+	schema, ok := a.Types["subscribe"]
+	if !ok {
+		return "", errors.New("API does not support subscribe")
+	}
+
+	collectionURL, ok := schema.Links[COLLECTION]
+	if !ok {
+		return "", errors.New("Failed to find collection URL for [subscribe]")
+	}
+
+	var filters map[string]interface{}
+	if opts != nil {
+		filters = opts.Filters
+	}
+	subscribeURL, err := appendFilters(collectionURL, filters)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(subscribeURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("resourceTypes", schemaType)
+	if revision != "" {
+		q.Set("revision", schemaType+":"+revision)
+	}
+	u.RawQuery = q.Encode()
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+
+	return u.String(), nil
+}
+
+// readEvents sends the events read from conn until the connection fails or ctx is done. It returns the revision
+// of the last change sent, if any.
+func (a *APIBaseClient) readEvents(ctx context.Context, conn *websocket.Conn, result chan<- Event) string {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	var revision string
+	for {
+		var event Event
+		if err := conn.ReadJSON(&event); err != nil {
+			return revision
+		}
+		if event.Name != EventChange && event.Name != EventRemove && event.Name != EventResync {
+			continue
+		}
+
+		select {
+		case result <- event:
+			if event.Revision != "" {
+				revision = event.Revision
+			}
+		case <-ctx.Done():
+			return revision
+		}
+	}
+}
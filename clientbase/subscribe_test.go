@@ -0,0 +1,61 @@
+package clientbase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeResumesAfterRevision(t *testing.T) {
+	revisions := make(chan string, 2)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		revision := req.URL.Query().Get("revision")
+		revisions <- revision
+		if revision == "" {
+			conn.WriteJSON(Event{Name: EventChange, Revision: "5", Data: []byte(`{}`)})
+			return
+		}
+		conn.WriteJSON(Event{Name: EventChange, Revision: "6", Data: []byte(`{}`)})
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	client := &APIBaseClient{
+		Ops: &APIOperations{Dialer: &websocket.Dialer{}},
+		Types: map[string]types.Schema{
+			"subscribe": {Links: map[string]string{COLLECTION: server.URL + "/v3/subscribe"}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := client.Subscribe(ctx, "widget", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, expected := range []string{"5", "6"} {
+		select {
+		case event := <-events:
+			assert.Equal(t, EventChange, event.Name)
+			assert.Equal(t, expected, event.Revision)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+	assert.Equal(t, "", <-revisions)
+	assert.Equal(t, "widget:5", <-revisions)
+}
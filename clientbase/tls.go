@@ -0,0 +1,91 @@
+package clientbase
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// tlsConfig returns the TLS configuration of the options or nil if they do not configure TLS.
+func (c *ClientOpts) tlsConfig() (*tls.Config, error) {
+	if c.CACerts == "" && c.CACertsFile == "" && !c.Insecure && c.ClientCert == "" && c.ClientCertFile == "" &&
+		c.TLSMinVersion == 0 && len(c.CipherSuites) == 0 && c.ServerSPIFFEID == "" {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: c.Insecure,
+		MinVersion:         c.TLSMinVersion,
+		CipherSuites:       c.CipherSuites,
+	}
+
+	caCerts := []byte(c.CACerts)
+	if c.CACertsFile != "" {
+		data, err := ioutil.ReadFile(c.CACertsFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read CA certs")
+		}
+		caCerts = append(append(caCerts, '\n'), data...)
+	}
+	if c.CACerts != "" || c.CACertsFile != "" {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caCerts) {
+			return nil, errors.New("failed to parse CA certs")
+		}
+		config.RootCAs = roots
+	}
+
+	cert, err := c.clientCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		config.Certificates = []tls.Certificate{*cert}
+	}
+
+	if c.ServerSPIFFEID != "" {
+		spiffeID := c.ServerSPIFFEID
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			leaf, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			for _, uri := range leaf.URIs {
+				if uri.String() == spiffeID {
+					return nil
+				}
+			}
+			return fmt.Errorf("server certificate does not have SPIFFE ID %s", spiffeID)
+		}
+	}
+
+	return config, nil
+}
+
+func (c *ClientOpts) clientCertificate() (*tls.Certificate, error) {
+	certPEM, keyPEM := []byte(c.ClientCert), []byte(c.ClientKey)
+	if c.ClientCertFile != "" {
+		var err error
+		if certPEM, err = ioutil.ReadFile(c.ClientCertFile); err != nil {
+			return nil, errors.Wrap(err, "failed to read client certificate")
+		}
+		if keyPEM, err = ioutil.ReadFile(c.ClientKeyFile); err != nil {
+			return nil, errors.Wrap(err, "failed to read client key")
+		}
+	}
+	if len(certPEM) == 0 {
+		return nil, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load client certificate")
+	}
+	return &cert, nil
+}
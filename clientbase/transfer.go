@@ -0,0 +1,98 @@
+package clientbase
+
+import (
+	"io"
+	"net/http"
+)
+
+// ProgressFunc is called while a body is transferred with the bytes done so far and the total, which is -1 if
+// unknown.
+type ProgressFunc func(transferred, total int64)
+
+// DoStream sends body, which may be nil, and returns the response body without buffering it. The caller has to
+// close the returned body. Requests with a body are not retried since the body can not be replayed.
+func (a *APIOperations) DoStream(method, url string, body io.Reader, progress ProgressFunc) (io.ReadCloser, error) {
+	req, err := a.newRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	a.SetupRequest(req)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	return a.doStream(req, url, progress)
+}
+
+// DoUpload posts size bytes of body, or an unknown length if size is negative, reporting the upload to progress.
+// The caller has to close the returned response body.
+func (a *APIOperations) DoUpload(url string, body io.Reader, size int64, progress ProgressFunc) (io.ReadCloser, error) {
+	if progress != nil {
+		body = &progressReader{
+			Reader:   body,
+			total:    size,
+			progress: progress,
+		}
+	}
+
+	req, err := a.newRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	a.SetupRequest(req)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if size < 0 {
+		size = -1
+	}
+	req.ContentLength = size
+
+	return a.doStream(req, url, nil)
+}
+
+func (a *APIOperations) doStream(req *http.Request, url string, progress ProgressFunc) (io.ReadCloser, error) {
+	resp, err := a.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, NewAPIError(resp, url)
+	}
+
+	if progress == nil {
+		return resp.Body, nil
+	}
+
+	return &progressReadCloser{
+		progressReader: progressReader{
+			Reader:   resp.Body,
+			total:    resp.ContentLength,
+			progress: progress,
+		},
+		Closer: resp.Body,
+	}, nil
+}
+
+type progressReader struct {
+	io.Reader
+	transferred int64
+	total       int64
+	progress    ProgressFunc
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.Reader.Read(buf)
+	if n > 0 {
+		p.transferred += int64(n)
+		p.progress(p.transferred, p.total)
+	}
+	return n, err
+}
+
+type progressReadCloser struct {
+	progressReader
+	io.Closer
+}
@@ -0,0 +1,39 @@
+package clientbase
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		rw.Write([]byte(strings.ToUpper(string(data))))
+	}))
+	defer server.Close()
+
+	ops := &APIOperations{
+		Opts:   &ClientOpts{},
+		Client: server.Client(),
+	}
+
+	var uploaded int64
+	body, err := ops.DoUpload(server.URL, strings.NewReader("backup"), 6, func(transferred, total int64) {
+		uploaded = transferred
+		assert.Equal(t, int64(6), total)
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "BACKUP", string(data))
+	assert.Equal(t, int64(6), uploaded)
+}
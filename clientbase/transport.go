@@ -0,0 +1,67 @@
+package clientbase
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+)
+
+// newTransport returns a transport with the settings of http.DefaultTransport.
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: true,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// transport returns the transport configured by the options, or nil if the default transport can be used.
+func (c *ClientOpts) transport(tlsConfig *tls.Config) (*http.Transport, error) {
+	if tlsConfig == nil && c.MaxIdleConnsPerHost == 0 && c.IdleConnTimeout == 0 && !c.DisableHTTP2 &&
+		c.ProxyURL == "" && c.Proxy == nil {
+		return nil, nil
+	}
+
+	transport := newTransport()
+	transport.TLSClientConfig = tlsConfig
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+		if transport.MaxIdleConns < c.MaxIdleConnsPerHost {
+			transport.MaxIdleConns = c.MaxIdleConnsPerHost
+		}
+	}
+	if c.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = c.IdleConnTimeout
+	}
+	if c.DisableHTTP2 {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	} else if err := http2.ConfigureTransport(transport); err != nil {
+		// Transports with their own TLS config only use HTTP/2 if configured explicitly
+		return nil, errors.Wrap(err, "failed to enable HTTP/2")
+	}
+
+	switch {
+	case c.Proxy != nil:
+		transport.Proxy = c.Proxy
+	case c.ProxyURL != "":
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid proxy URL %s", c.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/rancher/norman/objectclient"
 	"k8s.io/api/core/v1"
 	err2 "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -110,6 +111,12 @@ func (c Cond) ReasonAndMessageFromError(obj runtime.Object, err error) {
 	}
 }
 
+// SetReasonCode sets the reason as a machine-readable CamelCase code, see ReasonCode.
+func (c Cond) SetReasonCode(obj runtime.Object, reason string) {
+	cond := findOrCreateCond(obj, string(c))
+	setValue(cond, "Reason", ReasonCode(reason))
+}
+
 func (c Cond) GetReason(obj runtime.Object) string {
 	cond := findOrNotCreateCond(obj, string(c))
 	if cond == nil {
@@ -118,6 +125,27 @@ func (c Cond) GetReason(obj runtime.Object) string {
 	return getFieldValue(*cond, "Reason").String()
 }
 
+func (c Cond) GetLastTransitionTime(obj runtime.Object) string {
+	cond := findOrNotCreateCond(obj, string(c))
+	if cond == nil {
+		return ""
+	}
+	return getOptionalFieldValue(*cond, "LastTransitionTime").String()
+}
+
+// GetObservedGeneration returns the generation of the object the status of the condition was last set for.
+func (c Cond) GetObservedGeneration(obj runtime.Object) int64 {
+	cond := findOrNotCreateCond(obj, string(c))
+	if cond == nil {
+		return 0
+	}
+	value := getOptionalFieldValue(*cond, "ObservedGeneration")
+	if !value.IsValid() {
+		return 0
+	}
+	return value.Int()
+}
+
 func (c Cond) Once(obj runtime.Object, f func() (runtime.Object, error)) (runtime.Object, error) {
 	if c.IsFalse(obj) {
 		return obj, &controller.ForgetError{
@@ -262,7 +290,30 @@ func getTS(obj interface{}, condName string) string {
 
 func setStatus(obj interface{}, condName, status string) {
 	cond := findOrCreateCond(obj, condName)
+	if getFieldValue(cond, "Status").String() != status {
+		touchTransition(cond)
+	}
 	setValue(cond, "Status", status)
+	setObservedGeneration(obj, cond)
+}
+
+// touchTransition sets lastTransitionTime for condition types having the field.
+func touchTransition(cond reflect.Value) {
+	value := getOptionalFieldValue(cond, "LastTransitionTime")
+	if value.IsValid() && value.Kind() == reflect.String {
+		value.SetString(time.Now().Format(time.RFC3339))
+	}
+}
+
+// setObservedGeneration sets observedGeneration for condition types having the field.
+func setObservedGeneration(obj interface{}, cond reflect.Value) {
+	value := getOptionalFieldValue(cond, "ObservedGeneration")
+	if !value.IsValid() || value.Kind() != reflect.Int64 {
+		return
+	}
+	if m, err := meta.Accessor(obj); err == nil {
+		value.SetInt(m.GetGeneration())
+	}
 }
 
 func setValue(cond reflect.Value, fieldName, newValue string) {
@@ -288,6 +339,7 @@ func findOrCreateCond(obj interface{}, condName string) reflect.Value {
 	newCond := reflect.New(condSlice.Type().Elem()).Elem()
 	newCond.FieldByName("Type").SetString(condName)
 	newCond.FieldByName("Status").SetString("Unknown")
+	touchTransition(newCond)
 	condSlice.Set(reflect.Append(condSlice, newCond))
 	return *findCond(condSlice, condName)
 }
@@ -304,6 +356,13 @@ func findCond(val reflect.Value, name string) *reflect.Value {
 	return nil
 }
 
+func getOptionalFieldValue(v reflect.Value, name string) reflect.Value {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v.FieldByName(name)
+}
+
 func getValue(obj interface{}, name ...string) reflect.Value {
 	if obj == nil {
 		return reflect.Value{}
@@ -359,4 +418,6 @@ type GenericCondition struct {
 	Reason string `json:"reason,omitempty"`
 	// Human-readable message indicating details about last transition
 	Message string `json:"message,omitempty"`
+	// The generation of the object the status was set for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
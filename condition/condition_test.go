@@ -1,6 +1,11 @@
 package condition
 
-import "testing"
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
 
 func TestRegexp(t *testing.T) {
 	testInputs := []string{
@@ -17,3 +22,69 @@ func TestRegexp(t *testing.T) {
 		}
 	}
 }
+
+type testStatus struct {
+	Conditions []GenericCondition
+}
+
+type testObject struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+	Status testStatus
+}
+
+func (t *testObject) DeepCopyObject() runtime.Object {
+	obj := *t
+	obj.Status.Conditions = append([]GenericCondition{}, t.Status.Conditions...)
+	return &obj
+}
+
+func TestTransition(t *testing.T) {
+	c := Cond("Provisioned")
+	obj := &testObject{}
+	obj.Generation = 3
+
+	c.True(obj)
+	obj.Status.Conditions[0].LastTransitionTime = "earlier"
+	c.True(obj)
+	if c.GetLastTransitionTime(obj) != "earlier" {
+		t.Fatal("lastTransitionTime changed without a status change")
+	}
+	if c.GetObservedGeneration(obj) != 3 {
+		t.Fatalf("expected observedGeneration 3, got %d", c.GetObservedGeneration(obj))
+	}
+
+	c.False(obj)
+	if c.GetLastTransitionTime(obj) == "earlier" {
+		t.Fatal("lastTransitionTime not updated on status change")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	obj := &testObject{}
+	if !Summary(obj).IsReady() {
+		t.Fatal("expected object without conditions to be ready")
+	}
+
+	Cond("Provisioned").True(obj)
+	Cond("Updated").Unknown(obj)
+	Cond("Agent").False(obj)
+	Cond("Agent").Message(obj, "agent disconnected")
+	Ready.False(obj)
+
+	state := Summary(obj)
+	if state.Status != "False" || state.Reason != "AgentFalse" || state.Message != "Agent: agent disconnected" {
+		t.Fatalf("unexpected summary %#v", state)
+	}
+
+	state.Apply(obj, Ready)
+	if Ready.GetMessage(obj) != state.Message {
+		t.Fatal("summary not applied")
+	}
+}
+
+func TestReasonCode(t *testing.T) {
+	if code := ReasonCode("waiting for nodes-ready"); code != "WaitingForNodesReady" {
+		t.Fatalf("unexpected reason code %s", code)
+	}
+}
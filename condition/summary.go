@@ -0,0 +1,95 @@
+package condition
+
+import (
+	"strings"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Ready is the condition Summary ignores so the rollup can be stored in it.
+const Ready = Cond("Ready")
+
+// State is the overall state of an object rolled up from its conditions.
+type State struct {
+	Status  string
+	Reason  string
+	Message string
+}
+
+// IsReady returns true if every condition is True.
+func (s State) IsReady() bool {
+	return s.Status == "True"
+}
+
+// Apply stores the state in cond of obj.
+func (s State) Apply(obj runtime.Object, cond Cond) {
+	cond.SetStatus(obj, s.Status)
+	cond.Reason(obj, s.Reason)
+	cond.Message(obj, s.Message)
+}
+
+// Summary rolls up the conditions of obj, except Ready, into one state. It is False if any condition is False,
+// else Unknown if any condition is Unknown, else True. Reason and message are taken from the first condition
+// determining the state.
+func Summary(obj runtime.Object) State {
+	state := State{
+		Status: "True",
+	}
+
+	condSlice := getValue(obj, "Status", "Conditions")
+	if !condSlice.IsValid() {
+		return state
+	}
+
+	for i := 0; i < condSlice.Len(); i++ {
+		cond := condSlice.Index(i)
+		condType := getFieldValue(cond, "Type").String()
+		if condType == string(Ready) {
+			continue
+		}
+
+		status := getFieldValue(cond, "Status").String()
+		if status == "" {
+			status = "Unknown"
+		}
+		if status == "True" || status == state.Status || state.Status == "False" {
+			continue
+		}
+
+		reason := getFieldValue(cond, "Reason").String()
+		if reason == "" {
+			reason = ReasonCode(condType + " " + status)
+		}
+		message := getFieldValue(cond, "Message").String()
+		if message != "" {
+			message = condType + ": " + message
+		}
+
+		state = State{
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		}
+	}
+
+	return state
+}
+
+// ReasonCode turns a free form reason like "waiting for nodes" into a CamelCase code like "WaitingForNodes".
+func ReasonCode(reason string) string {
+	buf := strings.Builder{}
+	upper := true
+	for _, r := range reason {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
@@ -0,0 +1,122 @@
+package condition
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func (c Cond) WaitForTrue(ctx context.Context, client ObjectClientGetter, namespace, name string, timeout time.Duration) (runtime.Object, error) {
+	return c.Wait(ctx, client, namespace, name, "True", timeout)
+}
+
+func (c Cond) WaitForFalse(ctx context.Context, client ObjectClientGetter, namespace, name string, timeout time.Duration) (runtime.Object, error) {
+	return c.Wait(ctx, client, namespace, name, "False", timeout)
+}
+
+// The delay before watching the object again after a watch closed doubles from minWatchRetry up to
+// maxWatchRetry, so a watch failing right away is not reopened in a hot loop.
+var (
+	minWatchRetry = 100 * time.Millisecond
+	maxWatchRetry = 5 * time.Second
+)
+
+// getWatcher is the part of the object client used to wait for conditions.
+type getWatcher interface {
+	GetNamespaced(namespace, name string, opts metav1.GetOptions) (runtime.Object, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// Wait watches the object until the condition has status and returns the object in that state. It gives up when
+// ctx is done or, if timeout is not zero, once timeout passed.
+func (c Cond) Wait(ctx context.Context, client ObjectClientGetter, namespace, name, status string, timeout time.Duration) (runtime.Object, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return c.wait(ctx, client.ObjectClient(), namespace, name, status)
+}
+
+func (c Cond) wait(ctx context.Context, objectClient getWatcher, namespace, name, status string) (runtime.Object, error) {
+	selector := fields.Set{
+		"metadata.name": name,
+	}
+	if namespace != "" {
+		selector["metadata.namespace"] = namespace
+	}
+
+	delay := minWatchRetry
+	for {
+		obj, err := objectClient.GetNamespaced(namespace, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if c.GetStatus(obj) == status {
+			return obj, nil
+		}
+
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		w, err := objectClient.Watch(metav1.ListOptions{
+			FieldSelector:   selector.String(),
+			ResourceVersion: objMeta.GetResourceVersion(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		obj, events, done, err := c.waitEvents(ctx, w, namespace, name, status)
+		w.Stop()
+		if done {
+			return obj, err
+		}
+
+		if events > 0 {
+			delay = minWatchRetry
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "waiting for condition %s of %s/%s to be %s", c, namespace, name, status)
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxWatchRetry {
+			delay = maxWatchRetry
+		}
+	}
+}
+
+// waitEvents returns done false if the watch closed before the condition got status, so the object is read and
+// watched again, and how many events were received.
+func (c Cond) waitEvents(ctx context.Context, w watch.Interface, namespace, name, status string) (runtime.Object, int, bool, error) {
+	events := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, events, true, errors.Wrapf(ctx.Err(), "waiting for condition %s of %s/%s to be %s", c, namespace, name, status)
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil, events, false, nil
+			}
+			events++
+			switch event.Type {
+			case watch.Deleted:
+				return nil, events, true, errors.Errorf("%s/%s was deleted while waiting for condition %s to be %s", namespace, name, c, status)
+			case watch.Error:
+				return nil, events, false, nil
+			}
+			if c.GetStatus(event.Object) == status {
+				return event.Object, events, true, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package condition
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// closingClient returns an object without the condition and watches that close right away, unless events has
+// events for the watch.
+type closingClient struct {
+	watches int
+	events  map[int][]watch.Event
+}
+
+func (c *closingClient) GetNamespaced(namespace, name string, opts metav1.GetOptions) (runtime.Object, error) {
+	obj := &testObject{}
+	obj.Name = name
+	obj.Namespace = namespace
+	return obj, nil
+}
+
+func (c *closingClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	c.watches++
+	w := watch.NewFakeWithChanSize(len(c.events[c.watches]), false)
+	for _, event := range c.events[c.watches] {
+		w.Action(event.Type, event.Object)
+	}
+	if len(c.events[c.watches]) == 0 {
+		w.Stop()
+	}
+	return w, nil
+}
+
+func withWatchRetry(min, max time.Duration) func() {
+	oldMin, oldMax := minWatchRetry, maxWatchRetry
+	minWatchRetry, maxWatchRetry = min, max
+	return func() {
+		minWatchRetry, maxWatchRetry = oldMin, oldMax
+	}
+}
+
+func TestWaitBacksOffWhenWatchCloses(t *testing.T) {
+	defer withWatchRetry(10*time.Millisecond, 40*time.Millisecond)()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	client := &closingClient{}
+	if _, err := Cond("Ready").wait(ctx, client, "default", "a", "True"); err == nil {
+		t.Fatal("expected an error once the context is done")
+	}
+	// 10, 20, 40, 40, ... ms between watches, without backoff there would be thousands
+	if client.watches < 3 || client.watches > 12 {
+		t.Fatalf("expected a few watches, got %d", client.watches)
+	}
+}
+
+func TestWaitRetriesClosedWatch(t *testing.T) {
+	defer withWatchRetry(time.Millisecond, time.Millisecond)()
+
+	ready := &testObject{}
+	Cond("Ready").True(ready)
+	client := &closingClient{
+		events: map[int][]watch.Event{
+			3: {{Type: watch.Modified, Object: ready}},
+		},
+	}
+
+	obj, err := Cond("Ready").wait(context.Background(), client, "default", "a", "True")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj != ready {
+		t.Fatalf("expected the object of the watch event, got %#v", obj)
+	}
+	if client.watches != 3 {
+		t.Fatalf("expected 3 watches, got %d", client.watches)
+	}
+}
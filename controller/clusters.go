@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/rest"
+)
+
+// ClientFactory creates the clients of one API group for config, storing them in the returned context.
+type ClientFactory func(context.Context, rest.Config) (context.Context, Starter, error)
+
+// ClusterRegister registers the handlers of a cluster, reading the clients of the cluster from ctx.
+type ClusterRegister func(ctx context.Context) error
+
+type clusterContextKey struct{}
+
+type cluster struct {
+	name     string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	starters []Starter
+	synced   bool
+	err      error
+}
+
+// Clusters runs controllers against several remote clusters from one process. Every cluster gets its own
+// clients, informers and caches, created by the factories from the rest.Config of the cluster.
+type Clusters struct {
+	sync.Mutex
+	factories   []ClientFactory
+	threadiness int
+	clusters    map[string]*cluster
+}
+
+func NewClusters(threadiness int, factories ...ClientFactory) *Clusters {
+	return &Clusters{
+		factories:   factories,
+		threadiness: threadiness,
+		clusters:    map[string]*cluster{},
+	}
+}
+
+// ClusterName returns the name of the cluster whose clients are stored in ctx, empty for the local cluster.
+func ClusterName(ctx context.Context) string {
+	name, _ := ctx.Value(clusterContextKey{}).(string)
+	return name
+}
+
+// Add creates the clients of cluster name, calls the registers to add handlers and starts the controllers in the
+// background. The controllers stop when ctx is done or the cluster is removed.
+func (c *Clusters) Add(ctx context.Context, name string, config rest.Config, registers ...ClusterRegister) error {
+	c.Lock()
+	if _, ok := c.clusters[name]; ok {
+		c.Unlock()
+		return fmt.Errorf("cluster %s is already registered", name)
+	}
+	c.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	ctx = context.WithValue(ctx, clusterContextKey{}, name)
+
+	cl := &cluster{
+		name:   name,
+		cancel: cancel,
+	}
+
+	var err error
+	for _, factory := range c.factories {
+		var starter Starter
+		ctx, starter, err = factory(ctx, config)
+		if err != nil {
+			cancel()
+			return err
+		}
+		cl.starters = append(cl.starters, starter)
+	}
+
+	for _, register := range registers {
+		if err := register(ctx); err != nil {
+			cancel()
+			return err
+		}
+	}
+	cl.ctx = ctx
+
+	c.Lock()
+	if _, ok := c.clusters[name]; ok {
+		c.Unlock()
+		cancel()
+		return fmt.Errorf("cluster %s is already registered", name)
+	}
+	c.clusters[name] = cl
+	c.Unlock()
+
+	go c.start(cl)
+	return nil
+}
+
+func (c *Clusters) start(cl *cluster) {
+	err := SyncThenStart(cl.ctx, c.threadiness, cl.starters...)
+
+	c.Lock()
+	defer c.Unlock()
+	if err != nil {
+		logrus.Errorf("failed to start controllers of cluster %s: %v", cl.name, err)
+		cl.err = err
+		return
+	}
+	cl.synced = true
+}
+
+// Remove stops the controllers of cluster name.
+func (c *Clusters) Remove(name string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if cl, ok := c.clusters[name]; ok {
+		cl.cancel()
+		delete(c.clusters, name)
+	}
+}
+
+// Context returns the context holding the clients of cluster name.
+func (c *Clusters) Context(name string) (context.Context, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	cl, ok := c.clusters[name]
+	if !ok {
+		return nil, false
+	}
+	return cl.ctx, true
+}
+
+// Synced returns whether the caches of cluster name are synced and its controllers started, and the error if
+// starting them failed.
+func (c *Clusters) Synced(name string) (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	cl, ok := c.clusters[name]
+	if !ok {
+		return false, fmt.Errorf("cluster %s is not registered", name)
+	}
+	return cl.synced, cl.err
+}
+
+func (c *Clusters) Names() []string {
+	c.Lock()
+	defer c.Unlock()
+
+	var names []string
+	for name := range c.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
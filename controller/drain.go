@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultDrainTimeout is how long a stopping controller waits for in-flight syncs unless set with WithDrainTimeout.
+var DefaultDrainTimeout = 30 * time.Second
+
+// Drainer is implemented by controllers that finish their in-flight syncs after being stopped.
+type Drainer interface {
+	Drained() <-chan struct{}
+}
+
+// WaitForDrain blocks until every started starter that is a Drainer finished its in-flight syncs after its context
+// was cancelled.
+func WaitForDrain(starters ...Starter) {
+	for _, starter := range starters {
+		if drainer, ok := starter.(Drainer); ok {
+			<-drainer.Drained()
+		}
+	}
+}
+
+// detachedContext keeps the values of a context but is never cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
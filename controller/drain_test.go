@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartAfterStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewGenericController("configmaps-restart-test", configMapBackend{})
+	if !assert.NoError(t, c.Start(ctx, 1)) {
+		cancel()
+		return
+	}
+
+	cancel()
+	select {
+	case <-c.Drained():
+	case <-time.After(5 * time.Second):
+		t.Fatal("controller did not drain")
+	}
+
+	assert.Error(t, c.Start(context.Background(), 1))
+}
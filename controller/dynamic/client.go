@@ -0,0 +1,135 @@
+package dynamic
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rancher/norman/controller"
+	"github.com/rancher/norman/lifecycle"
+	"github.com/rancher/norman/objectclient"
+	objectdynamic "github.com/rancher/norman/objectclient/dynamic"
+	"github.com/rancher/norman/restwatch"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+)
+
+type HandlerFunc func(key string, obj *unstructured.Unstructured) (runtime.Object, error)
+
+// Client runs controllers for types only known at runtime. Objects are handled as unstructured, otherwise the
+// controllers behave like generated ones and share their workqueue and lifecycle handling.
+type Client struct {
+	sync.Mutex
+	restClient  rest.Interface
+	controllers map[string]controller.GenericController
+	starters    []controller.Starter
+	recorder    record.EventRecorder
+}
+
+type contextKeyType struct{}
+
+// Factory creates a client whose controllers report handler panics with the event recorder of ctx, see From.
+func Factory(ctx context.Context, config rest.Config) (context.Context, controller.Starter, error) {
+	c, err := NewForConfig(config)
+	if err != nil {
+		return ctx, nil, err
+	}
+	c.recorder = controller.EventRecorderFrom(ctx)
+	return context.WithValue(ctx, contextKeyType{}, c), c, nil
+}
+
+// From returns the client created by Factory.
+func From(ctx context.Context) *Client {
+	return ctx.Value(contextKeyType{}).(*Client)
+}
+
+func NewForConfig(config rest.Config) (*Client, error) {
+	if config.NegotiatedSerializer == nil {
+		config.NegotiatedSerializer = objectdynamic.NegotiatedSerializer
+	}
+
+	restClient, err := restwatch.UnversionedRESTClientFor(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		restClient:  restClient,
+		controllers: map[string]controller.GenericController{},
+	}, nil
+}
+
+// ObjectClient returns a client for the resource in namespace, or all namespaces if empty.
+func (c *Client) ObjectClient(gvk schema.GroupVersionKind, resource metav1.APIResource, namespace string) *objectclient.ObjectClient {
+	return objectclient.NewObjectClient(namespace, c.restClient, &resource, gvk, &objectclient.UnstructuredObjectFactory{})
+}
+
+// Controller returns the controller of the resource in namespace, creating it on first use.
+func (c *Client) Controller(gvk schema.GroupVersionKind, resource metav1.APIResource, namespace string) controller.GenericController {
+	c.Lock()
+	defer c.Unlock()
+
+	key := gvk.String() + "/" + namespace
+	if genericController, ok := c.controllers[key]; ok {
+		return genericController
+	}
+
+	genericController := controller.NewGenericController(gvk.Kind+"Controller", c.ObjectClient(gvk, resource, namespace),
+		controller.WithRecorder(c.recorder))
+	c.controllers[key] = genericController
+	c.starters = append(c.starters, genericController)
+	return genericController
+}
+
+func (c *Client) AddHandler(ctx context.Context, gvk schema.GroupVersionKind, resource metav1.APIResource, name string, handler HandlerFunc) {
+	c.Controller(gvk, resource, "").AddHandler(ctx, name, func(key string, obj interface{}) (interface{}, error) {
+		if obj == nil {
+			return handler(key, nil)
+		} else if v, ok := obj.(*unstructured.Unstructured); ok {
+			return handler(key, v)
+		}
+		return nil, nil
+	})
+}
+
+func (c *Client) AddLifecycle(ctx context.Context, gvk schema.GroupVersionKind, resource metav1.APIResource, name string, l lifecycle.ObjectLifecycle) {
+	sync := lifecycle.NewObjectLifecycleAdapter(name, false, l, c.ObjectClient(gvk, resource, ""))
+	c.Controller(gvk, resource, "").AddHandler(ctx, name, sync)
+}
+
+func (c *Client) Sync(ctx context.Context) error {
+	c.Lock()
+	starters := append([]controller.Starter{}, c.starters...)
+	c.Unlock()
+	return controller.Sync(ctx, starters...)
+}
+
+func (c *Client) Start(ctx context.Context, threadiness int) error {
+	c.Lock()
+	starters := append([]controller.Starter{}, c.starters...)
+	c.Unlock()
+	return controller.Start(ctx, threadiness, starters...)
+}
+
+func (c *Client) WaitForInitialPass(ctx context.Context) error {
+	c.Lock()
+	starters := append([]controller.Starter{}, c.starters...)
+	c.Unlock()
+	return controller.WaitForInitialPass(ctx, starters...)
+}
+
+func (c *Client) Drained() <-chan struct{} {
+	c.Lock()
+	starters := append([]controller.Starter{}, c.starters...)
+	c.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		controller.WaitForDrain(starters...)
+		close(done)
+	}()
+	return done
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type eventRecorderKey struct{}
+
+// NewEventRecorder returns a recorder sending events for component through client. Objects must be registered in
+// objectScheme, or have their kind set, to be referenced by events. The client-go scheme is used if nil.
+func NewEventRecorder(client kubernetes.Interface, component string, objectScheme *runtime.Scheme) record.EventRecorder {
+	if objectScheme == nil {
+		objectScheme = scheme.Scheme
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(logrus.Debugf)
+	broadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return broadcaster.NewRecorder(objectScheme, v1.EventSource{Component: component})
+}
+
+func WithEventRecorder(ctx context.Context, recorder record.EventRecorder) context.Context {
+	return context.WithValue(ctx, eventRecorderKey{}, recorder)
+}
+
+// EventRecorderFrom returns the recorder stored in ctx, or one dropping all events if there is none.
+func EventRecorderFrom(ctx context.Context) record.EventRecorder {
+	if recorder, ok := ctx.Value(eventRecorderKey{}).(record.EventRecorder); ok {
+		return recorder
+	}
+	// Without a channel the fake recorder drops all events
+	return &record.FakeRecorder{}
+}
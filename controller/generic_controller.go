@@ -5,23 +5,27 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	errors2 "github.com/pkg/errors"
 	"github.com/rancher/norman/metrics"
 	"github.com/rancher/norman/objectclient"
+	"github.com/rancher/norman/signal"
 	"github.com/rancher/norman/types"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 )
 
@@ -30,12 +34,17 @@ const MetricsReflectorEnv = "NORMAN_REFLECTOR_METRICS"
 
 var (
 	resyncPeriod = 2 * time.Hour
+	// queueMetrics is the workqueue metrics provider in use, for the queues not created by the workqueue package
+	queueMetrics workqueue.MetricsProvider = noopWorkqueueMetricsProvider{}
 )
 
 // Override the metrics providers
 func init() {
 	if os.Getenv(MetricsQueueEnv) != "true" {
 		DisableControllerWorkqueuMetrics()
+	} else {
+		queueMetrics = metrics.WorkqueueMetricsProvider{}
+		workqueue.SetProvider(queueMetrics)
 	}
 	if os.Getenv(MetricsReflectorEnv) != "true" {
 		DisableControllerReflectorMetrics()
@@ -44,14 +53,28 @@ func init() {
 
 type HandlerFunc func(key string, obj interface{}) (interface{}, error)
 
+// ContextHandlerFunc is a HandlerFunc getting a context that is cancelled when the controller stops or the
+// HandlerTimeout of the handler passed.
+type ContextHandlerFunc func(ctx context.Context, key string, obj interface{}) (interface{}, error)
+
 type GenericController interface {
 	SetThreadinessOverride(count int)
+	SetMaxInFlightPerKey(count int)
+	SetRateLimiter(rateLimiter workqueue.RateLimiter)
+	SetEventRecorder(recorder record.EventRecorder)
+	SetCoalescingWindow(window time.Duration)
 	Informer() cache.SharedIndexInformer
-	AddHandler(ctx context.Context, name string, handler HandlerFunc)
+	AddHandler(ctx context.Context, name string, handler HandlerFunc, opts ...HandlerOption)
+	AddContextHandler(ctx context.Context, name string, handler ContextHandlerFunc, opts ...HandlerOption)
 	HandlerCount() int
 	Enqueue(namespace, name string)
+	EnqueueAfter(namespace, name string, after time.Duration)
 	Sync(ctx context.Context) error
 	Start(ctx context.Context, threadiness int) error
+	WaitForInitialPass(ctx context.Context) error
+	Drained() <-chan struct{}
+	Suspend()
+	Resume() error
 }
 
 type Backend interface {
@@ -61,9 +84,13 @@ type Backend interface {
 }
 
 type handlerDef struct {
-	name       string
-	generation int
-	handler    HandlerFunc
+	name        string
+	generation  int
+	handler     ContextHandlerFunc
+	workers     chan struct{}
+	rateLimiter workqueue.RateLimiter
+	timeout     time.Duration
+	resync      *time.Duration
 }
 
 type generationKey struct {
@@ -71,36 +98,103 @@ type generationKey struct {
 	key        string
 }
 
+// resyncKey is queued for periodic resyncs of the informer and only runs the handlers without their own resync
+// period.
+type resyncKey struct {
+	key string
+}
+
+// handlerKey runs only one handler, queued for handlers with their own resync period.
+type handlerKey struct {
+	handler *handlerDef
+	key     string
+}
+
 type genericController struct {
 	sync.Mutex
 	threadinessOverride int
 	generation          int
 	informer            cache.SharedIndexInformer
+	newInformer         func() cache.SharedIndexInformer
+	registered          registrations
+	stopInformer        context.CancelFunc
+	syncCtx             context.Context
+	idleStop            bool
+	suspended           bool
+	resuming            bool
 	handlers            []*handlerDef
 	queue               workqueue.RateLimitingInterface
+	rateLimiter         *swappableRateLimiter
+	recorder            record.EventRecorder
+	processing          int32
+	stopping            int32
+	drainTimeout        time.Duration
+	drained             chan struct{}
+	coalescingWindow    int64
+	keys                *keyLimiter
+	delayedLock         sync.Mutex
+	delayed             map[interface{}]bool
+	ctx                 context.Context
 	name                string
+	id                  string
 	running             bool
+	stopped             bool
 	synced              bool
 }
 
-func NewGenericController(name string, genericClient Backend) GenericController {
-	informer := cache.NewSharedIndexInformer(
-		&cache.ListWatch{
-			ListFunc:  genericClient.List,
-			WatchFunc: genericClient.Watch,
-		},
-		genericClient.ObjectFactory().Object(), resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+func NewGenericController(name string, genericClient Backend, opts ...Option) GenericController {
+	config := Options{
+		Transform:    DefaultTransform,
+		DrainTimeout: DefaultDrainTimeout,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
 
-	rl := workqueue.NewMaxOfRateLimiter(
-		workqueue.NewItemExponentialFailureRateLimiter(500*time.Millisecond, 1000*time.Second),
-		// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
-		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
-	)
+	newInformer := func() cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+					list, err := genericClient.List(config.apply(opts))
+					if err != nil {
+						return nil, err
+					}
+					return transformList(config.Transform, list)
+				},
+				WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+					w, err := genericClient.Watch(config.apply(opts))
+					if err != nil {
+						return nil, err
+					}
+					return transformWatch(config.Transform, w), nil
+				},
+			},
+			genericClient.ObjectFactory().Object(), resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+
+	rl := &swappableRateLimiter{
+		rateLimiter: NewRateLimiter(DefaultRateLimit),
+	}
+
+	var queue workqueue.RateLimitingInterface
+	if config.PriorityQueue {
+		queue = newPriorityQueue(name, rl, isBulkItem)
+	} else {
+		queue = workqueue.NewNamedRateLimitingQueue(rl, name)
+	}
 
 	return &genericController{
-		informer: informer,
-		queue:    workqueue.NewNamedRateLimitingQueue(rl, name),
-		name:     name,
+		informer:     newInformer(),
+		newInformer:  newInformer,
+		idleStop:     config.IdleStop,
+		queue:        queue,
+		rateLimiter:  rl,
+		recorder:     config.Recorder,
+		keys:         newKeyLimiter(),
+		drainTimeout: config.DrainTimeout,
+		drained:      make(chan struct{}),
+		name:         name,
+		id:           newControllerID(name),
 	}
 }
 
@@ -108,12 +202,36 @@ func (g *genericController) SetThreadinessOverride(count int) {
 	g.threadinessOverride = count
 }
 
+// SetMaxInFlightPerKey limits how many workers may process the same object at once, zero meaning no limit.
+func (g *genericController) SetMaxInFlightPerKey(count int) {
+	g.keys.setMax(count)
+}
+
+// SetRateLimiter replaces the rate limiter used to retry failed objects, see NewRateLimiter.
+func (g *genericController) SetRateLimiter(rateLimiter workqueue.RateLimiter) {
+	g.rateLimiter.set(rateLimiter)
+}
+
+// SetEventRecorder sets the recorder used to report handler panics as events on the object.
+func (g *genericController) SetEventRecorder(recorder record.EventRecorder) {
+	g.Lock()
+	defer g.Unlock()
+	g.recorder = recorder
+}
+
+// SetCoalescingWindow delays handling changes of an object by window so a burst of updates causes a single sync.
+func (g *genericController) SetCoalescingWindow(window time.Duration) {
+	atomic.StoreInt64(&g.coalescingWindow, int64(window))
+}
+
 func (g *genericController) HandlerCount() int {
+	g.Lock()
+	defer g.Unlock()
 	return len(g.handlers)
 }
 
 func (g *genericController) Informer() cache.SharedIndexInformer {
-	return g.informer
+	return &controllerInformer{g: g}
 }
 
 func (g *genericController) Enqueue(namespace, name string) {
@@ -124,30 +242,86 @@ func (g *genericController) Enqueue(namespace, name string) {
 	}
 }
 
-func (g *genericController) AddHandler(ctx context.Context, name string, handler HandlerFunc) {
+// EnqueueAfter queues the object again once after has passed, for example to check it again when a certificate is
+// about to expire.
+func (g *genericController) EnqueueAfter(namespace, name string, after time.Duration) {
+	if namespace == "" {
+		g.queue.AddAfter(name, after)
+	} else {
+		g.queue.AddAfter(namespace+"/"+name, after)
+	}
+}
+
+func (g *genericController) AddHandler(ctx context.Context, name string, handler HandlerFunc, opts ...HandlerOption) {
+	g.AddContextHandler(ctx, name, func(_ context.Context, key string, obj interface{}) (interface{}, error) {
+		return handler(key, obj)
+	}, opts...)
+}
+
+func (g *genericController) AddContextHandler(ctx context.Context, name string, handler ContextHandlerFunc, opts ...HandlerOption) {
 	g.Lock()
 	h := &handlerDef{
 		name:       name,
 		generation: g.generation,
 		handler:    handler,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
 	g.handlers = append(g.handlers, h)
+	resume := g.suspended && g.idleStop
 	g.Unlock()
 
+	if resume {
+		go func() {
+			if err := g.Resume(); err != nil {
+				logrus.Errorf("Failed to resume %s controller: %v", g.name, err)
+			}
+		}()
+	}
+
+	if h.resync != nil && *h.resync > 0 {
+		go g.resyncHandler(ctx, h)
+	}
+
 	go func() {
 		<-ctx.Done()
 		g.Lock()
 		var handlers []*handlerDef
 		for _, handler := range g.handlers {
 			if handler != h {
-				handlers = append(handlers, h)
+				handlers = append(handlers, handler)
 			}
 		}
 		g.handlers = handlers
+		idle := g.idleStop && len(handlers) == 0
 		g.Unlock()
+
+		if idle {
+			g.Suspend()
+		}
 	}()
 }
 
+func (g *genericController) resyncHandler(ctx context.Context, h *handlerDef) {
+	ticker := time.NewTicker(*h.resync)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, key := range g.Informer().GetStore().ListKeys() {
+				g.queue.Add(handlerKey{
+					handler: h,
+					key:     key,
+				})
+			}
+		}
+	}
+}
+
 func (g *genericController) Sync(ctx context.Context) error {
 	g.Lock()
 	defer g.Unlock()
@@ -160,11 +334,27 @@ func (g *genericController) sync(ctx context.Context) error {
 		return nil
 	}
 
+	informer := g.startInformer(ctx)
+	if err := waitForCacheSync(ctx, g.name, informer); err != nil {
+		return err
+	}
+	g.setSynced()
+	return nil
+}
+
+// startInformer runs the informer of the controller without waiting for its cache, the caller must hold the lock.
+func (g *genericController) startInformer(ctx context.Context) cache.SharedIndexInformer {
 	defer utilruntime.HandleCrash()
 
 	g.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: g.queueObject,
-		UpdateFunc: func(_, obj interface{}) {
+		UpdateFunc: func(old, obj interface{}) {
+			if isResync(old, obj) {
+				if key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
+					g.queue.Add(resyncKey{key: key})
+				}
+				return
+			}
 			g.queueObject(obj)
 		},
 		DeleteFunc: g.queueObject,
@@ -172,21 +362,44 @@ func (g *genericController) sync(ctx context.Context) error {
 
 	logrus.Debugf("Syncing %s Controller", g.name)
 
-	go g.informer.Run(ctx.Done())
+	informerCtx, cancel := context.WithCancel(ctx)
+	g.syncCtx = ctx
+	g.stopInformer = cancel
+	go g.informer.Run(informerCtx.Done())
+	if !g.suspended {
+		// Resuming reuses ctx, so only the first sync waits for it to report the controller stopped
+		go func() {
+			<-ctx.Done()
+			health.remove(g.id)
+		}()
+	}
+
+	return g.informer
+}
 
-	if !cache.WaitForCacheSync(ctx.Done(), g.informer.HasSynced) {
-		return fmt.Errorf("failed to sync controller %s", g.name)
+func waitForCacheSync(ctx context.Context, name string, informer cache.SharedIndexInformer) error {
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to sync controller %s", name)
 	}
-	logrus.Debugf("Syncing %s Controller Done", g.name)
+	logrus.Debugf("Syncing %s Controller Done", name)
+	return nil
+}
 
+// setSynced marks the cache of the controller synced, the caller must hold the lock.
+func (g *genericController) setSynced() {
+	health.synced(g.id, g.name)
 	g.synced = true
-	return nil
 }
 
+// Start syncs and runs the controller until ctx is done. The queue is shut down when the controller stops, so a
+// stopped controller can not be started again.
 func (g *genericController) Start(ctx context.Context, threadiness int) error {
 	g.Lock()
 	defer g.Unlock()
 
+	if g.stopped {
+		return fmt.Errorf("%s controller was stopped and can not be started again", g.name)
+	}
 	if err := g.sync(ctx); err != nil {
 		return err
 	}
@@ -195,7 +408,16 @@ func (g *genericController) Start(ctx context.Context, threadiness int) error {
 		if g.threadinessOverride > 0 {
 			threadiness = g.threadinessOverride
 		}
-		go g.run(ctx, threadiness)
+		// Handlers get a context that is only cancelled once the controller drained so stopping does not
+		// interrupt a sync in the middle of writing the object
+		handlerCtx, cancel := context.WithCancel(detachedContext{ctx})
+		g.ctx = handlerCtx
+		go g.run(ctx, threadiness, cancel)
+
+		drained := g.drained
+		signal.OnShutdown(ctx, g.name+" controller", func() {
+			<-drained
+		})
 	}
 
 	if g.running {
@@ -218,6 +440,53 @@ func (g *genericController) Start(ctx context.Context, threadiness int) error {
 	return nil
 }
 
+func isResync(old, obj interface{}) bool {
+	oldMeta, err := meta.Accessor(old)
+	if err != nil {
+		return false
+	}
+	newMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	return oldMeta.GetResourceVersion() == newMeta.GetResourceVersion()
+}
+
+// WaitForInitialPass blocks until the controller is started and its queue was empty and idle once, meaning the
+// objects listed at start were processed. Objects held back by the coalescing window count as queued, retries and
+// objects queued with EnqueueAfter don't.
+func (g *genericController) WaitForInitialPass(ctx context.Context) error {
+	return wait.PollUntil(100*time.Millisecond, func() (bool, error) {
+		g.Lock()
+		running := g.running
+		g.Unlock()
+		return running && g.queue.Len() == 0 && g.delayedLen() == 0 && atomic.LoadInt32(&g.processing) == 0, nil
+	}, ctx.Done())
+}
+
+// addDelayed queues key once window passed, counting it as pending until a worker picks it up.
+func (g *genericController) addDelayed(key interface{}, window time.Duration) {
+	g.delayedLock.Lock()
+	if g.delayed == nil {
+		g.delayed = map[interface{}]bool{}
+	}
+	g.delayed[key] = true
+	g.delayedLock.Unlock()
+	g.queue.AddAfter(key, window)
+}
+
+func (g *genericController) removeDelayed(key interface{}) {
+	g.delayedLock.Lock()
+	delete(g.delayed, key)
+	g.delayedLock.Unlock()
+}
+
+func (g *genericController) delayedLen() int {
+	g.delayedLock.Lock()
+	defer g.delayedLock.Unlock()
+	return len(g.delayed)
+}
+
 func (g *genericController) queueObject(obj interface{}) {
 	if _, ok := obj.(generationKey); ok {
 		g.queue.Add(obj)
@@ -225,14 +494,36 @@ func (g *genericController) queueObject(obj interface{}) {
 	}
 
 	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
-	if err == nil {
+	if err != nil {
+		return
+	}
+
+	// The delaying queue keeps only the earliest pending add of a key, so all changes within the window are
+	// handled by one sync
+	if window := time.Duration(atomic.LoadInt64(&g.coalescingWindow)); window > 0 {
+		g.addDelayed(key, window)
+	} else {
 		g.queue.Add(key)
 	}
 }
 
-func (g *genericController) run(ctx context.Context, threadiness int) {
+// Drained is closed once a started controller stopped and its in-flight syncs finished or were abandoned after the
+// drain timeout. For a controller that was never started it is closed right away.
+func (g *genericController) Drained() <-chan struct{} {
+	g.Lock()
+	defer g.Unlock()
+	if !g.running {
+		closed := make(chan struct{})
+		close(closed)
+		return closed
+	}
+	return g.drained
+}
+
+func (g *genericController) run(ctx context.Context, threadiness int, cancel context.CancelFunc) {
 	defer utilruntime.HandleCrash()
-	defer g.queue.ShutDown()
+	defer close(g.drained)
+	defer cancel()
 
 	for i := 0; i < threadiness; i++ {
 		go wait.Until(g.runWorker, time.Second, ctx.Done())
@@ -240,6 +531,24 @@ func (g *genericController) run(ctx context.Context, threadiness int) {
 
 	<-ctx.Done()
 	logrus.Infof("Shutting down %s controller", g.name)
+
+	atomic.StoreInt32(&g.stopping, 1)
+	g.Lock()
+	g.stopped = true
+	g.Unlock()
+	g.queue.ShutDown()
+	g.drain()
+}
+
+// drain waits for the syncs in progress to finish, at most the drain timeout.
+func (g *genericController) drain() {
+	err := wait.PollImmediate(50*time.Millisecond, g.drainTimeout, func() (bool, error) {
+		return atomic.LoadInt32(&g.processing) == 0, nil
+	})
+	if err != nil {
+		logrus.Warnf("Abandoning %d in-flight syncs of %s controller after %v", atomic.LoadInt32(&g.processing), g.name,
+			g.drainTimeout)
+	}
 }
 
 func (g *genericController) runWorker() {
@@ -252,10 +561,19 @@ func (g *genericController) processNextWorkItem() bool {
 	if quit {
 		return false
 	}
+	atomic.AddInt32(&g.processing, 1)
+	g.removeDelayed(key)
+	defer atomic.AddInt32(&g.processing, -1)
 	defer g.queue.Done(key)
 
+	// The queue hands out the remaining keys after shut down, they are dropped with the stopped controller
+	if atomic.LoadInt32(&g.stopping) == 1 {
+		return false
+	}
+
 	// do your work on the key.  This method will contains your "do stuff" logic
 	err := g.syncHandler(key)
+	health.result(g.id, g.name, filterConflictsError(err))
 	checkErr := err
 	if handlerErr, ok := checkErr.(*handlerError); ok {
 		checkErr = handlerErr.err
@@ -273,7 +591,10 @@ func (g *genericController) processNextWorkItem() bool {
 	}
 
 	if gk, ok := key.(generationKey); ok {
-		g.queue.AddRateLimited(gk.key)
+		key = gk.key
+	}
+	if delay, ok := handlerDelay(err); ok {
+		g.queue.AddAfter(key, delay)
 	} else {
 		g.queue.AddRateLimited(key)
 	}
@@ -281,6 +602,29 @@ func (g *genericController) processNextWorkItem() bool {
 	return true
 }
 
+// handlerDelay returns the longest retry delay of the failed handlers that have their own rate limiter.
+func handlerDelay(err error) (time.Duration, bool) {
+	var (
+		delay time.Duration
+		found bool
+	)
+
+	errs := []error{err}
+	if multi, ok := err.(*types.MultiErrors); ok {
+		errs = multi.Errors
+	}
+	for _, err := range errs {
+		if handlerErr, ok := err.(*handlerError); ok && handlerErr.limited {
+			found = true
+			if handlerErr.delay > delay {
+				delay = handlerErr.delay
+			}
+		}
+	}
+
+	return delay, found
+}
+
 func ignoreError(err error, checkString bool) bool {
 	err = errors2.Cause(err)
 	if errors.IsConflict(err) {
@@ -317,8 +661,12 @@ func (g *genericController) syncHandler(key interface{}) (err error) {
 	defer utilruntime.RecoverFromPanic(&err)
 
 	generation := -1
-	var s string
-	var obj interface{}
+	var (
+		s      string
+		obj    interface{}
+		only   *handlerDef
+		resync bool
+	)
 
 	switch v := key.(type) {
 	case string:
@@ -326,11 +674,29 @@ func (g *genericController) syncHandler(key interface{}) (err error) {
 	case generationKey:
 		generation = v.generation
 		s = v.key
+	case resyncKey:
+		resync = true
+		s = v.key
+	case handlerKey:
+		only = v.handler
+		s = v.key
 	default:
 		return nil
 	}
 
-	obj, exists, err := g.informer.GetStore().GetByKey(s)
+	g.keys.acquire(s)
+	defer g.keys.release(s)
+
+	g.Lock()
+	informer, suspended := g.informer, g.suspended
+	g.Unlock()
+
+	// Keys left in the queue are queued again when the resumed informer lists the objects
+	if suspended {
+		return nil
+	}
+
+	obj, exists, err := informer.GetStore().GetByKey(s)
 	if err != nil {
 		return err
 	} else if !exists {
@@ -342,28 +708,100 @@ func (g *genericController) syncHandler(key interface{}) (err error) {
 		if generation > -1 && handler.generation != generation {
 			continue
 		}
+		if only != nil && handler != only {
+			continue
+		}
+		if resync && handler.resync != nil {
+			continue
+		}
 
 		logrus.Debugf("%s calling handler %s %s", g.name, handler.name, s)
 		metrics.IncTotalHandlerExecution(g.name, handler.name)
-		if newObj, err := handler.handler(s, obj); err != nil {
+		start := time.Now()
+		newObj, err := g.runHandler(handler, s, obj)
+		metrics.ObserveHandlerExecution(g.name, handler.name, start)
+		if err != nil {
 			if !ignoreError(err, false) {
 				metrics.IncTotalHandlerFailure(g.name, handler.name, s)
 			}
-			errs = append(errs, &handlerError{
+			handlerErr := &handlerError{
 				name: handler.name,
 				err:  err,
-			})
-		} else if newObj != nil && !reflect.ValueOf(newObj).IsNil() {
-			obj = newObj
+			}
+			if handler.rateLimiter != nil {
+				handlerErr.limited = true
+				handlerErr.delay = handler.rateLimiter.When(s)
+			}
+			errs = append(errs, handlerErr)
+		} else {
+			if handler.rateLimiter != nil {
+				handler.rateLimiter.Forget(s)
+			}
+			if newObj != nil && !reflect.ValueOf(newObj).IsNil() {
+				obj = newObj
+			}
 		}
 	}
 	err = types.NewErrors(errs...)
 	return
 }
 
+// runHandler runs one handler, turning a panic into an error so the object is retried with backoff instead of the
+// process crashing.
+func (g *genericController) runHandler(handler *handlerDef, key string, obj interface{}) (newObj interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			logrus.Errorf("%s handler %s panicked on %s: %v\n%s", g.name, handler.name, key, r, stack)
+			metrics.IncTotalHandlerPanic(g.name, handler.name)
+			g.recordPanic(obj, handler.name, r)
+			newObj = nil
+			err = fmt.Errorf("handler %s panicked: %v", handler.name, r)
+		}
+	}()
+
+	return handler.run(g.ctx, key, obj)
+}
+
+func (g *genericController) recordPanic(obj interface{}, handlerName string, r interface{}) {
+	g.Lock()
+	recorder := g.recorder
+	g.Unlock()
+
+	if recorder == nil {
+		return
+	}
+	if runtimeObj, ok := obj.(runtime.Object); ok && obj != nil && !reflect.ValueOf(obj).IsNil() {
+		recorder.Eventf(runtimeObj, "Warning", "HandlerPanic", "handler %s panicked: %v", handlerName, r)
+	}
+}
+
+func (h *handlerDef) run(ctx context.Context, key string, obj interface{}) (interface{}, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	if h.workers != nil {
+		select {
+		case h.workers <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		defer func() { <-h.workers }()
+	}
+	return h.handler(ctx, key, obj)
+}
+
 type handlerError struct {
-	name string
-	err  error
+	name    string
+	err     error
+	limited bool
+	delay   time.Duration
 }
 
 func (h *handlerError) Error() string {
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// InitialPassWaiter is implemented by starters that can tell when the objects queued at start were processed.
+type InitialPassWaiter interface {
+	WaitForInitialPass(ctx context.Context) error
+}
+
+// WaitForInitialPass blocks until every starter that is an InitialPassWaiter processed its initial objects.
+func WaitForInitialPass(ctx context.Context, starters ...Starter) error {
+	for _, starter := range starters {
+		if waiter, ok := starter.(InitialPassWaiter); ok {
+			if err := waiter.WaitForInitialPass(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type startNode struct {
+	starter   Starter
+	dependsOn []string
+	done      chan struct{}
+}
+
+// StartGraph starts controllers in dependency order. A starter is only synced and started once all starters it
+// depends on have synced their caches, started and, if they implement InitialPassWaiter, processed their initial
+// objects. Starters without dependencies between them start in parallel.
+type StartGraph struct {
+	nodes map[string]*startNode
+}
+
+func NewStartGraph() *StartGraph {
+	return &StartGraph{
+		nodes: map[string]*startNode{},
+	}
+}
+
+// Add registers starter under name, to be started after the starters named in dependsOn.
+func (s *StartGraph) Add(name string, starter Starter, dependsOn ...string) {
+	s.nodes[name] = &startNode{
+		starter:   starter,
+		dependsOn: dependsOn,
+	}
+}
+
+func (s *StartGraph) validate() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		node, ok := s.nodes[name]
+		if !ok {
+			return fmt.Errorf("controller %s depends on unknown controller %s", path[len(path)-1], name)
+		}
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("controller dependency cycle: %v", append(path, name))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, dep := range node.dependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	var names []string
+	for name := range s.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start syncs and starts all starters, returning once all of them are started or one failed.
+func (s *StartGraph) Start(ctx context.Context, threadiness int) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	for _, node := range s.nodes {
+		node.done = make(chan struct{})
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	for name, node := range s.nodes {
+		name, node := name, node
+		eg.Go(func() error {
+			for _, dep := range node.dependsOn {
+				select {
+				case <-s.nodes[dep].done:
+				case <-egCtx.Done():
+					return egCtx.Err()
+				}
+			}
+
+			if err := node.starter.Sync(ctx); err != nil {
+				return fmt.Errorf("failed to sync controller %s: %v", name, err)
+			}
+			if err := node.starter.Start(ctx, threadiness); err != nil {
+				return fmt.Errorf("failed to start controller %s: %v", name, err)
+			}
+			if waiter, ok := node.starter.(InitialPassWaiter); ok {
+				if err := waiter.WaitForInitialPass(egCtx); err != nil {
+					return err
+				}
+			}
+
+			close(node.done)
+			return nil
+		})
+	}
+
+	return eg.Wait()
+}
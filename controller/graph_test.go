@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderStarter struct {
+	name  string
+	lock  *sync.Mutex
+	order *[]string
+}
+
+func (o *orderStarter) Sync(ctx context.Context) error {
+	return nil
+}
+
+func (o *orderStarter) Start(ctx context.Context, threadiness int) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	*o.order = append(*o.order, o.name)
+	return nil
+}
+
+func TestStartGraphOrder(t *testing.T) {
+	var (
+		lock  sync.Mutex
+		order []string
+	)
+	starter := func(name string) Starter {
+		return &orderStarter{name: name, lock: &lock, order: &order}
+	}
+
+	g := NewStartGraph()
+	g.Add("c", starter("c"), "b")
+	g.Add("b", starter("b"), "a")
+	g.Add("a", starter("a"))
+
+	assert.NoError(t, g.Start(context.Background(), 1))
+	assert.Equal(t, []string{"a", "b", "c"}, order)
+}
+
+func TestStartGraphCycle(t *testing.T) {
+	g := NewStartGraph()
+	g.Add("a", &orderStarter{}, "b")
+	g.Add("b", &orderStarter{}, "a")
+	assert.Error(t, g.Start(context.Background(), 1))
+
+	g = NewStartGraph()
+	g.Add("a", &orderStarter{}, "missing")
+	assert.Error(t, g.Start(context.Background(), 1))
+}
+
+type waitingStarter struct {
+	orderStarter
+	waited bool
+}
+
+func (w *waitingStarter) WaitForInitialPass(ctx context.Context) error {
+	w.waited = true
+	return nil
+}
+
+func TestWaitForInitialPass(t *testing.T) {
+	waiter := &waitingStarter{}
+	assert.NoError(t, WaitForInitialPass(context.Background(), &orderStarter{}, waiter))
+	assert.True(t, waiter.waited)
+
+	var _ InitialPassWaiter = NewGenericController("test", configMapBackend{})
+}
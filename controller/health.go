@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// controllerIDs numbers the controllers, so controllers with the same name, like those of several clusters, are
+// reported separately.
+var controllerIDs int64
+
+func newControllerID(name string) string {
+	return fmt.Sprintf("%s-%d", name, atomic.AddInt64(&controllerIDs, 1))
+}
+
+// Health is the state a controller reports to the health registry.
+type Health struct {
+	ID                string    `json:"id"`
+	Name              string    `json:"name"`
+	Synced            bool      `json:"synced"`
+	Suspended         bool      `json:"suspended"`
+	LastSuccess       time.Time `json:"lastSuccess,omitempty"`
+	ConsecutiveErrors int       `json:"consecutiveErrors"`
+}
+
+type healthRegistry struct {
+	sync.Mutex
+	controllers map[string]*Health
+}
+
+var health = &healthRegistry{
+	controllers: map[string]*Health{},
+}
+
+func (h *healthRegistry) update(id, name string, f func(*Health)) {
+	h.Lock()
+	defer h.Unlock()
+
+	state, ok := h.controllers[id]
+	if !ok {
+		state = &Health{ID: id, Name: name}
+		h.controllers[id] = state
+	}
+	f(state)
+}
+
+func (h *healthRegistry) synced(id, name string) {
+	h.update(id, name, func(state *Health) {
+		state.Synced = true
+		state.Suspended = false
+	})
+}
+
+func (h *healthRegistry) suspended(id, name string) {
+	h.update(id, name, func(state *Health) {
+		state.Synced = false
+		state.Suspended = true
+	})
+}
+
+// remove forgets a stopped controller.
+func (h *healthRegistry) remove(id string) {
+	h.Lock()
+	defer h.Unlock()
+	delete(h.controllers, id)
+}
+
+func (h *healthRegistry) result(id, name string, err error) {
+	h.update(id, name, func(state *Health) {
+		if err == nil {
+			state.LastSuccess = time.Now()
+			state.ConsecutiveErrors = 0
+		} else {
+			state.ConsecutiveErrors++
+		}
+	})
+}
+
+// HealthStatus returns the state of all synced or suspended controllers that weren't stopped, sorted by name.
+func HealthStatus() []Health {
+	health.Lock()
+	defer health.Unlock()
+
+	result := make([]Health, 0, len(health.controllers))
+	for _, state := range health.controllers {
+		result = append(result, *state)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// HealthHandler serves the state of all controllers as JSON. It responds with 503 if a controller that isn't
+// suspended hasn't synced its cache or failed maxConsecutiveErrors times in a row, zero disabling the error check.
+func HealthHandler(maxConsecutiveErrors int) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		controllers := HealthStatus()
+		healthy := true
+		for _, state := range controllers {
+			if state.Suspended {
+				continue
+			}
+			if !state.Synced || (maxConsecutiveErrors > 0 && state.ConsecutiveErrors >= maxConsecutiveErrors) {
+				healthy = false
+			}
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		if !healthy {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"healthy":     healthy,
+			"controllers": controllers,
+		})
+	})
+}
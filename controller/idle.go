@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Suspend stops the informer of a synced controller, closing its watch and dropping its cache. While suspended
+// queued keys are skipped and listers see an empty cache. Resume starts a new informer, which queues every object
+// again. Informers can not be run twice, so the new informer gets the indexers and event handlers added through
+// Informer() registered again.
+func (g *genericController) Suspend() {
+	g.Lock()
+	defer g.Unlock()
+
+	if !g.synced || g.suspended {
+		return
+	}
+
+	logrus.Infof("Suspending %s controller", g.name)
+	g.stopInformer()
+	g.informer = g.newInformer()
+	if err := g.registered.apply(g.informer); err != nil {
+		logrus.Errorf("Failed to register indexers of %s controller: %v", g.name, err)
+	}
+	g.synced = false
+	g.suspended = true
+	health.suspended(g.id, g.name)
+}
+
+// Resume starts the informer of a suspended controller again and waits for its cache to sync. The lock is not held
+// while waiting, so the workers keep handling queued objects. Calls while another one is waiting return right away.
+func (g *genericController) Resume() error {
+	g.Lock()
+	if !g.suspended || g.resuming {
+		g.Unlock()
+		return nil
+	}
+
+	logrus.Infof("Resuming %s controller", g.name)
+	g.resuming = true
+	ctx := g.syncCtx
+	informer := g.startInformer(ctx)
+	g.Unlock()
+
+	err := waitForCacheSync(ctx, g.name, informer)
+
+	g.Lock()
+	defer g.Unlock()
+	g.resuming = false
+	if err != nil {
+		return err
+	}
+	g.setSynced()
+	g.suspended = false
+	return nil
+}
+
+type eventHandlerRegistration struct {
+	handler      cache.ResourceEventHandler
+	resyncPeriod time.Duration
+}
+
+// registrations are the indexers and event handlers added to the informer of a controller from outside.
+type registrations struct {
+	sync.Mutex
+	indexers cache.Indexers
+	handlers []eventHandlerRegistration
+}
+
+func (r *registrations) addIndexers(indexers cache.Indexers) {
+	r.Lock()
+	defer r.Unlock()
+	if r.indexers == nil {
+		r.indexers = cache.Indexers{}
+	}
+	for name, indexFunc := range indexers {
+		r.indexers[name] = indexFunc
+	}
+}
+
+func (r *registrations) addHandler(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+	r.handlers = append(r.handlers, eventHandlerRegistration{
+		handler:      handler,
+		resyncPeriod: resyncPeriod,
+	})
+}
+
+// apply registers everything on informer, which must not be started yet.
+func (r *registrations) apply(informer cache.SharedIndexInformer) error {
+	r.Lock()
+	defer r.Unlock()
+	for _, h := range r.handlers {
+		informer.AddEventHandlerWithResyncPeriod(h.handler, h.resyncPeriod)
+	}
+	if len(r.indexers) == 0 {
+		return nil
+	}
+	return informer.AddIndexers(r.indexers)
+}
+
+// controllerInformer is the informer returned by Informer(). It always uses the current informer of the
+// controller and records what is added to it, so it survives the informer being replaced on Resume.
+type controllerInformer struct {
+	g *genericController
+}
+
+func (c *controllerInformer) current() cache.SharedIndexInformer {
+	c.g.Lock()
+	defer c.g.Unlock()
+	return c.g.informer
+}
+
+func (c *controllerInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	c.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+}
+
+func (c *controllerInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	c.g.registered.addHandler(handler, resyncPeriod)
+	c.current().AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+}
+
+func (c *controllerInformer) GetStore() cache.Store {
+	return c.current().GetStore()
+}
+
+func (c *controllerInformer) GetController() cache.Controller {
+	return c.current().GetController()
+}
+
+func (c *controllerInformer) Run(stopCh <-chan struct{}) {
+	c.current().Run(stopCh)
+}
+
+func (c *controllerInformer) HasSynced() bool {
+	return c.current().HasSynced()
+}
+
+func (c *controllerInformer) LastSyncResourceVersion() string {
+	return c.current().LastSyncResourceVersion()
+}
+
+func (c *controllerInformer) AddIndexers(indexers cache.Indexers) error {
+	if err := c.current().AddIndexers(indexers); err != nil {
+		return err
+	}
+	c.g.registered.addIndexers(indexers)
+	return nil
+}
+
+func (c *controllerInformer) GetIndexer() cache.Indexer {
+	return &controllerIndexer{
+		Indexer: c.current().GetIndexer(),
+		g:       c.g,
+	}
+}
+
+// controllerIndexer records the indexers added to the indexer of the informer.
+type controllerIndexer struct {
+	cache.Indexer
+	g *genericController
+}
+
+func (c *controllerIndexer) AddIndexers(indexers cache.Indexers) error {
+	if err := c.Indexer.AddIndexers(indexers); err != nil {
+		return err
+	}
+	c.g.registered.addIndexers(indexers)
+	return nil
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/objectclient"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+type configMapBackend struct{}
+
+func (configMapBackend) List(opts metav1.ListOptions) (runtime.Object, error) {
+	return &corev1.ConfigMapList{
+		Items: []corev1.ConfigMap{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", ResourceVersion: "1"}},
+		},
+	}, nil
+}
+
+func (configMapBackend) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return watch.NewFake(), nil
+}
+
+func (configMapBackend) ObjectFactory() objectclient.ObjectFactory {
+	return configMapFactory{}
+}
+
+type configMapFactory struct{}
+
+func (configMapFactory) Object() runtime.Object {
+	return &corev1.ConfigMap{}
+}
+
+func (configMapFactory) List() runtime.Object {
+	return &corev1.ConfigMapList{}
+}
+
+func TestResumeKeepsRegistrations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewGenericController("configmaps-resume-test", configMapBackend{})
+	err := c.Informer().GetIndexer().AddIndexers(cache.Indexers{
+		"name": func(obj interface{}) ([]string, error) {
+			return []string{obj.(*corev1.ConfigMap).Name}, nil
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var added int32
+	c.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			atomic.AddInt32(&added, 1)
+		},
+	})
+
+	if !assert.NoError(t, c.Sync(ctx)) {
+		return
+	}
+	id := c.(*genericController).id
+	assert.True(t, healthOf(id).Synced)
+
+	c.Suspend()
+	assert.False(t, healthOf(id).Synced)
+	assert.True(t, healthOf(id).Suspended)
+	if !assert.NoError(t, c.Resume()) {
+		return
+	}
+
+	objs, err := c.Informer().GetIndexer().ByIndex("name", "a")
+	assert.NoError(t, err)
+	assert.Len(t, objs, 1)
+
+	assert.NoError(t, wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&added) == 2, nil
+	}))
+	assert.True(t, healthOf(id).Synced)
+
+	cancel()
+	assert.NoError(t, wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return healthOf(id) == nil, nil
+	}))
+}
+
+func healthOf(id string) *Health {
+	for _, state := range HealthStatus() {
+		if state.ID == id {
+			return &state
+		}
+	}
+	return nil
+}
+
+// blockingBackend blocks lists once blocked is set until release is closed.
+type blockingBackend struct {
+	configMapBackend
+	blocked int32
+	release chan struct{}
+}
+
+func (b *blockingBackend) List(opts metav1.ListOptions) (runtime.Object, error) {
+	if atomic.LoadInt32(&b.blocked) == 1 {
+		<-b.release
+	}
+	return b.configMapBackend.List(opts)
+}
+
+func TestResumeDoesNotBlockWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backend := &blockingBackend{release: make(chan struct{})}
+	c := NewGenericController("configmaps-resume-workers-test", backend)
+
+	handled := make(chan string, 10)
+	c.AddHandler(ctx, "test", func(key string, obj interface{}) (interface{}, error) {
+		handled <- key
+		return obj, nil
+	})
+	if !assert.NoError(t, c.Start(ctx, 1)) {
+		return
+	}
+	assert.Equal(t, "default/a", <-handled)
+
+	c.Suspend()
+	atomic.StoreInt32(&backend.blocked, 1)
+	resumed := make(chan error)
+	go func() {
+		resumed <- c.Resume()
+	}()
+
+	// The key is dropped as the controller is suspended, but a worker has to get to it
+	g := c.(*genericController)
+	c.Enqueue("default", "b")
+	assert.NoError(t, wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return g.queue.Len() == 0 && atomic.LoadInt32(&g.processing) == 0, nil
+	}), "workers blocked while resuming")
+
+	close(backend.release)
+	assert.NoError(t, <-resumed)
+	assert.Equal(t, 1, c.HandlerCount())
+}
+
+func TestWaitForInitialPassCoalescing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewGenericController("configmaps-initial-pass-test", configMapBackend{})
+	c.SetCoalescingWindow(500 * time.Millisecond)
+
+	var handled int32
+	c.AddHandler(ctx, "test", func(key string, obj interface{}) (interface{}, error) {
+		atomic.AddInt32(&handled, 1)
+		return obj, nil
+	})
+	if !assert.NoError(t, c.Start(ctx, 1)) {
+		return
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer waitCancel()
+	assert.NoError(t, c.WaitForInitialPass(waitCtx))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&handled))
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/rancher/norman/objectclient"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// multiNamespaceBackend lists and watches the same type in several namespaces as if it was one collection, so a
+// controller can run with namespace scoped permissions only.
+type multiNamespaceBackend struct {
+	backends []Backend
+}
+
+// NewMultiNamespaceBackend combines backends of the same type, each scoped to one namespace, into one backend.
+func NewMultiNamespaceBackend(backends ...Backend) Backend {
+	return &multiNamespaceBackend{
+		backends: backends,
+	}
+}
+
+func (m *multiNamespaceBackend) ObjectFactory() objectclient.ObjectFactory {
+	return m.backends[0].ObjectFactory()
+}
+
+// List merges the lists of all namespaces. The resource version of the result is the lowest one seen so the
+// watch started from it doesn't miss changes, at the cost of replaying some.
+func (m *multiNamespaceBackend) List(opts metav1.ListOptions) (runtime.Object, error) {
+	var (
+		items           []runtime.Object
+		resourceVersion string
+		lowest          uint64
+	)
+
+	for _, backend := range m.backends {
+		list, err := backend.List(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		listItems, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, listItems...)
+
+		listMeta, err := meta.ListAccessor(list)
+		if err != nil {
+			return nil, err
+		}
+		if rv, err := strconv.ParseUint(listMeta.GetResourceVersion(), 10, 64); err == nil && (resourceVersion == "" || rv < lowest) {
+			lowest = rv
+			resourceVersion = listMeta.GetResourceVersion()
+		}
+	}
+
+	result := m.ObjectFactory().List()
+	if err := meta.SetList(result, items); err != nil {
+		return nil, err
+	}
+	listMeta, err := meta.ListAccessor(result)
+	if err != nil {
+		return nil, err
+	}
+	listMeta.SetResourceVersion(resourceVersion)
+	return result, nil
+}
+
+func (m *multiNamespaceBackend) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	var watches []watch.Interface
+	for _, backend := range m.backends {
+		w, err := backend.Watch(opts)
+		if err != nil {
+			for _, w := range watches {
+				w.Stop()
+			}
+			return nil, err
+		}
+		watches = append(watches, w)
+	}
+
+	return newMultiWatch(watches), nil
+}
+
+type multiWatch struct {
+	watches []watch.Interface
+	result  chan watch.Event
+	stop    chan struct{}
+	once    sync.Once
+}
+
+func newMultiWatch(watches []watch.Interface) *multiWatch {
+	m := &multiWatch{
+		watches: watches,
+		result:  make(chan watch.Event),
+		stop:    make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	for _, w := range watches {
+		wg.Add(1)
+		go func(w watch.Interface) {
+			defer wg.Done()
+			// One namespace ending ends the whole watch so the reflector relists everything
+			defer m.Stop()
+			for event := range w.ResultChan() {
+				select {
+				case m.result <- event:
+				case <-m.stop:
+					return
+				}
+			}
+		}(w)
+	}
+
+	go func() {
+		wg.Wait()
+		close(m.result)
+	}()
+
+	return m
+}
+
+func (m *multiWatch) Stop() {
+	m.once.Do(func() {
+		close(m.stop)
+		for _, w := range m.watches {
+			w.Stop()
+		}
+	})
+}
+
+func (m *multiWatch) ResultChan() <-chan watch.Event {
+	return m.result
+}
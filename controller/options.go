@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// Options configure a controller created with NewGenericController.
+type Options struct {
+	LabelSelector string
+	FieldSelector string
+	Transform     TransformFunc
+	// PriorityQueue hands out changed objects before objects queued by resyncs or for new handlers.
+	PriorityQueue bool
+	// DrainTimeout bounds how long a stopping controller waits for in-flight syncs before abandoning them.
+	DrainTimeout time.Duration
+	// IdleStop suspends the informer while no handlers are registered and resumes it when one is added.
+	IdleStop bool
+	// Recorder reports handler panics as events on the object, see SetEventRecorder.
+	Recorder record.EventRecorder
+}
+
+type Option func(*Options)
+
+// WithLabelSelector makes the informer of the controller only cache and handle objects matching selector.
+func WithLabelSelector(selector string) Option {
+	return func(o *Options) {
+		o.LabelSelector = selector
+	}
+}
+
+// WithFieldSelector makes the informer of the controller only cache and handle objects matching selector.
+func WithFieldSelector(selector string) Option {
+	return func(o *Options) {
+		o.FieldSelector = selector
+	}
+}
+
+// WithPriorityQueue processes changed objects before bulk resync traffic.
+func WithPriorityQueue() Option {
+	return func(o *Options) {
+		o.PriorityQueue = true
+	}
+}
+
+// WithDrainTimeout sets how long a stopping controller waits for in-flight syncs, see DefaultDrainTimeout.
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.DrainTimeout = timeout
+	}
+}
+
+// WithIdleStop releases the watch and cache of the controller while it has no handlers, for rarely used types.
+func WithIdleStop() Option {
+	return func(o *Options) {
+		o.IdleStop = true
+	}
+}
+
+// WithRecorder reports handler panics of the controller as events with recorder, usually the one stored in the
+// context with WithEventRecorder.
+func WithRecorder(recorder record.EventRecorder) Option {
+	return func(o *Options) {
+		o.Recorder = recorder
+	}
+}
+
+func (o Options) apply(opts metav1.ListOptions) metav1.ListOptions {
+	if o.LabelSelector != "" {
+		opts.LabelSelector = o.LabelSelector
+	}
+	if o.FieldSelector != "" {
+		opts.FieldSelector = o.FieldSelector
+	}
+	return opts
+}
+
+// HandlerOption configures a handler added with AddHandler.
+type HandlerOption func(*handlerDef)
+
+// HandlerWorkers limits how many workers of the controller may run the handler at the same time. By default every
+// worker may, so the handler runs with the parallelism of the controller.
+func HandlerWorkers(count int) HandlerOption {
+	return func(h *handlerDef) {
+		if count > 0 {
+			h.workers = make(chan struct{}, count)
+		}
+	}
+}
+
+// HandlerTimeout cancels the context passed to a ContextHandlerFunc after timeout for every sync.
+func HandlerTimeout(timeout time.Duration) HandlerOption {
+	return func(h *handlerDef) {
+		h.timeout = timeout
+	}
+}
+
+// HandlerResync runs the handler for every object each period, independent of the resync period of the
+// controller. Zero disables periodic resyncs for the handler.
+func HandlerResync(period time.Duration) HandlerOption {
+	return func(h *handlerDef) {
+		h.resync = &period
+	}
+}
+
+// keyLimiter bounds the number of workers processing the same object key. The queue already serializes equal
+// items, but an object can be queued both by its key and by a generation key of a handler started later.
+type keyLimiter struct {
+	sync.Mutex
+	cond     *sync.Cond
+	max      int
+	inFlight map[string]int
+}
+
+func newKeyLimiter() *keyLimiter {
+	k := &keyLimiter{
+		inFlight: map[string]int{},
+	}
+	k.cond = sync.NewCond(&k.Mutex)
+	return k
+}
+
+func (k *keyLimiter) setMax(max int) {
+	k.Lock()
+	k.max = max
+	k.Unlock()
+	k.cond.Broadcast()
+}
+
+func (k *keyLimiter) acquire(key string) {
+	k.Lock()
+	defer k.Unlock()
+	for k.max > 0 && k.inFlight[key] >= k.max {
+		k.cond.Wait()
+	}
+	k.inFlight[key]++
+}
+
+func (k *keyLimiter) release(key string) {
+	k.Lock()
+	if k.inFlight[key] <= 1 {
+		delete(k.inFlight, key)
+	} else {
+		k.inFlight[key]--
+	}
+	k.Unlock()
+	k.cond.Broadcast()
+}
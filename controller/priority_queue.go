@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// isBulkItem returns true for items queued for resyncs and newly started handlers rather than for a change of
+// the object.
+func isBulkItem(item interface{}) bool {
+	switch item.(type) {
+	case resyncKey, handlerKey, generationKey:
+		return true
+	}
+	return false
+}
+
+// priorityQueue is a rate limiting work queue handing out items queued for changes before bulk items, so objects
+// changed by users are handled quickly even while a resync queued every object. Like the client-go queue an item
+// is never processed concurrently and adding an item that is already queued is a no-op. It reports the same
+// metrics as the client-go queues, named after the controller.
+type priorityQueue struct {
+	cond        *sync.Cond
+	high, low   []interface{}
+	dirty       map[interface{}]struct{}
+	processing  map[interface{}]struct{}
+	waiting     map[interface{}]time.Time
+	shutdown    bool
+	rateLimiter workqueue.RateLimiter
+	isLow       func(interface{}) bool
+
+	depth        workqueue.GaugeMetric
+	adds         workqueue.CounterMetric
+	latency      workqueue.SummaryMetric
+	workDuration workqueue.SummaryMetric
+	retries      workqueue.CounterMetric
+	addTimes     map[interface{}]time.Time
+	startTimes   map[interface{}]time.Time
+}
+
+func newPriorityQueue(name string, rateLimiter workqueue.RateLimiter, isLow func(interface{}) bool) *priorityQueue {
+	return &priorityQueue{
+		cond:         sync.NewCond(&sync.Mutex{}),
+		dirty:        map[interface{}]struct{}{},
+		processing:   map[interface{}]struct{}{},
+		waiting:      map[interface{}]time.Time{},
+		rateLimiter:  rateLimiter,
+		isLow:        isLow,
+		depth:        queueMetrics.NewDepthMetric(name),
+		adds:         queueMetrics.NewAddsMetric(name),
+		latency:      queueMetrics.NewLatencyMetric(name),
+		workDuration: queueMetrics.NewWorkDurationMetric(name),
+		retries:      queueMetrics.NewRetriesMetric(name),
+		addTimes:     map[interface{}]time.Time{},
+		startTimes:   map[interface{}]time.Time{},
+	}
+}
+
+func (q *priorityQueue) Add(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	if q.shutdown {
+		return
+	}
+	if _, ok := q.dirty[item]; ok {
+		return
+	}
+	q.dirty[item] = struct{}{}
+	if _, ok := q.processing[item]; ok {
+		return
+	}
+
+	q.push(item)
+	q.cond.Signal()
+}
+
+func (q *priorityQueue) push(item interface{}) {
+	q.adds.Inc()
+	q.depth.Inc()
+	if _, ok := q.addTimes[item]; !ok {
+		q.addTimes[item] = time.Now()
+	}
+
+	if q.isLow(item) {
+		q.low = append(q.low, item)
+	} else {
+		q.high = append(q.high, item)
+	}
+}
+
+func (q *priorityQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return len(q.high) + len(q.low)
+}
+
+func (q *priorityQueue) Get() (interface{}, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	for len(q.high)+len(q.low) == 0 && !q.shutdown {
+		q.cond.Wait()
+	}
+	if len(q.high)+len(q.low) == 0 {
+		return nil, true
+	}
+
+	var item interface{}
+	if len(q.high) > 0 {
+		item, q.high = q.high[0], q.high[1:]
+	} else {
+		item, q.low = q.low[0], q.low[1:]
+	}
+
+	q.processing[item] = struct{}{}
+	delete(q.dirty, item)
+
+	q.depth.Dec()
+	q.startTimes[item] = time.Now()
+	if added, ok := q.addTimes[item]; ok {
+		q.latency.Observe(microsecondsSince(added))
+		delete(q.addTimes, item)
+	}
+	return item, false
+}
+
+func (q *priorityQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	delete(q.processing, item)
+	if started, ok := q.startTimes[item]; ok {
+		q.workDuration.Observe(microsecondsSince(started))
+		delete(q.startTimes, item)
+	}
+	if _, ok := q.dirty[item]; ok {
+		q.push(item)
+		q.cond.Signal()
+	}
+}
+
+func (q *priorityQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shutdown = true
+	q.cond.Broadcast()
+}
+
+func (q *priorityQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shutdown
+}
+
+// AddAfter adds item once duration passed. Only the earliest pending add of an item is kept.
+func (q *priorityQueue) AddAfter(item interface{}, duration time.Duration) {
+	if duration <= 0 {
+		q.Add(item)
+		return
+	}
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	if q.shutdown {
+		return
+	}
+	readyAt := time.Now().Add(duration)
+	if existing, ok := q.waiting[item]; ok && !existing.After(readyAt) {
+		return
+	}
+	q.waiting[item] = readyAt
+
+	time.AfterFunc(duration, func() {
+		q.cond.L.Lock()
+		current, ok := q.waiting[item]
+		if !ok || !current.Equal(readyAt) {
+			q.cond.L.Unlock()
+			return
+		}
+		delete(q.waiting, item)
+		q.cond.L.Unlock()
+
+		q.Add(item)
+	})
+}
+
+func (q *priorityQueue) AddRateLimited(item interface{}) {
+	q.retries.Inc()
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+func (q *priorityQueue) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}
+
+func (q *priorityQueue) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+// microsecondsSince returns the time since start in microseconds, the unit of the workqueue metrics.
+func microsecondsSince(start time.Time) float64 {
+	return float64(time.Since(start) / time.Microsecond)
+}
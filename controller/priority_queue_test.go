@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := newPriorityQueue("test", workqueue.DefaultControllerRateLimiter(), isBulkItem)
+
+	q.Add(resyncKey{key: "a"})
+	q.Add(resyncKey{key: "b"})
+	q.Add("c")
+	q.Add("c")
+	assert.Equal(t, 3, q.Len())
+
+	item, _ := q.Get()
+	assert.Equal(t, "c", item)
+
+	q.Add("c")
+	assert.Equal(t, 2, q.Len())
+	q.Done("c")
+	assert.Equal(t, 3, q.Len())
+
+	item, _ = q.Get()
+	assert.Equal(t, "c", item)
+	item, _ = q.Get()
+	assert.Equal(t, resyncKey{key: "a"}, item)
+
+	q.ShutDown()
+	item, _ = q.Get()
+	assert.Equal(t, resyncKey{key: "b"}, item)
+	_, quit := q.Get()
+	assert.True(t, quit)
+}
+
+type countingMetric struct {
+	value    int
+	observed int
+}
+
+func (c *countingMetric) Inc()            { c.value++ }
+func (c *countingMetric) Dec()            { c.value-- }
+func (c *countingMetric) Observe(float64) { c.observed++ }
+
+type countingMetricsProvider map[string]*countingMetric
+
+func (p countingMetricsProvider) metric(name string) *countingMetric {
+	if p[name] == nil {
+		p[name] = &countingMetric{}
+	}
+	return p[name]
+}
+
+func (p countingMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return p.metric(name + "/depth")
+}
+
+func (p countingMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return p.metric(name + "/adds")
+}
+
+func (p countingMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return p.metric(name + "/latency")
+}
+
+func (p countingMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return p.metric(name + "/work")
+}
+
+func (p countingMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return p.metric(name + "/retries")
+}
+
+func TestPriorityQueueMetrics(t *testing.T) {
+	provider := countingMetricsProvider{}
+	defer func(previous workqueue.MetricsProvider) {
+		queueMetrics = previous
+	}(queueMetrics)
+	queueMetrics = provider
+
+	q := newPriorityQueue("test", workqueue.DefaultControllerRateLimiter(), isBulkItem)
+	q.Add("a")
+	q.Add(resyncKey{key: "b"})
+	assert.Equal(t, 2, provider["test/adds"].value)
+	assert.Equal(t, 2, provider["test/depth"].value)
+
+	item, _ := q.Get()
+	assert.Equal(t, 1, provider["test/depth"].value)
+	assert.Equal(t, 1, provider["test/latency"].observed)
+	q.Done(item)
+	assert.Equal(t, 1, provider["test/work"].observed)
+
+	q.AddRateLimited("a")
+	assert.Equal(t, 1, provider["test/retries"].value)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// RateLimit configures the retry rate limiter of a controller or handler. Failing items are retried with an
+// exponential backoff from BaseDelay up to MaxDelay, and all retries together are limited to QPS with bursts of
+// Burst. Zero values use the defaults.
+type RateLimit struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	QPS       float64
+	Burst     int
+}
+
+var DefaultRateLimit = RateLimit{
+	BaseDelay: 500 * time.Millisecond,
+	MaxDelay:  1000 * time.Second,
+	QPS:       10,
+	Burst:     100,
+}
+
+// NewRateLimiter returns the workqueue rate limiter described by limit.
+func NewRateLimiter(limit RateLimit) workqueue.RateLimiter {
+	if limit.BaseDelay <= 0 {
+		limit.BaseDelay = DefaultRateLimit.BaseDelay
+	}
+	if limit.MaxDelay <= 0 {
+		limit.MaxDelay = DefaultRateLimit.MaxDelay
+	}
+	if limit.QPS <= 0 {
+		limit.QPS = DefaultRateLimit.QPS
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = DefaultRateLimit.Burst
+	}
+
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(limit.BaseDelay, limit.MaxDelay),
+		// This is only for retry speed and its only the overall factor (not per item)
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(limit.QPS), limit.Burst)},
+	)
+}
+
+// HandlerRateLimiter retries objects the handler failed on with delays from rateLimiter instead of the rate
+// limiter of the controller. If several handlers fail on an object the longest delay wins.
+func HandlerRateLimiter(rateLimiter workqueue.RateLimiter) HandlerOption {
+	return func(h *handlerDef) {
+		h.rateLimiter = rateLimiter
+	}
+}
+
+// swappableRateLimiter lets the rate limiter of a controller be replaced after its queue was created.
+type swappableRateLimiter struct {
+	sync.RWMutex
+	rateLimiter workqueue.RateLimiter
+}
+
+func (s *swappableRateLimiter) set(rateLimiter workqueue.RateLimiter) {
+	s.Lock()
+	defer s.Unlock()
+	s.rateLimiter = rateLimiter
+}
+
+func (s *swappableRateLimiter) get() workqueue.RateLimiter {
+	s.RLock()
+	defer s.RUnlock()
+	return s.rateLimiter
+}
+
+func (s *swappableRateLimiter) When(item interface{}) time.Duration {
+	return s.get().When(item)
+}
+
+func (s *swappableRateLimiter) Forget(item interface{}) {
+	s.get().Forget(item)
+}
+
+func (s *swappableRateLimiter) NumRequeues(item interface{}) int {
+	return s.get().NumRequeues(item)
+}
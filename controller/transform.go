@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const LastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// TransformFunc modifies objects before they are stored in the cache of a controller.
+type TransformFunc func(obj runtime.Object) runtime.Object
+
+// DefaultTransform is applied to the objects of every controller created without WithTransform.
+var DefaultTransform TransformFunc
+
+// WithTransform sets the transform applied to listed and watched objects before they are cached.
+func WithTransform(transform TransformFunc) Option {
+	return func(o *Options) {
+		o.Transform = transform
+	}
+}
+
+// StripMetadata returns a transform removing the managed fields, the last applied configuration annotation and
+// the given annotations, which are rarely needed by controllers but take up much of the cache. Objects updated
+// from the cache lose what was stripped, so only use it for controllers that don't write back these objects or
+// patch them.
+func StripMetadata(annotations ...string) TransformFunc {
+	strip := append([]string{LastAppliedConfigAnnotation}, annotations...)
+	return func(obj runtime.Object) runtime.Object {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+		}
+
+		metadata, err := meta.Accessor(obj)
+		if err != nil {
+			return obj
+		}
+
+		current := metadata.GetAnnotations()
+		for _, key := range strip {
+			delete(current, key)
+		}
+		if current != nil {
+			metadata.SetAnnotations(current)
+		}
+		return obj
+	}
+}
+
+func transformList(transform TransformFunc, list runtime.Object) (runtime.Object, error) {
+	if transform == nil {
+		return list, nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		items[i] = transform(item)
+	}
+	return list, meta.SetList(list, items)
+}
+
+func transformWatch(transform TransformFunc, w watch.Interface) watch.Interface {
+	if transform == nil {
+		return w
+	}
+
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		if event.Object != nil && event.Type != watch.Error {
+			event.Object = transform(event.Object)
+		}
+		return event, true
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/rancher/norman/api"
+	"github.com/rancher/norman/signal"
 	"github.com/rancher/norman/store/crd"
 	"github.com/rancher/norman/store/proxy"
 	"github.com/rancher/norman/types"
@@ -36,6 +37,8 @@ var (
 )
 
 func main() {
+	ctx := signal.SigTermCancelContext(context.Background())
+
 	kubeConfig, err := clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
 	if err != nil {
 		panic(err)
@@ -51,7 +54,7 @@ func main() {
 	}
 
 	Schemas.MustImportAndCustomize(&version, Foo{}, func(schema *types.Schema) {
-		if err := crdFactory.AssignStores(context.Background(), types.DefaultStorageContext, schema); err != nil {
+		if err := crdFactory.AssignStores(ctx, types.DefaultStorageContext, schema); err != nil {
 			panic(err)
 		}
 	})
@@ -62,5 +65,8 @@ func main() {
 	}
 
 	fmt.Println("Listening on 0.0.0.0:1234")
-	http.ListenAndServe("0.0.0.0:1234", server)
+	if err := signal.ListenAndServe(ctx, &http.Server{Addr: "0.0.0.0:1234", Handler: server}); err != nil {
+		panic(err)
+	}
+	<-signal.Done(ctx)
 }
@@ -3,6 +3,8 @@ package generator
 var clientTemplate = `package client
 
 import (
+	"context"
+
 	"github.com/rancher/norman/clientbase"
 )
 
@@ -28,4 +30,16 @@ func NewClient(opts *clientbase.ClientOpts) (*Client, error) {
 {{end}}{{end}}
 	return client, nil
 }
+
+// WithContext returns a copy of the client sending every request with ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	client := &Client{
+		APIBaseClient: c.APIBaseClient.WithContext(ctx),
+	}
+
+    {{range .schemas}}
+    {{- if . | hasGet }}client.{{.CodeName}} = new{{.CodeName}}Client(client)
+{{end}}{{end}}
+	return client
+}
 `
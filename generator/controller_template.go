@@ -4,6 +4,8 @@ var controllerTemplate = `package {{.schema.Version.Version}}
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	{{.importPackage}}
 	"github.com/rancher/norman/objectclient"
@@ -62,6 +64,8 @@ type {{.schema.CodeName}}List struct {
 
 type {{.schema.CodeName}}HandlerFunc func(key string, obj *{{.prefix}}{{.schema.CodeName}}) (runtime.Object, error)
 
+type {{.schema.CodeName}}ContextHandlerFunc func(ctx context.Context, key string, obj *{{.prefix}}{{.schema.CodeName}}) (runtime.Object, error)
+
 type {{.schema.CodeName}}ChangeHandlerFunc func(obj *{{.prefix}}{{.schema.CodeName}}) (runtime.Object, error)
 
 type {{.schema.CodeName}}Lister interface {
@@ -73,11 +77,14 @@ type {{.schema.CodeName}}Controller interface {
 	Generic() controller.GenericController
 	Informer() cache.SharedIndexInformer
 	Lister() {{.schema.CodeName}}Lister
-	AddHandler(ctx context.Context, name string, handler {{.schema.CodeName}}HandlerFunc)
-	AddClusterScopedHandler(ctx context.Context, name, clusterName string, handler {{.schema.CodeName}}HandlerFunc)
+	AddHandler(ctx context.Context, name string, handler {{.schema.CodeName}}HandlerFunc, opts ...controller.HandlerOption)
+	AddContextHandler(ctx context.Context, name string, handler {{.schema.CodeName}}ContextHandlerFunc, opts ...controller.HandlerOption)
+	AddClusterScopedHandler(ctx context.Context, name, clusterName string, handler {{.schema.CodeName}}HandlerFunc, opts ...controller.HandlerOption)
 	Enqueue(namespace, name string)
+	EnqueueAfter(namespace, name string, after time.Duration)
 	Sync(ctx context.Context) error
 	Start(ctx context.Context, threadiness int) error
+	WaitForInitialPass(ctx context.Context) error
 }
 
 type {{.schema.CodeName}}Interface interface {
@@ -86,16 +93,20 @@ type {{.schema.CodeName}}Interface interface {
 	GetNamespaced(namespace, name string, opts metav1.GetOptions) (*{{.prefix}}{{.schema.CodeName}}, error)
 	Get(name string, opts metav1.GetOptions) (*{{.prefix}}{{.schema.CodeName}}, error)
 	Update(*{{.prefix}}{{.schema.CodeName}}) (*{{.prefix}}{{.schema.CodeName}}, error)
+	UpdateIfChanged(existing, desired *{{.prefix}}{{.schema.CodeName}}) (*{{.prefix}}{{.schema.CodeName}}, error)
 	Delete(name string, options *metav1.DeleteOptions) error
 	DeleteNamespaced(namespace, name string, options *metav1.DeleteOptions) error
 	List(opts metav1.ListOptions) (*{{.schema.CodeName}}List, error)
 	Watch(opts metav1.ListOptions) (watch.Interface, error)
 	DeleteCollection(deleteOpts *metav1.DeleteOptions, listOpts metav1.ListOptions) error
 	Controller() {{.schema.CodeName}}Controller
-	AddHandler(ctx context.Context, name string, sync {{.schema.CodeName}}HandlerFunc)
-	AddLifecycle(ctx context.Context, name string, lifecycle {{.schema.CodeName}}Lifecycle)
-	AddClusterScopedHandler(ctx context.Context, name, clusterName string, sync {{.schema.CodeName}}HandlerFunc)
-	AddClusterScopedLifecycle(ctx context.Context, name, clusterName string, lifecycle {{.schema.CodeName}}Lifecycle)
+	FilteredController(labelSelector, fieldSelector string) {{.schema.CodeName}}Controller
+	NamespacedController(namespaces ...string) {{.schema.CodeName}}Controller
+	AddHandler(ctx context.Context, name string, sync {{.schema.CodeName}}HandlerFunc, opts ...controller.HandlerOption)
+	AddContextHandler(ctx context.Context, name string, sync {{.schema.CodeName}}ContextHandlerFunc, opts ...controller.HandlerOption)
+	AddLifecycle(ctx context.Context, name string, lifecycle {{.schema.CodeName}}Lifecycle, opts ...controller.HandlerOption)
+	AddClusterScopedHandler(ctx context.Context, name, clusterName string, sync {{.schema.CodeName}}HandlerFunc, opts ...controller.HandlerOption)
+	AddClusterScopedLifecycle(ctx context.Context, name, clusterName string, lifecycle {{.schema.CodeName}}Lifecycle, opts ...controller.HandlerOption)
 }
 
 type {{.schema.ID}}Lister struct {
@@ -144,7 +155,7 @@ func (c *{{.schema.ID}}Controller) Lister() {{.schema.CodeName}}Lister {
 }
 
 
-func (c *{{.schema.ID}}Controller) AddHandler(ctx context.Context, name string, handler {{.schema.CodeName}}HandlerFunc) {
+func (c *{{.schema.ID}}Controller) AddHandler(ctx context.Context, name string, handler {{.schema.CodeName}}HandlerFunc, opts ...controller.HandlerOption) {
 	c.GenericController.AddHandler(ctx, name, func(key string, obj interface{}) (interface{}, error) {
 		if obj == nil {
 			return handler(key, nil)
@@ -153,10 +164,22 @@ func (c *{{.schema.ID}}Controller) AddHandler(ctx context.Context, name string,
 		} else {
 			return nil, nil
 		}
-	})
+	}, opts...)
+}
+
+func (c *{{.schema.ID}}Controller) AddContextHandler(ctx context.Context, name string, handler {{.schema.CodeName}}ContextHandlerFunc, opts ...controller.HandlerOption) {
+	c.GenericController.AddContextHandler(ctx, name, func(ctx context.Context, key string, obj interface{}) (interface{}, error) {
+		if obj == nil {
+			return handler(ctx, key, nil)
+		} else if v, ok := obj.(*{{.prefix}}{{.schema.CodeName}}); ok {
+			return handler(ctx, key, v)
+		} else {
+			return nil, nil
+		}
+	}, opts...)
 }
 
-func (c *{{.schema.ID}}Controller) AddClusterScopedHandler(ctx context.Context, name, cluster string, handler {{.schema.CodeName}}HandlerFunc) {
+func (c *{{.schema.ID}}Controller) AddClusterScopedHandler(ctx context.Context, name, cluster string, handler {{.schema.CodeName}}HandlerFunc, opts ...controller.HandlerOption) {
 	c.GenericController.AddHandler(ctx, name, func(key string, obj interface{}) (interface{}, error) {
 		if obj == nil {
 			return handler(key, nil)
@@ -165,7 +188,7 @@ func (c *{{.schema.ID}}Controller) AddClusterScopedHandler(ctx context.Context,
 		} else {
 			return nil, nil
 		}
-	})
+	}, opts...)
 }
 
 type {{.schema.ID}}Factory struct {
@@ -180,27 +203,73 @@ func (c {{.schema.ID}}Factory) List() runtime.Object {
 }
 
 func (s *{{.schema.ID}}Client) Controller() {{.schema.CodeName}}Controller {
+	return s.FilteredController("", "")
+}
+
+// FilteredController returns a controller whose informer only caches and handles the objects matching the
+// label and field selectors.
+func (s *{{.schema.ID}}Client) FilteredController(labelSelector, fieldSelector string) {{.schema.CodeName}}Controller {
 	s.client.Lock()
 	defer s.client.Unlock()
 
-	c, ok := s.client.{{.schema.ID}}Controllers[s.ns]
+	key := s.ns
+	if labelSelector != "" || fieldSelector != "" {
+		key = s.ns + "?" + labelSelector + "&" + fieldSelector
+	}
+
+	c, ok := s.client.{{.schema.ID}}Controllers[key]
 	if ok {
 		return c
 	}
 
 	genericController := controller.NewGenericController({{.schema.CodeName}}GroupVersionKind.Kind+"Controller",
-		s.objectClient)
+		s.objectClient, controller.WithLabelSelector(labelSelector), controller.WithFieldSelector(fieldSelector),
+		controller.WithRecorder(s.client.recorder))
 
 	c = &{{.schema.ID}}Controller{
 		GenericController: genericController,
 	}
 
-	s.client.{{.schema.ID}}Controllers[s.ns] = c
+	s.client.{{.schema.ID}}Controllers[key] = c
     s.client.starters = append(s.client.starters, c)
 
 	return c
 }
 
+// NamespacedController returns a controller that only lists and watches the given namespaces, for deployments
+// without cluster wide permissions.
+func (s *{{.schema.ID}}Client) NamespacedController(namespaces ...string) {{.schema.CodeName}}Controller {
+	if len(namespaces) == 0 {
+		return s.Controller()
+	}
+
+	s.client.Lock()
+	defer s.client.Unlock()
+
+	key := "namespaces=" + strings.Join(namespaces, ",")
+	c, ok := s.client.{{.schema.ID}}Controllers[key]
+	if ok {
+		return c
+	}
+
+	var backends []controller.Backend
+	for _, namespace := range namespaces {
+		backends = append(backends, objectclient.NewObjectClient(namespace, s.client.restClient, &{{.schema.CodeName}}Resource, {{.schema.CodeName}}GroupVersionKind, {{.schema.ID}}Factory{}))
+	}
+
+	genericController := controller.NewGenericController({{.schema.CodeName}}GroupVersionKind.Kind+"Controller",
+		controller.NewMultiNamespaceBackend(backends...), controller.WithRecorder(s.client.recorder))
+
+	c = &{{.schema.ID}}Controller{
+		GenericController: genericController,
+	}
+
+	s.client.{{.schema.ID}}Controllers[key] = c
+	s.client.starters = append(s.client.starters, c)
+
+	return c
+}
+
 type {{.schema.ID}}Client struct {
 	client *Client
 	ns string
@@ -232,6 +301,16 @@ func (s *{{.schema.ID}}Client) Update(o *{{.prefix}}{{.schema.CodeName}}) (*{{.p
 	return obj.(*{{.prefix}}{{.schema.CodeName}}), err
 }
 
+// UpdateIfChanged updates the object to desired, skipping the call if only status or server set metadata differ
+// from existing.
+func (s *{{.schema.ID}}Client) UpdateIfChanged(existing, desired *{{.prefix}}{{.schema.CodeName}}) (*{{.prefix}}{{.schema.CodeName}}, error) {
+	obj, err := s.objectClient.UpdateIfChanged(desired.Name, existing, desired)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*{{.prefix}}{{.schema.CodeName}}), nil
+}
+
 func (s *{{.schema.ID}}Client) Delete(name string, options *metav1.DeleteOptions) error {
 	return s.objectClient.Delete(name, options)
 }
@@ -259,22 +338,26 @@ func (s *{{.schema.ID}}Client) DeleteCollection(deleteOpts *metav1.DeleteOptions
 	return s.objectClient.DeleteCollection(deleteOpts, listOpts)
 }
 
-func (s *{{.schema.ID}}Client) AddHandler(ctx context.Context, name string, sync {{.schema.CodeName}}HandlerFunc) {
-	s.Controller().AddHandler(ctx, name, sync)
+func (s *{{.schema.ID}}Client) AddHandler(ctx context.Context, name string, sync {{.schema.CodeName}}HandlerFunc, opts ...controller.HandlerOption) {
+	s.Controller().AddHandler(ctx, name, sync, opts...)
 }
 
-func (s *{{.schema.ID}}Client) AddLifecycle(ctx context.Context, name string, lifecycle {{.schema.CodeName}}Lifecycle) {
+func (s *{{.schema.ID}}Client) AddContextHandler(ctx context.Context, name string, sync {{.schema.CodeName}}ContextHandlerFunc, opts ...controller.HandlerOption) {
+	s.Controller().AddContextHandler(ctx, name, sync, opts...)
+}
+
+func (s *{{.schema.ID}}Client) AddLifecycle(ctx context.Context, name string, lifecycle {{.schema.CodeName}}Lifecycle, opts ...controller.HandlerOption) {
 	sync := New{{.schema.CodeName}}LifecycleAdapter(name, false, s, lifecycle)
-	s.Controller().AddHandler(ctx, name, sync)
+	s.Controller().AddHandler(ctx, name, sync, opts...)
 }
 
-func (s *{{.schema.ID}}Client) AddClusterScopedHandler(ctx context.Context, name, clusterName string, sync {{.schema.CodeName}}HandlerFunc) {
-	s.Controller().AddClusterScopedHandler(ctx, name, clusterName, sync)
+func (s *{{.schema.ID}}Client) AddClusterScopedHandler(ctx context.Context, name, clusterName string, sync {{.schema.CodeName}}HandlerFunc, opts ...controller.HandlerOption) {
+	s.Controller().AddClusterScopedHandler(ctx, name, clusterName, sync, opts...)
 }
 
-func (s *{{.schema.ID}}Client) AddClusterScopedLifecycle(ctx context.Context, name, clusterName string, lifecycle {{.schema.CodeName}}Lifecycle) {
+func (s *{{.schema.ID}}Client) AddClusterScopedLifecycle(ctx context.Context, name, clusterName string, lifecycle {{.schema.CodeName}}Lifecycle, opts ...controller.HandlerOption) {
 	sync := New{{.schema.CodeName}}LifecycleAdapter(name+"_"+clusterName, true, s, lifecycle)
-	s.Controller().AddClusterScopedHandler(ctx, name, clusterName, sync)
+	s.Controller().AddClusterScopedHandler(ctx, name, clusterName, sync, opts...)
 }
 
 type {{.schema.CodeName}}Indexer func(obj *{{.prefix}}{{.schema.CodeName}}) ([]string, error)
@@ -291,6 +374,7 @@ type {{.schema.CodeName}}Client interface {
 	Create(*{{.prefix}}{{.schema.CodeName}}) (*{{.prefix}}{{.schema.CodeName}}, error)
 	Get(namespace, name string, opts metav1.GetOptions) (*{{.prefix}}{{.schema.CodeName}}, error)
 	Update(*{{.prefix}}{{.schema.CodeName}}) (*{{.prefix}}{{.schema.CodeName}}, error)
+	UpdateIfChanged(existing, desired *{{.prefix}}{{.schema.CodeName}}) (*{{.prefix}}{{.schema.CodeName}}, error)
 	Delete(namespace, name string, options *metav1.DeleteOptions) error
 	List(namespace string, opts metav1.ListOptions) (*{{.schema.CodeName}}List, error)
 	Watch(opts metav1.ListOptions) (watch.Interface, error)
@@ -301,6 +385,7 @@ type {{.schema.CodeName}}Client interface {
 	OnChange(ctx context.Context, name string, sync {{.schema.CodeName}}ChangeHandlerFunc)
 	OnRemove(ctx context.Context, name string, sync {{.schema.CodeName}}ChangeHandlerFunc)
     Enqueue(namespace, name string)
+    EnqueueAfter(namespace, name string, after time.Duration)
 
 	Generic() controller.GenericController
     ObjectClient() *objectclient.ObjectClient
@@ -332,6 +417,10 @@ func (n *{{.schema.ID}}Client2) Enqueue(namespace, name string) {
 	n.iface.Controller().Enqueue(namespace, name)
 }
 
+func (n *{{.schema.ID}}Client2) EnqueueAfter(namespace, name string, after time.Duration) {
+	n.iface.Controller().EnqueueAfter(namespace, name, after)
+}
+
 func (n *{{.schema.ID}}Client2) Create(obj *{{.prefix}}{{.schema.CodeName}}) (*{{.prefix}}{{.schema.CodeName}}, error) {
 	return n.iface.Create(obj)
 }
@@ -344,6 +433,10 @@ func (n *{{.schema.ID}}Client2) Update(obj *{{.prefix}}{{.schema.CodeName}}) (*{
 	return n.iface.Update(obj)
 }
 
+func (n *{{.schema.ID}}Client2) UpdateIfChanged(existing, desired *{{.prefix}}{{.schema.CodeName}}) (*{{.prefix}}{{.schema.CodeName}}, error) {
+	return n.iface.UpdateIfChanged(existing, desired)
+}
+
 func (n *{{.schema.ID}}Client2) Delete(namespace, name string, options *metav1.DeleteOptions) error {
 	return n.iface.DeleteNamespaced(namespace, name, options)
 }
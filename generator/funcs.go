@@ -18,6 +18,7 @@ func funcs() template.FuncMap {
 		"hasGet":              hasGet,
 		"hasPost":             hasPost,
 		"getCollectionOutput": getCollectionOutput,
+		"isBinary":            isBinary,
 	}
 }
 
@@ -48,3 +49,7 @@ func getCollectionOutput(output, codeName string) string {
 	}
 	return convert.Capitalize(output)
 }
+
+func isBinary(output string) bool {
+	return output == types.ActionOutputBinary
+}
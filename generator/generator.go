@@ -120,7 +120,7 @@ func getTypeMap(schema *types.Schema, schemas *types.Schemas) map[string]fieldIn
 func getResourceActions(schema *types.Schema, schemas *types.Schemas) map[string]types.Action {
 	result := map[string]types.Action{}
 	for name, action := range schema.ResourceActions {
-		if action.Output != "" {
+		if action.Output != "" && action.Output != types.ActionOutputBinary {
 			if schemas.Schema(&schema.Version, action.Output) != nil {
 				result[name] = action
 			}
@@ -134,7 +134,7 @@ func getResourceActions(schema *types.Schema, schemas *types.Schemas) map[string
 func getCollectionActions(schema *types.Schema, schemas *types.Schemas) map[string]types.Action {
 	result := map[string]types.Action{}
 	for name, action := range schema.CollectionActions {
-		if action.Output != "" {
+		if action.Output != "" && action.Output != types.ActionOutputBinary {
 			output := action.Output
 			if action.Output == "collection" {
 				output = strings.ToLower(schema.CodeName)
@@ -379,8 +379,12 @@ func Generate(schemas *types.Schemas, privateTypes map[string]bool, cattleOutput
 	}
 
 	fakeDir := path.Join(k8sDir, "fakes")
+	cattleFakeDir := ""
+	if cattleDir != "" {
+		cattleFakeDir = path.Join(cattleDir, "fakes")
+	}
 
-	if err := prepareDirs(cattleDir, k8sDir, fakeDir); err != nil {
+	if err := prepareDirs(cattleDir, k8sDir, fakeDir, cattleFakeDir); err != nil {
 		return err
 	}
 
@@ -446,7 +450,10 @@ func Generate(schemas *types.Schemas, privateTypes map[string]bool, cattleOutput
 	}
 
 	if cattleOutputPackage != "" {
-		return gofmt(baseDir, cattleOutputPackage)
+		if err := gofmt(baseDir, cattleOutputPackage); err != nil {
+			return err
+		}
+		return generateClientFakes(cattleDir, cattleClientTypes)
 	}
 
 	return nil
@@ -545,6 +552,32 @@ func isObjectOrList(t *gengotypes.Type) bool {
 	return false
 }
 
+// generateClientFakes generates mocks of the operations interfaces of the client types so consumers can test
+// without an API server.
+func generateClientFakes(cattleDir string, schemas []*types.Schema) error {
+	m, err := moq.New(cattleDir, "fakes")
+	if err != nil {
+		return err
+	}
+
+	for _, schema := range schemas {
+		if !hasGet(schema) {
+			continue
+		}
+
+		var out bytes.Buffer
+		if err := m.Mock(&out, schema.CodeName+"Operations"); err != nil {
+			return err
+		}
+
+		filePath := path.Join(cattleDir, "fakes", "zz_generated_"+addUnderscore(schema.ID)+"_mock.go")
+		if err := ioutil.WriteFile(filePath, out.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func generateFakes(k8sDir string, controllers []*types.Schema) error {
 	m, err := moq.New(k8sDir, "fakes")
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/rancher/norman/controller"
 	"github.com/rancher/norman/restwatch"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 )
 
 type (
@@ -35,15 +36,18 @@ type Client struct {
 	sync.Mutex
 	restClient         rest.Interface
 	starters           []controller.Starter
+	recorder           record.EventRecorder
 	{{range .schemas}}
 	{{.ID}}Controllers map[string]{{.CodeName}}Controller{{end}}
 }
 
+// Factory creates the clients, whose controllers report handler panics with the event recorder of ctx.
 func Factory(ctx context.Context, config rest.Config) (context.Context, controller.Starter, error) {
-	c, err := NewForConfig(config)
+	c, err := newClient(config)
 	if err != nil {
 		return ctx, nil, err
 	}
+	c.recorder = controller.EventRecorderFrom(ctx)
 
 	cs := NewClientsFromInterface(c)
 
@@ -79,6 +83,10 @@ func NewClientsFromInterface(iface Interface) *Clients {
 }
 
 func NewForConfig(config rest.Config) (Interface, error) {
+	return newClient(config)
+}
+
+func newClient(config rest.Config) (*Client, error) {
 	if config.NegotiatedSerializer == nil {
 		config.NegotiatedSerializer = dynamic.NegotiatedSerializer
 	}
@@ -107,6 +115,19 @@ func (c *Client) Start(ctx context.Context, threadiness int) error {
 	return controller.Start(ctx, threadiness, c.starters...)
 }
 
+func (c *Client) WaitForInitialPass(ctx context.Context) error {
+	return controller.WaitForInitialPass(ctx, c.starters...)
+}
+
+func (c *Client) Drained() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		controller.WaitForDrain(c.starters...)
+		close(done)
+	}()
+	return done
+}
+
 {{range .schemas}}
 type {{.CodeNamePlural}}Getter interface {
 	{{.CodeNamePlural}}(namespace string) {{.CodeName}}Interface
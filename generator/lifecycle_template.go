@@ -29,6 +29,20 @@ func (w *{{.schema.ID}}LifecycleAdapter) HasFinalize() bool {
 	return !ok || o.HasFinalize()
 }
 
+func (w *{{.schema.ID}}LifecycleAdapter) FinalizerName() string {
+	if o, ok := w.lifecycle.(lifecycle.ObjectLifecycleFinalizer); ok {
+		return o.FinalizerName()
+	}
+	return ""
+}
+
+func (w *{{.schema.ID}}LifecycleAdapter) FinalizeAfter() []string {
+	if o, ok := w.lifecycle.(lifecycle.ObjectLifecycleFinalizer); ok {
+		return o.FinalizeAfter()
+	}
+	return nil
+}
+
 func (w *{{.schema.ID}}LifecycleAdapter) Create(obj runtime.Object) (runtime.Object, error) {
 	o, err := w.lifecycle.Create(obj.(*{{.prefix}}{{.schema.CodeName}}))
 	if o == nil {
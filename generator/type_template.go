@@ -4,6 +4,11 @@ var typeTemplate = `package client
 
 {{- if .schema | hasGet }}
 import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/rancher/norman/clientbase"
 	"github.com/rancher/norman/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -37,34 +42,22 @@ type {{.schema.CodeName}}Client struct {
 }
 
 type {{.schema.CodeName}}Operations interface {
-    List(opts *types.ListOpts) (*{{.schema.CodeName}}Collection, error)
-    Create(opts *{{.schema.CodeName}}) (*{{.schema.CodeName}}, error)
-    Update(existing *{{.schema.CodeName}}, updates interface{}) (*{{.schema.CodeName}}, error)
-    Replace(existing *{{.schema.CodeName}}) (*{{.schema.CodeName}}, error)
-    ByID(id string) (*{{.schema.CodeName}}, error)
-    Delete(container *{{.schema.CodeName}}) error
+    List(opts *types.ListOpts, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}Collection, error)
+    ListAll(opts *types.ListOpts) (*{{.schema.CodeName}}Collection, error)
+    ListPager(opts *types.ListOpts) *{{.schema.CodeName}}ListPager
+    ListEach(opts *types.ListOpts, f func(*{{.schema.CodeName}}) error) error
+    Create(opts *{{.schema.CodeName}}, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}, error)
+    Update(existing *{{.schema.CodeName}}, updates interface{}, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}, error)
+    Replace(existing *{{.schema.CodeName}}, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}, error)
+    ByID(id string, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}, error)
+    Watch(ctx context.Context, opts *types.ListOpts) (<-chan {{.schema.CodeName}}Event, error)
+    Delete(container *{{.schema.CodeName}}, callOpts ...clientbase.CallOption) error
     {{range $key, $value := .resourceActions}}
-        {{if (and (eq $value.Input "") (eq $value.Output ""))}}
-            Action{{$key | capitalize}} (resource *{{$.schema.CodeName}}) (error)
-        {{else if (and (eq $value.Input "") (ne $value.Output ""))}}
-            Action{{$key | capitalize}} (resource *{{$.schema.CodeName}}) (*{{.Output | capitalize}}, error)
-        {{else if (and (ne $value.Input "") (eq $value.Output ""))}}
-            Action{{$key | capitalize}} (resource *{{$.schema.CodeName}}, input *{{$value.Input | capitalize}}) (error)
-        {{else}}
-            Action{{$key | capitalize}} (resource *{{$.schema.CodeName}}, input *{{$value.Input | capitalize}}) (*{{.Output | capitalize}}, error)
-        {{end}}
-	{{end}}
+    Action{{$key | capitalize}}(ctx context.Context, resource *{{$.schema.CodeName}}{{if ne $value.Input ""}}, input *{{$value.Input | capitalize}}{{end}}, callOpts ...clientbase.CallOption) ({{if isBinary $value.Output}}io.ReadCloser, {{else if ne $value.Output ""}}*{{$value.Output | capitalize}}, {{end}}error)
+    {{- end}}
     {{range $key, $value := .collectionActions}}
-        {{if (and (eq $value.Input "") (eq $value.Output ""))}}
-            CollectionAction{{$key | capitalize}} (resource *{{$.schema.CodeName}}Collection) (error)
-        {{else if (and (eq $value.Input "") (ne $value.Output ""))}}
-            CollectionAction{{$key | capitalize}} (resource *{{$.schema.CodeName}}Collection) (*{{getCollectionOutput $value.Output $.schema.CodeName}}, error)
-        {{else if (and (ne $value.Input "") (eq $value.Output ""))}}
-            CollectionAction{{$key | capitalize}} (resource *{{$.schema.CodeName}}Collection, input *{{$value.Input | capitalize}}) (error)
-        {{else}}
-            CollectionAction{{$key | capitalize}} (resource *{{$.schema.CodeName}}Collection, input *{{$value.Input | capitalize}}) (*{{getCollectionOutput $value.Output $.schema.CodeName}}, error)
-        {{end}}
-	{{end}}
+    CollectionAction{{$key | capitalize}}(ctx context.Context, resource *{{$.schema.CodeName}}Collection{{if ne $value.Input ""}}, input *{{$value.Input | capitalize}}{{end}}, callOpts ...clientbase.CallOption) ({{if isBinary $value.Output}}io.ReadCloser, {{else if ne $value.Output ""}}*{{getCollectionOutput $value.Output $.schema.CodeName}}, {{end}}error)
+    {{- end}}
 }
 
 func new{{.schema.CodeName}}Client(apiClient *Client) *{{.schema.CodeName}}Client {
@@ -73,27 +66,27 @@ func new{{.schema.CodeName}}Client(apiClient *Client) *{{.schema.CodeName}}Clien
     }
 }
 
-func (c *{{.schema.CodeName}}Client) Create(container *{{.schema.CodeName}}) (*{{.schema.CodeName}}, error) {
+func (c *{{.schema.CodeName}}Client) Create(container *{{.schema.CodeName}}, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}, error) {
     resp := &{{.schema.CodeName}}{}
-    err := c.apiClient.Ops.DoCreate({{.schema.CodeName}}Type, container, resp)
+    err := c.apiClient.Ops.WithOptions(callOpts...).DoCreate({{.schema.CodeName}}Type, container, resp)
     return resp, err
 }
 
-func (c *{{.schema.CodeName}}Client) Update(existing *{{.schema.CodeName}}, updates interface{}) (*{{.schema.CodeName}}, error) {
+func (c *{{.schema.CodeName}}Client) Update(existing *{{.schema.CodeName}}, updates interface{}, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}, error) {
     resp := &{{.schema.CodeName}}{}
-    err := c.apiClient.Ops.DoUpdate({{.schema.CodeName}}Type, &existing.Resource, updates, resp)
+    err := c.apiClient.Ops.WithOptions(callOpts...).DoUpdate({{.schema.CodeName}}Type, &existing.Resource, updates, resp)
     return resp, err
 }
 
-func (c *{{.schema.CodeName}}Client) Replace(obj *{{.schema.CodeName}}) (*{{.schema.CodeName}}, error) {
+func (c *{{.schema.CodeName}}Client) Replace(obj *{{.schema.CodeName}}, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}, error) {
 	resp := &{{.schema.CodeName}}{}
-	err := c.apiClient.Ops.DoReplace({{.schema.CodeName}}Type, &obj.Resource, obj, resp)
+	err := c.apiClient.Ops.WithOptions(callOpts...).DoReplace({{.schema.CodeName}}Type, &obj.Resource, obj, resp)
 	return resp, err
 }
 
-func (c *{{.schema.CodeName}}Client) List(opts *types.ListOpts) (*{{.schema.CodeName}}Collection, error) {
+func (c *{{.schema.CodeName}}Client) List(opts *types.ListOpts, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}Collection, error) {
     resp := &{{.schema.CodeName}}Collection{}
-    err := c.apiClient.Ops.DoList({{.schema.CodeName}}Type, opts, resp)
+    err := c.apiClient.Ops.WithOptions(callOpts...).DoList({{.schema.CodeName}}Type, opts, resp)
     resp.client = c
     return resp, err
 }
@@ -108,59 +101,150 @@ func (cc *{{.schema.CodeName}}Collection) Next() (*{{.schema.CodeName}}Collectio
     return nil, nil
 }
 
-func (c *{{.schema.CodeName}}Client) ByID(id string) (*{{.schema.CodeName}}, error) {
-    resp := &{{.schema.CodeName}}{}
-    err := c.apiClient.Ops.DoByID({{.schema.CodeName}}Type, id, resp)
+// ListAll lists every page of the collection, following the next links, and returns the items in one collection.
+func (c *{{.schema.CodeName}}Client) ListAll(opts *types.ListOpts) (*{{.schema.CodeName}}Collection, error) {
+    resp := &{{.schema.CodeName}}Collection{}
+    err := c.ListPager(opts).EachPage(func(page *{{.schema.CodeName}}Collection) error {
+        resp.Collection = page.Collection
+        resp.Data = append(resp.Data, page.Data...)
+        return nil
+    })
+    resp.Pagination = nil
+    resp.client = c
     return resp, err
 }
 
-func (c *{{.schema.CodeName}}Client) Delete(container *{{.schema.CodeName}}) error {
-    return c.apiClient.Ops.DoResourceDelete({{.schema.CodeName}}Type, &container.Resource)
+// ListEach calls f for every object of every page, decoding the responses while they are read so large
+// collections are never held in memory.
+func (c *{{.schema.CodeName}}Client) ListEach(opts *types.ListOpts, f func(*{{.schema.CodeName}}) error) error {
+    each := func(item interface{}) error {
+        return f(item.(*{{.schema.CodeName}}))
+    }
+
+    resp := &{{.schema.CodeName}}Collection{}
+    err := c.apiClient.Ops.DoListStream({{.schema.CodeName}}Type, opts, resp, each)
+    for err == nil && resp.Pagination != nil && resp.Pagination.Next != "" {
+        next := resp.Pagination.Next
+        resp = &{{.schema.CodeName}}Collection{}
+        err = c.apiClient.Ops.DoNextStream(next, resp, each)
+    }
+    return err
+}
+
+func (c *{{.schema.CodeName}}Client) ListPager(opts *types.ListOpts) *{{.schema.CodeName}}ListPager {
+    return &{{.schema.CodeName}}ListPager{
+        client: c,
+        opts:   opts,
+    }
 }
 
-{{range $key, $value := .resourceActions}}
-    {{if (and (eq $value.Input "") (eq $value.Output ""))}}
-        func (c *{{$.schema.CodeName}}Client) Action{{$key | capitalize}} (resource *{{$.schema.CodeName}}) (error) {
-            err := c.apiClient.Ops.DoAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Resource, nil, nil)
+type {{.schema.CodeName}}ListPager struct {
+    client *{{.schema.CodeName}}Client
+    opts   *types.ListOpts
+}
+
+// EachPage calls f for every page of the collection until there is no next page or f returns an error.
+func (p *{{.schema.CodeName}}ListPager) EachPage(f func(page *{{.schema.CodeName}}Collection) error) error {
+    page, err := p.client.List(p.opts)
+    for {
+        if err != nil {
             return err
-    {{else if (and (eq $value.Input "") (ne $value.Output ""))}}
-        func (c *{{$.schema.CodeName}}Client) Action{{$key | capitalize}} (resource *{{$.schema.CodeName}}) (*{{.Output | capitalize}}, error) {
-            resp := &{{.Output | capitalize}}{}
-            err := c.apiClient.Ops.DoAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Resource, nil, resp)
-            return resp, err
-    {{else if (and (ne $value.Input "") (eq $value.Output ""))}}
-        func (c *{{$.schema.CodeName}}Client) Action{{$key | capitalize}} (resource *{{$.schema.CodeName}}, input *{{$value.Input | capitalize}}) (error) {
-            err := c.apiClient.Ops.DoAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Resource, input, nil)
+        }
+        if page == nil {
+            return nil
+        }
+        if err := f(page); err != nil {
             return err
-    {{else}}
-        func (c *{{$.schema.CodeName}}Client) Action{{$key | capitalize}} (resource *{{$.schema.CodeName}}, input *{{$value.Input | capitalize}}) (*{{.Output | capitalize}}, error) {
-            resp := &{{.Output | capitalize}}{}
-            err := c.apiClient.Ops.DoAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Resource, input, resp)
-            return resp, err
-    {{- end -}}
+        }
+        page, err = page.Next()
     }
+}
+
+func (c *{{.schema.CodeName}}Client) ByID(id string, callOpts ...clientbase.CallOption) (*{{.schema.CodeName}}, error) {
+    resp := &{{.schema.CodeName}}{}
+    err := c.apiClient.Ops.WithOptions(callOpts...).DoByID({{.schema.CodeName}}Type, id, resp)
+    return resp, err
+}
+
+type {{.schema.CodeName}}Event struct {
+    // Removed is true if the object was deleted.
+    Removed bool
+    Object  *{{.schema.CodeName}}
+}
+
+// Watch streams changes of the objects until ctx is done. When the subscription reconnects every object is listed
+// again and sent as a change, since changes while disconnected are not replayed.
+func (c *{{.schema.CodeName}}Client) Watch(ctx context.Context, opts *types.ListOpts) (<-chan {{.schema.CodeName}}Event, error) {
+    events, err := c.apiClient.Subscribe(ctx, {{.schema.CodeName}}Type, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    result := make(chan {{.schema.CodeName}}Event)
+    go func() {
+        defer close(result)
+        for event := range events {
+            if event.Name == clientbase.EventResync {
+                all, err := c.ListAll(opts)
+                if err != nil {
+                    continue
+                }
+                for i := range all.Data {
+                    select {
+                    case result <- {{.schema.CodeName}}Event{Object: &all.Data[i]}:
+                    case <-ctx.Done():
+                        return
+                    }
+                }
+                continue
+            }
+
+            obj := &{{.schema.CodeName}}{}
+            if err := json.Unmarshal(event.Data, obj); err != nil {
+                continue
+            }
+            select {
+            case result <- {{.schema.CodeName}}Event{Removed: event.Name == clientbase.EventRemove, Object: obj}:
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+
+    return result, nil
+}
+
+func (c *{{.schema.CodeName}}Client) Delete(container *{{.schema.CodeName}}, callOpts ...clientbase.CallOption) error {
+    return c.apiClient.Ops.WithOptions(callOpts...).DoResourceDelete({{.schema.CodeName}}Type, &container.Resource)
+}
+
+{{range $key, $value := .resourceActions}}
+func (c *{{$.schema.CodeName}}Client) Action{{$key | capitalize}}(ctx context.Context, resource *{{$.schema.CodeName}}{{if ne $value.Input ""}}, input *{{$value.Input | capitalize}}{{end}}, callOpts ...clientbase.CallOption) ({{if isBinary $value.Output}}io.ReadCloser, {{else if ne $value.Output ""}}*{{$value.Output | capitalize}}, {{end}}error) {
+    ops := c.apiClient.Ops.WithContext(ctx).WithOptions(callOpts...)
+    {{- if isBinary $value.Output}}
+    return ops.DoActionStream({{$.schema.CodeName}}Type, "{{$key}}", &resource.Resource, {{if ne $value.Input ""}}input{{else}}nil{{end}})
+    {{- else if ne $value.Output ""}}
+    resp := &{{$value.Output | capitalize}}{}
+    err := ops.DoAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Resource, {{if ne $value.Input ""}}input{{else}}nil{{end}}, resp)
+    return resp, err
+    {{- else}}
+    return ops.DoAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Resource, {{if ne $value.Input ""}}input{{else}}nil{{end}}, nil)
+    {{- end}}
+}
 {{end}}
 
 {{range $key, $value := .collectionActions}}
-    {{if (and (eq $value.Input "") (eq $value.Output ""))}}
-        func (c *{{$.schema.CodeName}}Client) CollectionAction{{$key | capitalize}} (resource *{{$.schema.CodeName}}Collection) (error) {
-			err := c.apiClient.Ops.DoCollectionAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Collection, nil, nil)
-			return err
-    {{else if (and (eq $value.Input "") (ne $value.Output ""))}}
-        func (c *{{$.schema.CodeName}}Client) CollectionAction{{$key | capitalize}} (resource *{{$.schema.CodeName}}Collection) (*{{getCollectionOutput $value.Output $.schema.CodeName}}, error) {
-			resp := &{{getCollectionOutput $value.Output $.schema.CodeName}}{}
-			err := c.apiClient.Ops.DoCollectionAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Collection, nil, resp)
-			return resp, err
-	{{else if (and (ne $value.Input "") (eq $value.Output ""))}}
-		func (c *{{$.schema.CodeName}}Client) CollectionAction{{$key | capitalize}} (resource *{{$.schema.CodeName}}Collection, input *{{$value.Input | capitalize}}) (error) {
-			err := c.apiClient.Ops.DoCollectionAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Collection, input, nil)
-    		return err
-	{{else}}
-        func (c *{{$.schema.CodeName}}Client) CollectionAction{{$key | capitalize}} (resource *{{$.schema.CodeName}}Collection, input *{{$value.Input | capitalize}}) (*{{getCollectionOutput $value.Output $.schema.CodeName}}, error) {
-			resp := &{{getCollectionOutput $value.Output $.schema.CodeName}}{}
-			err := c.apiClient.Ops.DoCollectionAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Collection, input, resp)
-    		return resp, err
-    {{- end -}}
-    }
+func (c *{{$.schema.CodeName}}Client) CollectionAction{{$key | capitalize}}(ctx context.Context, resource *{{$.schema.CodeName}}Collection{{if ne $value.Input ""}}, input *{{$value.Input | capitalize}}{{end}}, callOpts ...clientbase.CallOption) ({{if isBinary $value.Output}}io.ReadCloser, {{else if ne $value.Output ""}}*{{getCollectionOutput $value.Output $.schema.CodeName}}, {{end}}error) {
+    ops := c.apiClient.Ops.WithContext(ctx).WithOptions(callOpts...)
+    {{- if isBinary $value.Output}}
+    return ops.DoCollectionActionStream({{$.schema.CodeName}}Type, "{{$key}}", &resource.Collection, {{if ne $value.Input ""}}input{{else}}nil{{end}})
+    {{- else if ne $value.Output ""}}
+    resp := &{{getCollectionOutput $value.Output $.schema.CodeName}}{}
+    err := ops.DoCollectionAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Collection, {{if ne $value.Input ""}}input{{else}}nil{{end}}, resp)
+    return resp, err
+    {{- else}}
+    return ops.DoCollectionAction({{$.schema.CodeName}}Type, "{{$key}}", &resource.Collection, {{if ne $value.Input ""}}input{{else}}nil{{end}}, nil)
+    {{- end}}
+}
 {{end}}
 {{end}}`
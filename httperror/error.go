@@ -10,6 +10,7 @@ var (
 	NotFound         = ErrorCode{"NotFound", 404}
 	MethodNotAllowed = ErrorCode{"MethodNotAllow", 405}
 	Conflict         = ErrorCode{"Conflict", 409}
+	Expired          = ErrorCode{"Expired", 410}
 
 	InvalidDateFormat  = ErrorCode{"InvalidDateFormat", 422}
 	InvalidFormat      = ErrorCode{"InvalidFormat", 422}
@@ -29,9 +30,11 @@ var (
 	InvalidType        = ErrorCode{"InvalidType", 422}
 	ActionNotAvailable = ErrorCode{"ActionNotAvailable", 404}
 	InvalidState       = ErrorCode{"InvalidState", 422}
+	QuotaExceeded      = ErrorCode{"QuotaExceeded", 422}
 
 	ServerError        = ErrorCode{"ServerError", 500}
 	ClusterUnavailable = ErrorCode{"ClusterUnavailable", 503}
+	ServiceUnavailable = ErrorCode{"ServiceUnavailable", 503}
 )
 
 type ErrorCode struct {
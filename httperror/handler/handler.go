@@ -16,12 +16,12 @@ func ErrorHandler(request *types.APIContext, err error) {
 			if url == "" {
 				url = request.Request.URL.String()
 			}
-			logrus.Errorf("API error response %v for %v %v. Cause: %v", apiError.Code.Status, request.Request.Method,
-				url, apiError.Cause)
+			logrus.Errorf("API error response %v for %v %v [%s]. Cause: %v", apiError.Code.Status, request.Request.Method,
+				url, request.RequestID, apiError.Cause)
 		}
 		error = apiError
 	} else {
-		logrus.Errorf("Unknown error: %v", err)
+		logrus.Errorf("Unknown error [%s]: %v", request.RequestID, err)
 		error = &httperror.APIError{
 			Code:    httperror.ServerError,
 			Message: err.Error(),
@@ -29,9 +29,24 @@ func ErrorHandler(request *types.APIContext, err error) {
 	}
 
 	data := toError(error)
+	if request.RequestID != "" {
+		data["requestId"] = request.RequestID
+	}
 	request.WriteResponse(error.Code.Status, data)
 }
 
+// ErrorData returns the error response body of err, for reporting errors as part of another response.
+func ErrorData(err error) map[string]interface{} {
+	apiError, ok := err.(*httperror.APIError)
+	if !ok {
+		apiError = &httperror.APIError{
+			Code:    httperror.ServerError,
+			Message: err.Error(),
+		}
+	}
+	return toError(apiError)
+}
+
 func toError(apiError *httperror.APIError) map[string]interface{} {
 	e := map[string]interface{}{
 		"type":    "/meta/schemas/error",
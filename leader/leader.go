@@ -3,8 +3,10 @@ package leader
 import (
 	"context"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/rancher/norman/signal"
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -20,36 +22,63 @@ import (
 
 type Callback func(cb context.Context)
 
+// Config tunes the leader election. Zero values use the defaults. LockType is "configmaps", the default,
+// "endpoints" or "leases", which needs a cluster serving coordination.k8s.io/v1. If OnStoppedLeading is set it is
+// called when the leadership is lost and the election returns, cancelling the context given to the callback,
+// instead of exiting the process.
+type Config struct {
+	LockType         string
+	LeaseDuration    time.Duration
+	RenewDeadline    time.Duration
+	RetryPeriod      time.Duration
+	OnStoppedLeading func()
+}
+
 func RunOrDie(ctx context.Context, namespace, name string, client kubernetes.Interface, cb Callback) {
+	RunWithConfigOrDie(ctx, namespace, name, client, Config{}, cb)
+}
+
+func RunWithConfigOrDie(ctx context.Context, namespace, name string, client kubernetes.Interface, config Config, cb Callback) {
 	if namespace == "" {
 		namespace = "kube-system"
 	}
 
-	err := run(ctx, namespace, name, client, cb)
+	// Shutdown waits for the election to return and for the callback to finish draining
+	var (
+		stopped   = make(chan struct{})
+		callbacks sync.WaitGroup
+	)
+	unregister := signal.OnShutdown(ctx, "leader election "+name, func() {
+		<-stopped
+		callbacks.Wait()
+	})
+
+	err := run(ctx, namespace, name, client, config, &callbacks, cb)
+	close(stopped)
+	go func() {
+		callbacks.Wait()
+		unregister()
+	}()
 	if err != nil {
-		logrus.Fatalf("Failed to start leader election for %s", name)
+		logrus.Fatalf("Failed to start leader election for %s: %v", name, err)
+	}
+	if config.OnStoppedLeading == nil && ctx.Err() == nil {
+		panic("Failed to start leader election for " + name)
 	}
-	panic("Failed to start leader election for " + name)
 }
 
-func run(ctx context.Context, namespace, name string, client kubernetes.Interface, cb Callback) error {
+func run(ctx context.Context, namespace, name string, client kubernetes.Interface, config Config, callbacks *sync.WaitGroup, cb Callback) error {
 	id, err := os.Hostname()
 	if err != nil {
 		return err
 	}
 
-	recorder := createRecorder(name, client)
-
-	rl, err := resourcelock.New(resourcelock.ConfigMapsResourceLock,
-		namespace,
-		name,
-		client.CoreV1(),
-		resourcelock.ResourceLockConfig{
-			Identity:      id,
-			EventRecorder: recorder,
-		})
+	rl, err := newLock(namespace, name, client, resourcelock.ResourceLockConfig{
+		Identity:      id,
+		EventRecorder: createRecorder(name, client),
+	}, config.LockType)
 	if err != nil {
-		logrus.Fatalf("error creating leader lock for %s: %v", name, err)
+		return err
 	}
 
 	t := time.Second
@@ -59,19 +88,53 @@ func run(ctx context.Context, namespace, name string, client kubernetes.Interfac
 
 	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
 		Lock:          rl,
-		LeaseDuration: 45 * t,
-		RenewDeadline: 30 * t,
-		RetryPeriod:   2 * t,
+		LeaseDuration: durationOrDefault(config.LeaseDuration, 45*t),
+		RenewDeadline: durationOrDefault(config.RenewDeadline, 30*t),
+		RetryPeriod:   durationOrDefault(config.RetryPeriod, 2*t),
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
-				go cb(ctx)
+				callbacks.Add(1)
+				go func() {
+					defer callbacks.Done()
+					cb(ctx)
+				}()
 			},
 			OnStoppedLeading: func() {
-				logrus.Fatalf("leaderelection lost for %s", name)
+				if ctx.Err() != nil {
+					logrus.Infof("Stopped leading %s, shutting down", name)
+					return
+				}
+				if config.OnStoppedLeading == nil {
+					logrus.Fatalf("leaderelection lost for %s", name)
+				}
+				logrus.Errorf("leaderelection lost for %s", name)
+				config.OnStoppedLeading()
 			},
 		},
 	})
-	panic("unreachable")
+	return nil
+}
+
+func newLock(namespace, name string, client kubernetes.Interface, config resourcelock.ResourceLockConfig, lockType string) (resourcelock.Interface, error) {
+	switch lockType {
+	case "":
+		lockType = resourcelock.ConfigMapsResourceLock
+	case LeasesResourceLock:
+		return &LeaseLock{
+			Namespace:  namespace,
+			Name:       name,
+			Client:     client.Discovery().RESTClient(),
+			LockConfig: config,
+		}, nil
+	}
+	return resourcelock.New(lockType, namespace, name, client.CoreV1(), config)
+}
+
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
 }
 
 func createRecorder(name string, kubeClient kubernetes.Interface) record.EventRecorder {
@@ -0,0 +1,44 @@
+package leader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+func TestNewLock(t *testing.T) {
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: "http://localhost:0"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	config := resourcelock.ResourceLockConfig{Identity: "test"}
+
+	lock, err := newLock("kube-system", "test", client, config, "")
+	if assert.NoError(t, err) {
+		assert.IsType(t, &resourcelock.ConfigMapLock{}, lock)
+		assert.Equal(t, "kube-system/test", lock.Describe())
+		assert.Equal(t, "test", lock.Identity())
+	}
+
+	lock, err = newLock("kube-system", "test", client, config, resourcelock.EndpointsResourceLock)
+	if assert.NoError(t, err) {
+		assert.IsType(t, &resourcelock.EndpointsLock{}, lock)
+	}
+
+	lock, err = newLock("kube-system", "test", client, config, LeasesResourceLock)
+	if assert.NoError(t, err) {
+		assert.IsType(t, &LeaseLock{}, lock)
+	}
+
+	_, err = newLock("kube-system", "test", client, config, "unknown")
+	assert.Error(t, err)
+}
+
+func TestDurationOrDefault(t *testing.T) {
+	assert.Equal(t, 5*time.Second, durationOrDefault(0, 5*time.Second))
+	assert.Equal(t, time.Second, durationOrDefault(time.Second, 5*time.Second))
+}
@@ -0,0 +1,162 @@
+package leader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const LeasesResourceLock = "leases"
+
+type lease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              leaseSpec `json:"spec,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       *string           `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int32            `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *metav1.MicroTime `json:"acquireTime,omitempty"`
+	RenewTime            *metav1.MicroTime `json:"renewTime,omitempty"`
+	LeaseTransitions     *int32            `json:"leaseTransitions,omitempty"`
+}
+
+// LeaseLock is a resource lock stored in a coordination.k8s.io Lease. The Lease API is accessed through a plain
+// REST client so no generated client for it is needed.
+type LeaseLock struct {
+	Namespace  string
+	Name       string
+	Client     rest.Interface
+	LockConfig resourcelock.ResourceLockConfig
+
+	lease *lease
+}
+
+func (l *LeaseLock) path() string {
+	return fmt.Sprintf("/apis/coordination.k8s.io/v1/namespaces/%s/leases", l.Namespace)
+}
+
+func (l *LeaseLock) Get() (*resourcelock.LeaderElectionRecord, error) {
+	data, err := l.Client.Get().AbsPath(l.path(), l.Name).Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &lease{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	l.lease = result
+
+	return toRecord(result.Spec), nil
+}
+
+func (l *LeaseLock) Create(ler resourcelock.LeaderElectionRecord) error {
+	obj := &lease{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: l.Namespace,
+			Name:      l.Name,
+		},
+		Spec: toSpec(ler),
+	}
+	return l.write(l.Client.Post().AbsPath(l.path()), obj)
+}
+
+func (l *LeaseLock) Update(ler resourcelock.LeaderElectionRecord) error {
+	if l.lease == nil {
+		return errors.New("lease not initialized, call get or create first")
+	}
+	obj := l.lease.DeepCopy()
+	obj.Spec = toSpec(ler)
+	return l.write(l.Client.Put().AbsPath(l.path(), l.Name), obj)
+}
+
+func (l *LeaseLock) write(req *rest.Request, obj *lease) error {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	data, err := req.SetHeader("Content-Type", "application/json").Body(body).Do().Raw()
+	if err != nil {
+		return err
+	}
+
+	result := &lease{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return err
+	}
+	l.lease = result
+	return nil
+}
+
+func (l *LeaseLock) RecordEvent(s string) {
+	if l.LockConfig.EventRecorder == nil || l.lease == nil {
+		return
+	}
+	events := fmt.Sprintf("%v %v", l.LockConfig.Identity, s)
+	l.LockConfig.EventRecorder.Event(l.lease, "Normal", "LeaderElection", events)
+}
+
+func (l *LeaseLock) Describe() string {
+	return fmt.Sprintf("%v/%v", l.Namespace, l.Name)
+}
+
+func (l *LeaseLock) Identity() string {
+	return l.LockConfig.Identity
+}
+
+func (l *lease) DeepCopyObject() runtime.Object {
+	return l.DeepCopy()
+}
+
+func (l *lease) DeepCopy() *lease {
+	result := &lease{
+		TypeMeta: l.TypeMeta,
+		Spec:     l.Spec,
+	}
+	l.ObjectMeta.DeepCopyInto(&result.ObjectMeta)
+	return result
+}
+
+func toRecord(spec leaseSpec) *resourcelock.LeaderElectionRecord {
+	record := &resourcelock.LeaderElectionRecord{}
+	if spec.HolderIdentity != nil {
+		record.HolderIdentity = *spec.HolderIdentity
+	}
+	if spec.LeaseDurationSeconds != nil {
+		record.LeaseDurationSeconds = int(*spec.LeaseDurationSeconds)
+	}
+	if spec.LeaseTransitions != nil {
+		record.LeaderTransitions = int(*spec.LeaseTransitions)
+	}
+	if spec.AcquireTime != nil {
+		record.AcquireTime = metav1.Time{Time: spec.AcquireTime.Time}
+	}
+	if spec.RenewTime != nil {
+		record.RenewTime = metav1.Time{Time: spec.RenewTime.Time}
+	}
+	return record
+}
+
+func toSpec(ler resourcelock.LeaderElectionRecord) leaseSpec {
+	duration := int32(ler.LeaseDurationSeconds)
+	transitions := int32(ler.LeaderTransitions)
+	return leaseSpec{
+		HolderIdentity:       &ler.HolderIdentity,
+		LeaseDurationSeconds: &duration,
+		AcquireTime:          &metav1.MicroTime{Time: ler.AcquireTime.Time},
+		RenewTime:            &metav1.MicroTime{Time: ler.RenewTime.Time},
+		LeaseTransitions:     &transitions,
+	}
+}
@@ -0,0 +1,92 @@
+package leader
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// leaseServer serves a single Lease like the coordination.k8s.io API.
+func leaseServer(t *testing.T) *httptest.Server {
+	var stored []byte
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/apis/coordination.k8s.io/v1/namespaces/kube-system/leases/test":
+			if stored == nil {
+				rw.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(rw).Encode(metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusFailure,
+					Reason:   metav1.StatusReasonNotFound,
+					Code:     http.StatusNotFound,
+				})
+				return
+			}
+			rw.Write(stored)
+		case req.Method == http.MethodPost && req.URL.Path == "/apis/coordination.k8s.io/v1/namespaces/kube-system/leases",
+			req.Method == http.MethodPut && req.URL.Path == "/apis/coordination.k8s.io/v1/namespaces/kube-system/leases/test":
+			body, err := ioutil.ReadAll(req.Body)
+			assert.NoError(t, err)
+			stored = body
+			rw.Write(stored)
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+			rw.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestLeaseLock(t *testing.T) {
+	server := leaseServer(t)
+	defer server.Close()
+
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	lock, err := newLock("kube-system", "test", client, resourcelock.ResourceLockConfig{Identity: "a"}, LeasesResourceLock)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "kube-system/test", lock.Describe())
+
+	_, err = lock.Get()
+	assert.True(t, errors.IsNotFound(err))
+
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	assert.NoError(t, lock.Create(resourcelock.LeaderElectionRecord{
+		HolderIdentity:       "a",
+		LeaseDurationSeconds: 45,
+		AcquireTime:          now,
+		RenewTime:            now,
+	}))
+
+	record, err := lock.Get()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a", record.HolderIdentity)
+		assert.Equal(t, 45, record.LeaseDurationSeconds)
+		assert.True(t, now.Equal(&record.RenewTime))
+	}
+
+	assert.NoError(t, lock.Update(resourcelock.LeaderElectionRecord{
+		HolderIdentity:       "b",
+		LeaseDurationSeconds: 45,
+		LeaderTransitions:    1,
+	}))
+	record, err = lock.Get()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "b", record.HolderIdentity)
+		assert.Equal(t, 1, record.LeaderTransitions)
+	}
+}
@@ -3,12 +3,15 @@ package lifecycle
 import (
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/rancher/norman/objectclient"
 	"github.com/rancher/norman/types/slice"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 var (
@@ -28,23 +31,58 @@ type ObjectLifecycleCondition interface {
 	HasFinalize() bool
 }
 
+// ObjectLifecycleFinalizer can be implemented by an ObjectLifecycle to control its finalizer. FinalizerName
+// replaces the default finalizer name derived from the lifecycle name if not empty. Finalize is only called once
+// all finalizers returned by FinalizeAfter are gone from the object, so lifecycles on the same type can clean up
+// in order.
+type ObjectLifecycleFinalizer interface {
+	FinalizerName() string
+	FinalizeAfter() []string
+}
+
+// finalizedTTL is how long finalized objects are remembered to ignore stale cache entries still listing the
+// removed finalizer.
+const finalizedTTL = 10 * time.Minute
+
 type objectLifecycleAdapter struct {
 	name          string
 	clusterScoped bool
 	lifecycle     ObjectLifecycle
 	objectClient  *objectclient.ObjectClient
+
+	finalizedLock sync.Mutex
+	finalized     map[types.UID]time.Time
 }
 
 func NewObjectLifecycleAdapter(name string, clusterScoped bool, lifecycle ObjectLifecycle, objectClient *objectclient.ObjectClient) func(key string, obj interface{}) (interface{}, error) {
-	o := objectLifecycleAdapter{
+	o := &objectLifecycleAdapter{
 		name:          name,
 		clusterScoped: clusterScoped,
 		lifecycle:     lifecycle,
 		objectClient:  objectClient,
+		finalized:     map[types.UID]time.Time{},
 	}
 	return o.sync
 }
 
+// FinalizerKey returns the default finalizer added for the lifecycle called name.
+func FinalizerKey(name string, clusterScoped bool) string {
+	if clusterScoped {
+		return ScopedFinalizerKey + name
+	}
+	return finalizerKey + name
+}
+
+// FinalizerRemoved returns true if obj is being deleted and finalizer was already removed from it, meaning the
+// finalizer ran and must not run again.
+func FinalizerRemoved(obj runtime.Object, finalizer string) bool {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	return metadata.GetDeletionTimestamp() != nil && !slice.ContainsString(metadata.GetFinalizers(), finalizer)
+}
+
 func (o *objectLifecycleAdapter) sync(key string, in interface{}) (interface{}, error) {
 	if in == nil || reflect.ValueOf(in).IsNil() {
 		return nil, nil
@@ -99,7 +137,12 @@ func (o *objectLifecycleAdapter) finalize(obj runtime.Object) (runtime.Object, b
 		return nil, true, nil
 	}
 
-	if !slice.ContainsString(metadata.GetFinalizers(), o.constructFinalizerKey()) {
+	if FinalizerRemoved(obj, o.constructFinalizerKey()) || o.isFinalized(metadata.GetUID()) {
+		return nil, false, nil
+	}
+
+	// The removal of the pending finalizers is an update that queues the object again
+	if o.hasPendingFinalizers(metadata.GetFinalizers()) {
 		return nil, false, nil
 	}
 
@@ -109,9 +152,46 @@ func (o *objectLifecycleAdapter) finalize(obj runtime.Object) (runtime.Object, b
 	}
 
 	obj, err = o.removeFinalizer(o.constructFinalizerKey(), maybeDeepCopy(obj, newObj))
+	if err == nil {
+		o.setFinalized(metadata.GetUID())
+	}
 	return obj, false, err
 }
 
+func (o *objectLifecycleAdapter) hasPendingFinalizers(finalizers []string) bool {
+	f, ok := o.lifecycle.(ObjectLifecycleFinalizer)
+	if !ok {
+		return false
+	}
+
+	for _, after := range f.FinalizeAfter() {
+		if slice.ContainsString(finalizers, after) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *objectLifecycleAdapter) isFinalized(uid types.UID) bool {
+	o.finalizedLock.Lock()
+	defer o.finalizedLock.Unlock()
+	_, ok := o.finalized[uid]
+	return ok
+}
+
+func (o *objectLifecycleAdapter) setFinalized(uid types.UID) {
+	o.finalizedLock.Lock()
+	defer o.finalizedLock.Unlock()
+
+	now := time.Now()
+	for k, t := range o.finalized {
+		if now.Sub(t) > finalizedTTL {
+			delete(o.finalized, k)
+		}
+	}
+	o.finalized[uid] = now
+}
+
 func maybeDeepCopy(old, newObj runtime.Object) runtime.Object {
 	if old == newObj {
 		return old.DeepCopyObject()
@@ -154,10 +234,10 @@ func (o *objectLifecycleAdapter) createKey() string {
 }
 
 func (o *objectLifecycleAdapter) constructFinalizerKey() string {
-	if o.clusterScoped {
-		return ScopedFinalizerKey + o.name
+	if f, ok := o.lifecycle.(ObjectLifecycleFinalizer); ok && f.FinalizerName() != "" {
+		return f.FinalizerName()
 	}
-	return finalizerKey + o.name
+	return FinalizerKey(o.name, o.clusterScoped)
 }
 
 func (o *objectLifecycleAdapter) hasFinalize() bool {
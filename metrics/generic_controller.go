@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"os"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -27,6 +28,25 @@ var (
 		},
 		[]string{"name", "handlerName", "key"},
 	)
+
+	TotalHandlerPanic = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "norman_generic_controller",
+			Name:      "total_handler_panic",
+			Help:      "Total Count of handler panics",
+		},
+		[]string{"name", "handlerName"},
+	)
+
+	HandlerExecutionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: "norman_generic_controller",
+			Name:      "handler_execution_duration_seconds",
+			Help:      "Latency of handler executions",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"name", "handlerName"},
+	)
 )
 
 func init() {
@@ -56,3 +76,23 @@ func IncTotalHandlerFailure(controllerName, handlerName, key string) {
 		).Inc()
 	}
 }
+
+func ObserveHandlerExecution(controllerName, handlerName string, start time.Time) {
+	if genericControllerMetrics {
+		HandlerExecutionDuration.With(
+			prometheus.Labels{
+				"name":        controllerName,
+				"handlerName": handlerName},
+		).Observe(time.Since(start).Seconds())
+	}
+}
+
+func IncTotalHandlerPanic(controllerName, handlerName string) {
+	if genericControllerMetrics {
+		TotalHandlerPanic.With(
+			prometheus.Labels{
+				"name":        controllerName,
+				"handlerName": handlerName},
+		).Inc()
+	}
+}
@@ -8,4 +8,14 @@ import (
 func init() {
 	prometheus.MustRegister(metrics.TotalHandlerExecution)
 	prometheus.MustRegister(metrics.TotalHandlerFailure)
+	prometheus.MustRegister(metrics.TotalHandlerPanic)
+	prometheus.MustRegister(metrics.HandlerExecutionDuration)
+	prometheus.MustRegister(metrics.QueueDepth)
+	prometheus.MustRegister(metrics.QueueAdds)
+	prometheus.MustRegister(metrics.QueueLatency)
+	prometheus.MustRegister(metrics.QueueWorkDuration)
+	prometheus.MustRegister(metrics.QueueRetries)
+	prometheus.MustRegister(metrics.StoreOperationDuration)
+	prometheus.MustRegister(metrics.StoreOperationFailure)
+	prometheus.MustRegister(metrics.TotalSubscribeDropped)
 }
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rancher/norman/httperror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const MetricsStoreEnv = "NORMAN_STORE_METRICS"
+
+var (
+	// storeMetrics is set to 1 once store metrics are enabled, it is read by every store operation
+	storeMetrics           int32
+	StoreOperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: "norman_store",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of store operations",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"resource", "verb"},
+	)
+
+	StoreOperationFailure = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "norman_store",
+			Name:      "total_operation_failure",
+			Help:      "Total Count of failed store operations by cause",
+		},
+		[]string{"resource", "verb", "cause"},
+	)
+)
+
+func init() {
+	if os.Getenv(MetricsStoreEnv) == "true" {
+		EnableStoreMetrics()
+	}
+}
+
+// EnableStoreMetrics records the store metrics, as if NORMAN_STORE_METRICS was set.
+func EnableStoreMetrics() {
+	atomic.StoreInt32(&storeMetrics, 1)
+}
+
+func ObserveStoreOperation(resource, verb string, start time.Time, err error) {
+	if atomic.LoadInt32(&storeMetrics) == 0 {
+		return
+	}
+
+	StoreOperationDuration.With(
+		prometheus.Labels{
+			"resource": resource,
+			"verb":     verb,
+		},
+	).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		StoreOperationFailure.With(
+			prometheus.Labels{
+				"resource": resource,
+				"verb":     verb,
+				"cause":    ErrorCause(err),
+			},
+		).Inc()
+	}
+}
+
+// ErrorCause classifies a store error as conflict, forbidden, notFound, timeout, validation or unknown. API errors
+// are classified by their status, falling back to their cause, which may be a Kubernetes or network error.
+func ErrorCause(err error) string {
+	if apiError, ok := err.(*httperror.APIError); ok {
+		switch status := apiError.Code.Status; {
+		case status == 409:
+			return "conflict"
+		case status == 401 || status == 403:
+			return "forbidden"
+		case status == 404:
+			return "notFound"
+		case status == 408 || status == 504:
+			return "timeout"
+		case status == 400 || status == 422:
+			return "validation"
+		}
+		if apiError.Cause == nil {
+			return "unknown"
+		}
+		err = apiError.Cause
+	}
+
+	err = errors.Cause(err)
+	switch {
+	case apierrors.IsConflict(err):
+		return "conflict"
+	case apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err):
+		return "forbidden"
+	case apierrors.IsNotFound(err):
+		return "notFound"
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err):
+		return "timeout"
+	case apierrors.IsInvalid(err) || apierrors.IsBadRequest(err):
+		return "validation"
+	}
+
+	if err == context.DeadlineExceeded {
+		return "timeout"
+	}
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return "timeout"
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/rancher/norman/httperror"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorCause(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"api conflict", httperror.NewAPIError(httperror.Conflict, "conflict"), "conflict"},
+		{"api not found", httperror.NewAPIError(httperror.NotFound, "missing"), "notFound"},
+		{"api validation", httperror.NewAPIError(httperror.InvalidBodyContent, "bad"), "validation"},
+		{"api server error", httperror.NewAPIError(httperror.ServerError, "timeout"), "unknown"},
+		{"wrapped kubernetes timeout", httperror.WrapAPIError(apierrors.NewTimeoutError("slow", 1), httperror.ServerError, "failed"), "timeout"},
+		{"kubernetes server timeout", apierrors.NewServerTimeout(pods, "list", 1), "timeout"},
+		{"kubernetes forbidden", apierrors.NewForbidden(pods, "a", errors.New("denied")), "forbidden"},
+		{"wrapped kubernetes conflict", pkgerrors.Wrap(apierrors.NewConflict(pods, "a", errors.New("changed")), "update"), "conflict"},
+		{"network timeout", &url.Error{Op: "Get", URL: "http://example.com", Err: timeoutError{}}, "timeout"},
+		{"deadline", context.DeadlineExceeded, "timeout"},
+		{"message only", errors.New("connection timeout"), "unknown"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ErrorCause(test.err), test.name)
+	}
+}
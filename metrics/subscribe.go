@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const MetricsSubscribeEnv = "NORMAN_SUBSCRIBE_METRICS"
+
+var (
+	subscribeMetrics      = false
+	TotalSubscribeDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "norman_subscribe",
+			Name:      "total_dropped_events",
+			Help:      "Total Count of events dropped or connections closed for slow subscribers",
+		},
+		[]string{"policy"},
+	)
+)
+
+func init() {
+	if os.Getenv(MetricsSubscribeEnv) == "true" {
+		subscribeMetrics = true
+	}
+}
+
+func IncSubscribeDropped(policy string) {
+	if subscribeMetrics {
+		TotalSubscribeDropped.With(prometheus.Labels{"policy": policy}).Inc()
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	QueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "norman_workqueue",
+			Name:      "depth",
+			Help:      "Current number of items waiting in the queue of a controller",
+		},
+		[]string{"name"},
+	)
+
+	QueueAdds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "norman_workqueue",
+			Name:      "adds_total",
+			Help:      "Total number of items added to the queue of a controller",
+		},
+		[]string{"name"},
+	)
+
+	QueueLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: "norman_workqueue",
+			Name:      "queue_duration_seconds",
+			Help:      "How long items wait in the queue of a controller before being processed",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+		[]string{"name"},
+	)
+
+	QueueWorkDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: "norman_workqueue",
+			Name:      "work_duration_seconds",
+			Help:      "How long processing an item of a controller takes",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+		[]string{"name"},
+	)
+
+	QueueRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "norman_workqueue",
+			Name:      "retries_total",
+			Help:      "Total number of retries of items of a controller",
+		},
+		[]string{"name"},
+	)
+)
+
+// WorkqueueMetricsProvider reports the workqueue metrics of controllers to prometheus.
+type WorkqueueMetricsProvider struct{}
+
+func (WorkqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return QueueDepth.WithLabelValues(name)
+}
+
+func (WorkqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return QueueAdds.WithLabelValues(name)
+}
+
+func (WorkqueueMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return microseconds{QueueLatency.WithLabelValues(name)}
+}
+
+func (WorkqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return microseconds{QueueWorkDuration.WithLabelValues(name)}
+}
+
+func (WorkqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return QueueRetries.WithLabelValues(name)
+}
+
+// microseconds converts the durations the workqueue reports in microseconds to seconds.
+type microseconds struct {
+	observer prometheus.Observer
+}
+
+func (m microseconds) Observe(value float64) {
+	m.observer.Observe(value / 1e6)
+}
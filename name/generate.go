@@ -0,0 +1,145 @@
+package name
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+var lowerChars = regexp.MustCompile("[a-z]+")
+
+// Generator generates names for objects of a type created without one.
+type Generator interface {
+	Generate(typeName string) (string, error)
+}
+
+type GeneratorFunc func(typeName string) (string, error)
+
+func (g GeneratorFunc) Generate(typeName string) (string, error) {
+	return g(typeName)
+}
+
+// Abbreviate shortens a type name to its first letter followed by its upper case letters, for example
+// clusterRoleTemplateBinding becomes crtb.
+func Abbreviate(typeName string) string {
+	if typeName == "" {
+		return typeName
+	}
+	return strings.ToLower(typeName[0:1] + lowerChars.ReplaceAllString(typeName[1:], ""))
+}
+
+// Random generates "<prefix>-<suffix>" names with a random suffix of Length characters, 5 if unset. The prefix
+// defaults to the abbreviated type name.
+type Random struct {
+	Prefix string
+	Length int
+}
+
+func (r Random) Generate(typeName string) (string, error) {
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = Abbreviate(typeName)
+	}
+	length := r.Length
+	if length <= 0 {
+		length = 5
+	}
+	return fmt.Sprintf("%s-%s", prefix, utilrand.String(length)), nil
+}
+
+var (
+	adjectives = []string{
+		"autumn", "bold", "brave", "calm", "cold", "crimson", "damp", "dark", "eager", "falling", "fragrant",
+		"gentle", "green", "hidden", "holy", "icy", "late", "lively", "misty", "muddy", "nameless", "old",
+		"patient", "proud", "quiet", "red", "restless", "shy", "silent", "snowy", "solitary", "spring", "still",
+		"summer", "twilight", "wandering", "weathered", "wild", "winter", "young",
+	}
+	nouns = []string{
+		"bird", "breeze", "brook", "cloud", "darkness", "dawn", "dew", "dream", "dust", "feather", "field", "fire",
+		"flower", "fog", "forest", "frost", "glade", "grass", "haze", "hill", "lake", "leaf", "meadow", "moon",
+		"morning", "mountain", "night", "paper", "pine", "pond", "rain", "resonance", "river", "sea", "shadow",
+		"sky", "smoke", "snow", "star", "sun", "sunset", "surf", "thunder", "tree", "violet", "water", "wave",
+		"wind", "wood",
+	}
+)
+
+// WordPair generates memorable "<adjective>-<noun>-<digits>" names with Digits random digits, 4 if unset.
+type WordPair struct {
+	Digits int
+}
+
+func (w WordPair) Generate(typeName string) (string, error) {
+	digits := w.Digits
+	if digits <= 0 {
+		digits = 4
+	}
+	number := make([]byte, digits)
+	for i := range number {
+		number[i] = byte('0' + rand.Intn(10))
+	}
+	return fmt.Sprintf("%s-%s-%s", adjectives[rand.Intn(len(adjectives))], nouns[rand.Intn(len(nouns))], number), nil
+}
+
+// Sequence generates "<prefix>-<n>" names with increasing numbers per type. The prefix defaults to the abbreviated
+// type name. Next returns the next number of a type and can be backed by a persistent counter, by default the
+// numbers are counted in memory starting at 1.
+type Sequence struct {
+	Prefix string
+	Next   func(typeName string) (int64, error)
+
+	lock     sync.Mutex
+	counters map[string]int64
+}
+
+func (s *Sequence) Generate(typeName string) (string, error) {
+	next, err := s.next(typeName)
+	if err != nil {
+		return "", err
+	}
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = Abbreviate(typeName)
+	}
+	return fmt.Sprintf("%s-%d", prefix, next), nil
+}
+
+func (s *Sequence) next(typeName string) (int64, error) {
+	if s.Next != nil {
+		return s.Next(typeName)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.counters == nil {
+		s.counters = map[string]int64{}
+	}
+	s.counters[typeName]++
+	return s.counters[typeName], nil
+}
+
+// Unique generates names with g until exists reports one as free, giving up after attempts tries. A nil exists
+// accepts the first name.
+func Unique(g Generator, typeName string, attempts int, exists func(name string) (bool, error)) (string, error) {
+	for i := 0; i < attempts; i++ {
+		name, err := g.Generate(typeName)
+		if err != nil {
+			return "", err
+		}
+		if exists == nil {
+			return name, nil
+		}
+		taken, err := exists(name)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return name, nil
+		}
+	}
+	return "", errors.Errorf("failed to generate a unique %s name after %d attempts", typeName, attempts)
+}
@@ -0,0 +1,39 @@
+package name
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerators(t *testing.T) {
+	generated, _ := Random{}.Generate("clusterRoleTemplateBinding")
+	assert.Regexp(t, regexp.MustCompile("^crtb-[a-z0-9]{5}$"), generated)
+
+	generated, _ = Random{Prefix: "node", Length: 8}.Generate("cluster")
+	assert.Regexp(t, regexp.MustCompile("^node-[a-z0-9]{8}$"), generated)
+
+	generated, _ = WordPair{}.Generate("cluster")
+	assert.Regexp(t, regexp.MustCompile("^[a-z]+-[a-z]+-[0-9]{4}$"), generated)
+
+	sequence := &Sequence{}
+	generated, _ = sequence.Generate("project")
+	assert.Equal(t, "p-1", generated)
+	generated, _ = sequence.Generate("project")
+	assert.Equal(t, "p-2", generated)
+}
+
+func TestUnique(t *testing.T) {
+	taken := map[string]bool{"p-1": true, "p-2": true}
+	exists := func(name string) (bool, error) {
+		return taken[name], nil
+	}
+
+	generated, err := Unique(&Sequence{}, "project", 5, exists)
+	assert.NoError(t, err)
+	assert.Equal(t, "p-3", generated)
+
+	_, err = Unique(&Sequence{}, "project", 2, exists)
+	assert.Error(t, err)
+}
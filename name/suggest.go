@@ -0,0 +1,77 @@
+package name
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest returns up to max candidates that are within a small edit distance of name, closest first.
+func Suggest(name string, candidates []string, max int) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+
+	threshold := len(name)/3 + 1
+	var matches []match
+	for _, candidate := range candidates {
+		d := distance(strings.ToLower(name), strings.ToLower(candidate))
+		if d <= threshold {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance == matches[j].distance {
+			return matches[i].name < matches[j].name
+		}
+		return matches[i].distance < matches[j].distance
+	})
+
+	var result []string
+	for i := 0; i < len(matches) && i < max; i++ {
+		result = append(result, matches[i].name)
+	}
+	return result
+}
+
+// DidYouMean formats the suggestions for name as a message suffix, or returns "" if there are none.
+func DidYouMean(name string, candidates []string) string {
+	suggestions := Suggest(name, candidates, 3)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return ", did you mean " + strings.Join(suggestions, ", ") + "?"
+}
+
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,17 @@
+package name
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"cluster", "clusters", "project", "node", "nodePool"}
+
+	assert.Equal(t, []string{"cluster", "clusters"}, Suggest("clustr", candidates, 3))
+	assert.Equal(t, []string{"node"}, Suggest("nod", candidates, 3))
+	assert.Nil(t, Suggest("secret", candidates, 3))
+	assert.Equal(t, "", DidYouMean("secret", candidates))
+	assert.Equal(t, ", did you mean project?", DidYouMean("projet", candidates))
+}
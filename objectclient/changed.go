@@ -0,0 +1,73 @@
+package objectclient
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// metadataNoise are the metadata fields set by the server that never make an update necessary.
+var metadataNoise = []string{
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+	"selfLink",
+	"uid",
+	"managedFields",
+}
+
+// Changed returns true if updating existing to desired would change it, ignoring the status and the metadata set
+// by the server.
+func Changed(existing, desired runtime.Object) (bool, error) {
+	existingData, err := comparable(existing)
+	if err != nil {
+		return false, err
+	}
+	desiredData, err := comparable(desired)
+	if err != nil {
+		return false, err
+	}
+	return !reflect.DeepEqual(existingData, desiredData), nil
+}
+
+func comparable(obj runtime.Object) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		data = runtime.DeepCopyJSON(u.Object)
+	} else {
+		var err error
+		data, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	delete(data, "status")
+	if metadata, ok := data["metadata"].(map[string]interface{}); ok {
+		for _, key := range metadataNoise {
+			delete(metadata, key)
+		}
+		for _, key := range []string{"labels", "annotations"} {
+			if m, ok := metadata[key].(map[string]interface{}); ok && len(m) == 0 {
+				delete(metadata, key)
+			}
+		}
+	}
+	return data, nil
+}
+
+// UpdateIfChanged updates the object to desired unless that would not change existing, see Changed. Existing is
+// returned if nothing changed.
+func (p *ObjectClient) UpdateIfChanged(name string, existing, desired runtime.Object) (runtime.Object, error) {
+	changed, err := Changed(existing, desired)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return existing, nil
+	}
+	return p.Update(name, desired)
+}
@@ -0,0 +1,43 @@
+package objectclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestChanged(t *testing.T) {
+	existing := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "config",
+			Namespace:       "default",
+			ResourceVersion: "10",
+			UID:             "1234",
+		},
+		Data: map[string]string{
+			"key": "value",
+		},
+	}
+
+	desired := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "config",
+			Namespace:   "default",
+			Annotations: map[string]string{},
+		},
+		Data: map[string]string{
+			"key": "value",
+		},
+	}
+
+	changed, err := Changed(existing, desired)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	desired.Data["key"] = "other"
+	changed, err = Changed(existing, desired)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+}
@@ -3,9 +3,11 @@ package builder
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/name"
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/types/convert"
 	"github.com/rancher/norman/types/definition"
@@ -21,6 +23,10 @@ var (
 	ErrComplexType = errors.New("complex type")
 )
 
+// StrictFieldsHeader set to "true" rejects request bodies with fields unknown to the schema, as if every schema
+// had StrictFields set.
+const StrictFieldsHeader = "X-API-Strict-Fields"
+
 type Operation string
 
 func (o Operation) IsList() bool {
@@ -35,6 +41,8 @@ type Builder struct {
 	edit         bool
 	export       bool
 	yaml         bool
+	strict       bool
+	depth        int
 }
 
 func NewBuilder(apiRequest *types.APIContext) *Builder {
@@ -43,6 +51,7 @@ func NewBuilder(apiRequest *types.APIContext) *Builder {
 		yaml:         apiRequest.ResponseFormat == "yaml",
 		edit:         apiRequest.Option("edit") == "true",
 		export:       apiRequest.Option("export") == "true",
+		strict:       apiRequest.Request != nil && apiRequest.Request.Header.Get(StrictFieldsHeader) == "true",
 		Version:      apiRequest.Version,
 		Schemas:      apiRequest.Schemas,
 		RefValidator: apiRequest.ReferenceValidator,
@@ -50,6 +59,12 @@ func NewBuilder(apiRequest *types.APIContext) *Builder {
 }
 
 func (b *Builder) Construct(schema *types.Schema, input map[string]interface{}, op Operation) (map[string]interface{}, error) {
+	if b.depth == 0 && (op == Create || op == Update) {
+		if err := b.checkUnknownFields(schema, input); err != nil {
+			return nil, err
+		}
+	}
+
 	result, err := b.copyFields(schema, input, op)
 	if err != nil {
 		return nil, err
@@ -419,6 +434,10 @@ func (b *Builder) convertType(fieldType string, value interface{}, op Operation)
 		return nil, httperror.NewAPIError(httperror.InvalidFormat, fmt.Sprintf("Value can not be converted to type %s: %v", fieldType, value))
 	}
 
+	b.depth++
+	defer func() {
+		b.depth--
+	}()
 	return b.Construct(schema, mapValue, op)
 }
 
@@ -500,3 +519,105 @@ func fieldMatchesOp(field types.Field, op Operation) bool {
 		return false
 	}
 }
+
+func ignoredUnknownField(schema *types.Schema, fieldName string) bool {
+	switch fieldName {
+	case "type", "id", "baseType", "links", "actions":
+		return true
+	}
+	if strings.HasSuffix(fieldName, "TS") {
+		_, ok := schema.ResourceFields[strings.TrimSuffix(fieldName, "TS")]
+		return ok
+	}
+	return false
+}
+
+// checkUnknownFields rejects input if it has fields unknown to a schema with StrictFields, or to any schema if the
+// request asked for strict fields, listing the paths of all of them.
+func (b *Builder) checkUnknownFields(schema *types.Schema, input map[string]interface{}) error {
+	unknown := b.unknownFields(schema, input, "")
+	switch len(unknown) {
+	case 0:
+		return nil
+	case 1:
+		return httperror.NewFieldAPIError(httperror.InvalidBodyContent, unknown[0].path,
+			"unknown field "+unknown[0].path+name.DidYouMean(unknown[0].name, fieldNames(unknown[0].schema)))
+	}
+
+	var paths []string
+	for _, field := range unknown {
+		paths = append(paths, field.path)
+	}
+	sort.Strings(paths)
+	return httperror.NewFieldAPIError(httperror.InvalidBodyContent, paths[0], "unknown fields "+strings.Join(paths, ", "))
+}
+
+type unknownField struct {
+	schema *types.Schema
+	name   string
+	path   string
+}
+
+func (b *Builder) unknownFields(schema *types.Schema, input map[string]interface{}, prefix string) []unknownField {
+	var result []unknownField
+	for fieldName, value := range input {
+		path := fieldName
+		if prefix != "" {
+			path = prefix + "." + fieldName
+		}
+
+		field, ok := schema.ResourceFields[fieldName]
+		if !ok {
+			if (b.strict || schema.StrictFields) && !ignoredUnknownField(schema, fieldName) {
+				result = append(result, unknownField{
+					schema: schema,
+					name:   fieldName,
+					path:   path,
+				})
+			}
+			continue
+		}
+		result = append(result, b.unknownValueFields(field.Type, value, path)...)
+	}
+	return result
+}
+
+func (b *Builder) unknownValueFields(fieldType string, value interface{}, path string) []unknownField {
+	switch {
+	case value == nil || definition.IsReferenceType(fieldType):
+		return nil
+	case definition.IsMapType(fieldType):
+		var result []unknownField
+		mapValue, _ := value.(map[string]interface{})
+		for key, value := range mapValue {
+			result = append(result, b.unknownValueFields(definition.SubType(fieldType), value, path+"."+key)...)
+		}
+		return result
+	case definition.IsArrayType(fieldType):
+		var result []unknownField
+		sliceValue, _ := value.([]interface{})
+		for i, value := range sliceValue {
+			result = append(result, b.unknownValueFields(definition.SubType(fieldType), value,
+				fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return result
+	}
+
+	mapValue, ok := value.(map[string]interface{})
+	if !ok || b.Schemas == nil {
+		return nil
+	}
+	schema := b.Schemas.Schema(b.Version, fieldType)
+	if schema == nil {
+		return nil
+	}
+	return b.unknownFields(schema, mapValue, path)
+}
+
+func fieldNames(schema *types.Schema) []string {
+	var names []string
+	for name := range schema.ResourceFields {
+		names = append(names, name)
+	}
+	return names
+}
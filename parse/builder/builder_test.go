@@ -1,6 +1,8 @@
 package builder
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/rancher/norman/types"
@@ -42,3 +44,51 @@ func TestEmptyStringWithDefault(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "foo", value)
 }
+
+type strictPort struct {
+	Port int64 `json:"port"`
+}
+
+type strictSpec struct {
+	Ports  []strictPort `json:"ports"`
+	Labels map[string]string
+}
+
+type strictObject struct {
+	Name string     `json:"name"`
+	Spec strictSpec `json:"spec"`
+}
+
+func TestStrictFields(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().MustImport(&version, strictObject{})
+	schema := schemas.Schema(&version, "strictObject")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/strictobjects", nil)
+	req.Header.Set(StrictFieldsHeader, "true")
+	builder := NewBuilder(&types.APIContext{Request: req, Version: &version, Schemas: schemas})
+
+	input := map[string]interface{}{
+		"name": "test",
+		"nmae": "test",
+		"spec": map[string]interface{}{
+			"ports": []interface{}{
+				map[string]interface{}{"port": 80},
+				map[string]interface{}{"prot": 443},
+			},
+		},
+	}
+	_, err := builder.Construct(schema, input, Create)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unknown fields nmae, spec.ports[1].prot")
+	}
+
+	delete(input, "nmae")
+	_, err = builder.Construct(schema, input, Create)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unknown field spec.ports[1].prot, did you mean port?")
+	}
+
+	_, err = NewBuilder(&types.APIContext{Version: &version, Schemas: schemas}).Construct(schema, input, Create)
+	assert.NoError(t, err)
+}
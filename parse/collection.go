@@ -22,7 +22,7 @@ func QueryOptions(apiContext *types.APIContext, schema *types.Schema) types.Quer
 	result := &types.QueryOptions{}
 
 	result.Sort = parseSort(schema, apiContext)
-	result.Pagination = parsePagination(apiContext)
+	result.Pagination = parsePagination(apiContext, schema)
 	result.Conditions = parseFilters(schema, apiContext)
 
 	return *result
@@ -47,7 +47,7 @@ func parseSort(schema *types.Schema, apiContext *types.APIContext) types.Sort {
 	}
 }
 
-func parsePagination(apiContext *types.APIContext) *types.Pagination {
+func parsePagination(apiContext *types.APIContext, schema *types.Schema) *types.Pagination {
 	if apiContext.Pagination != nil {
 		return apiContext.Pagination
 	}
@@ -56,8 +56,19 @@ func parsePagination(apiContext *types.APIContext) *types.Pagination {
 	limit := q.Get("limit")
 	marker := q.Get("marker")
 
+	schemaDefault, schemaMax := defaultLimit, maxLimit
+	if schema != nil && schema.DefaultLimit > 0 {
+		schemaDefault = schema.DefaultLimit
+	}
+	if schema != nil && schema.MaxLimit > 0 {
+		schemaMax = schema.MaxLimit
+	}
+	if schemaDefault > schemaMax {
+		schemaDefault = schemaMax
+	}
+
 	result := &types.Pagination{
-		Limit:  &defaultLimit,
+		Limit:  &schemaDefault,
 		Marker: marker,
 	}
 
@@ -67,8 +78,9 @@ func parsePagination(apiContext *types.APIContext) *types.Pagination {
 			return result
 		}
 
-		if limitInt > maxLimit || limitInt == -1 {
-			result.Limit = &maxLimit
+		if limitInt > schemaMax || limitInt == -1 {
+			result.Limit = &schemaMax
+			result.LimitExceeded = true
 		} else if limitInt >= 0 {
 			result.Limit = &limitInt
 		}
@@ -94,7 +106,7 @@ func parseFilters(schema *types.Schema, apiContext *types.APIContext) []*types.Q
 	var conditions []*types.QueryCondition
 	for key, values := range apiContext.Query {
 		name, op := parseNameAndOp(key)
-		filter, ok := schema.CollectionFilters[name]
+		filter, ok := collectionFilter(apiContext.Schemas, schema, name)
 		if !ok {
 			continue
 		}
@@ -108,5 +120,9 @@ func parseFilters(schema *types.Schema, apiContext *types.APIContext) []*types.Q
 		}
 	}
 
+	if filters, err := parseQueryFilters(apiContext, schema); err == nil {
+		conditions = append(conditions, filters...)
+	}
+
 	return conditions
 }
@@ -0,0 +1,337 @@
+package parse
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/definition"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// FilterParam is the query parameter holding a filter expression in the language given by FilterLanguageParam.
+	FilterParam         = "filter"
+	FilterLanguageParam = "filterLanguage"
+	// LabelSelectorParam filters by labels with the Kubernetes selector syntax. Stores of Kubernetes resources
+	// also pass it on to the Kubernetes API.
+	LabelSelectorParam = "labelSelector"
+
+	// DefaultFilterLanguage combines terms like the filter query parameters with AND, OR and parentheses, for
+	// example "(state=error OR state=failed) AND name_ne=test". AND binds tighter than OR. Values containing
+	// spaces or parentheses are double quoted.
+	DefaultFilterLanguage = "simple"
+)
+
+// FilterLanguage compiles a filter expression into a condition evaluated against the objects of a collection.
+type FilterLanguage func(schemas *types.Schemas, schema *types.Schema, expression string) (*types.QueryCondition, error)
+
+var (
+	filterLanguagesLock sync.RWMutex
+	filterLanguages     = map[string]FilterLanguage{
+		DefaultFilterLanguage: ParseFilterExpression,
+	}
+)
+
+// RegisterFilterLanguage makes language selectable with the filterLanguage query parameter. Only the simple
+// language is built in, CEL or other languages are left to applications that bring their own evaluator.
+func RegisterFilterLanguage(name string, language FilterLanguage) {
+	filterLanguagesLock.Lock()
+	defer filterLanguagesLock.Unlock()
+	filterLanguages[name] = language
+}
+
+func filterLanguage(name string) (FilterLanguage, error) {
+	filterLanguagesLock.RLock()
+	defer filterLanguagesLock.RUnlock()
+
+	language, ok := filterLanguages[name]
+	if ok {
+		return language, nil
+	}
+
+	var names []string
+	for registered := range filterLanguages {
+		names = append(names, registered)
+	}
+	sort.Strings(names)
+	return nil, httperror.NewAPIError(httperror.InvalidOption, "unknown filter language "+name+", supported: "+strings.Join(names, ", "))
+}
+
+// ValidateFilter checks the filter expression and label selector of the request, which QueryOptions silently
+// ignores if invalid. Handlers listing or watching a collection call it before QueryOptions.
+func ValidateFilter(apiContext *types.APIContext, schema *types.Schema) error {
+	_, err := parseQueryFilters(apiContext, schema)
+	return err
+}
+
+func parseQueryFilters(apiContext *types.APIContext, schema *types.Schema) ([]*types.QueryCondition, error) {
+	if schema == nil {
+		return nil, nil
+	}
+
+	var conditions []*types.QueryCondition
+	if expression := apiContext.Query.Get(FilterParam); expression != "" {
+		languageName := apiContext.Query.Get(FilterLanguageParam)
+		if languageName == "" {
+			languageName = DefaultFilterLanguage
+		}
+		language, err := filterLanguage(languageName)
+		if err != nil {
+			return nil, err
+		}
+		cond, err := language(apiContext.Schemas, schema, expression)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+	}
+
+	if selector := apiContext.Query.Get(LabelSelectorParam); selector != "" {
+		cond, err := labelSelectorCondition(selector)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+	}
+
+	return conditions, nil
+}
+
+func labelSelectorCondition(selector string) (*types.QueryCondition, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, httperror.WrapAPIError(err, httperror.InvalidFormat, "invalid "+LabelSelectorParam)
+	}
+
+	return types.NewPredicateCondition(LabelSelectorParam, types.ModifierType("selector"), selector,
+		func(data map[string]interface{}) bool {
+			set := labels.Set{}
+			for k, v := range convert.ToMapInterface(data["labels"]) {
+				set[k] = convert.ToString(v)
+			}
+			return parsed.Matches(set)
+		}), nil
+}
+
+// ParseFilterExpression compiles an expression of the DefaultFilterLanguage into a condition tree over the
+// collection filters of schema and, if schemas is set, its nested fields.
+func ParseFilterExpression(schemas *types.Schemas, schema *types.Schema, expression string) (*types.QueryCondition, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{
+		schemas: schemas,
+		schema:  schema,
+		tokens:  tokens,
+	}
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, invalidFilter("unexpected " + p.tokens[p.pos])
+	}
+	return cond, nil
+}
+
+type filterParser struct {
+	schemas *types.Schemas
+	schema  *types.Schema
+	tokens  []string
+	pos     int
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) or() (*types.QueryCondition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = types.Or(left, right)
+	}
+	return left, nil
+}
+
+func (p *filterParser) and() (*types.QueryCondition, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.pos++
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		left = types.And(left, right)
+	}
+	return left, nil
+}
+
+func (p *filterParser) primary() (*types.QueryCondition, error) {
+	token := p.peek()
+	p.pos++
+
+	switch {
+	case token == "":
+		return nil, invalidFilter("unexpected end of expression")
+	case token == "(":
+		cond, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, invalidFilter("missing )")
+		}
+		p.pos++
+		return cond, nil
+	case token == ")" || strings.EqualFold(token, "and") || strings.EqualFold(token, "or"):
+		return nil, invalidFilter("unexpected " + token)
+	}
+
+	return p.term(token)
+}
+
+func (p *filterParser) term(token string) (*types.QueryCondition, error) {
+	key, value := token, ""
+	hasValue := false
+	if idx := strings.Index(token, "="); idx >= 0 {
+		key, value, hasValue = token[:idx], token[idx+1:], true
+	}
+
+	name, op := parseNameAndOp(key)
+	filter, ok := collectionFilter(p.schemas, p.schema, name)
+	if !ok {
+		return nil, invalidFilter("unknown filter " + name)
+	}
+	if !types.ValidMod(op) || !hasModifier(filter, op) {
+		return nil, invalidFilter("invalid modifier " + string(op) + " for filter " + name)
+	}
+
+	if !hasValue {
+		return types.NewConditionFromString(name, op), nil
+	}
+	if op == types.ModifierIn || op == types.ModifierNotIn {
+		return types.NewConditionFromString(name, op, strings.Split(value, ",")...), nil
+	}
+	return types.NewConditionFromString(name, op, value), nil
+}
+
+// nestedFilterModifiers are the modifiers of filters on nested fields.
+var nestedFilterModifiers = []types.ModifierType{
+	types.ModifierEQ,
+	types.ModifierNE,
+	types.ModifierIn,
+	types.ModifierNotIn,
+	types.ModifierNull,
+	types.ModifierNotNull,
+}
+
+// collectionFilter returns the filter of name, which is either a collection filter of schema or a path of nested
+// fields like spec.nodePool.name. Arrays along the path match if any element does, maps take the key as the next
+// path element.
+func collectionFilter(schemas *types.Schemas, schema *types.Schema, name string) (types.Filter, bool) {
+	if filter, ok := schema.CollectionFilters[name]; ok {
+		return filter, true
+	}
+	if schemas == nil || !strings.Contains(name, ".") {
+		return types.Filter{}, false
+	}
+
+	parts := strings.Split(name, ".")
+	current := schema
+	for i := 0; i < len(parts); i++ {
+		if current == nil {
+			return types.Filter{}, false
+		}
+		field, ok := current.ResourceFields[parts[i]]
+		if !ok {
+			return types.Filter{}, false
+		}
+
+		fieldType := field.Type
+		for definition.IsArrayType(fieldType) || definition.IsMapType(fieldType) {
+			if definition.IsMapType(fieldType) {
+				i++
+				if i >= len(parts) {
+					return types.Filter{}, false
+				}
+			}
+			fieldType = definition.SubType(fieldType)
+		}
+		current = schemas.Schema(&schema.Version, fieldType)
+	}
+
+	return types.Filter{
+		Modifiers: nestedFilterModifiers,
+	}, true
+}
+
+func hasModifier(filter types.Filter, op types.ModifierType) bool {
+	for _, mod := range filter.Modifiers {
+		if mod == op {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits expression at spaces and parentheses, keeping double quoted parts of terms together.
+func tokenize(expression string) ([]string, error) {
+	var (
+		tokens []string
+		term   strings.Builder
+		quoted bool
+	)
+
+	flush := func() {
+		if term.Len() > 0 {
+			tokens = append(tokens, term.String())
+			term.Reset()
+		}
+	}
+
+	for _, c := range expression {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+			term.WriteRune(c)
+		case c == '(' || c == ')':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			term.WriteRune(c)
+		}
+	}
+
+	if quoted {
+		return nil, invalidFilter("unterminated quote")
+	}
+	flush()
+	return tokens, nil
+}
+
+func invalidFilter(msg string) error {
+	return httperror.NewAPIError(httperror.InvalidFormat, "invalid filter expression: "+msg)
+}
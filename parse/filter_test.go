@@ -0,0 +1,135 @@
+package parse
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	mods := []types.ModifierType{types.ModifierEQ, types.ModifierNE, types.ModifierIn}
+	schema := &types.Schema{
+		CollectionFilters: map[string]types.Filter{
+			"state": {Modifiers: mods},
+			"name":  {Modifiers: mods},
+		},
+	}
+
+	cond, err := ParseFilterExpression(nil, schema, `(state=error OR state=failed) AND name_ne="my test"`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, cond.Valid(schema, map[string]interface{}{"state": "failed", "name": "prod"}))
+	assert.False(t, cond.Valid(schema, map[string]interface{}{"state": "failed", "name": "my test"}))
+	assert.False(t, cond.Valid(schema, map[string]interface{}{"state": "active", "name": "prod"}))
+
+	cond, err = ParseFilterExpression(nil, schema, "state=active or name_in=a,b and state=error")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, cond.Valid(schema, map[string]interface{}{"state": "active", "name": "c"}))
+	assert.True(t, cond.Valid(schema, map[string]interface{}{"state": "error", "name": "b"}))
+	assert.False(t, cond.Valid(schema, map[string]interface{}{"state": "failed", "name": "b"}))
+
+	for _, invalid := range []string{"state=error OR", "(state=error", "foo=bar", "state_null", `name="x`} {
+		_, err := ParseFilterExpression(nil, schema, invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+type nodePool struct {
+	Name string `json:"name"`
+}
+
+type clusterSpec struct {
+	NodePool nodePool          `json:"nodePool"`
+	Labels   map[string]string `json:"labels"`
+}
+
+type clusterCondition struct {
+	Type string `json:"type"`
+}
+
+type clusterStatus struct {
+	Conditions []clusterCondition `json:"conditions"`
+}
+
+type cluster struct {
+	types.Resource
+	Spec   clusterSpec   `json:"spec"`
+	Status clusterStatus `json:"status"`
+}
+
+func TestNestedFilters(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().MustImport(&version, cluster{})
+	schema := schemas.Schema(&version, "cluster")
+
+	for _, valid := range []string{"spec.nodePool.name", "status.conditions.type", "spec.labels.app"} {
+		_, ok := collectionFilter(schemas, schema, valid)
+		assert.True(t, ok, valid)
+	}
+	for _, invalid := range []string{"spec.nodePool.size", "spec.labels", "status.conditions.type.name"} {
+		_, ok := collectionFilter(schemas, schema, invalid)
+		assert.False(t, ok, invalid)
+	}
+
+	cond, err := ParseFilterExpression(schemas, schema, "status.conditions.type=Ready AND spec.nodePool.name_ne=foo")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, cond.Valid(schema, map[string]interface{}{
+		"spec": map[string]interface{}{"nodePool": map[string]interface{}{"name": "bar"}},
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Provisioned"},
+			map[string]interface{}{"type": "Ready"},
+		}},
+	}))
+	assert.False(t, cond.Valid(schema, map[string]interface{}{
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Provisioned"},
+		}},
+	}))
+}
+
+func TestQueryFilters(t *testing.T) {
+	schema := &types.Schema{
+		CollectionFilters: map[string]types.Filter{
+			"state": {Modifiers: []types.ModifierType{types.ModifierEQ}},
+		},
+	}
+	apiContext := &types.APIContext{
+		Query: url.Values{
+			FilterParam:        {"state=active"},
+			LabelSelectorParam: {"app=web,tier!=db"},
+		},
+	}
+
+	conditions, err := parseQueryFilters(apiContext, schema)
+	if !assert.NoError(t, err) || !assert.Len(t, conditions, 2) {
+		return
+	}
+	selector := conditions[1]
+	assert.True(t, selector.Valid(schema, map[string]interface{}{"labels": map[string]interface{}{"app": "web"}}))
+	assert.False(t, selector.Valid(schema, map[string]interface{}{"labels": map[string]interface{}{"app": "web", "tier": "db"}}))
+
+	apiContext.Query.Set(FilterLanguageParam, "cel")
+	assert.Error(t, ValidateFilter(apiContext, schema))
+
+	RegisterFilterLanguage("cel", func(schemas *types.Schemas, schema *types.Schema, expression string) (*types.QueryCondition, error) {
+		return types.NewPredicateCondition("", "cel", expression, func(data map[string]interface{}) bool {
+			return true
+		}), nil
+	})
+	defer func() {
+		filterLanguagesLock.Lock()
+		delete(filterLanguages, "cel")
+		filterLanguagesLock.Unlock()
+	}()
+	assert.NoError(t, ValidateFilter(apiContext, schema))
+
+	apiContext.Query.Set(LabelSelectorParam, "app in (")
+	assert.Error(t, ValidateFilter(apiContext, schema))
+}
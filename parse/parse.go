@@ -8,22 +8,29 @@ import (
 
 	"sort"
 
+	"github.com/pborman/uuid"
 	"github.com/rancher/norman/api/builtin"
 	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/name"
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/urlbuilder"
 )
 
 const (
 	maxFormSize = 2 * 1 << 20
+
+	APIVersionHeader = "X-API-Version"
 )
 
 var (
 	multiSlashRegexp = regexp.MustCompile("//+")
 	allowedFormats   = map[string]bool{
-		"html": true,
-		"json": true,
-		"yaml": true,
+		"html":   true,
+		"json":   true,
+		"yaml":   true,
+		"ndjson": true,
+		"csv":    true,
+		"xlsx":   true,
 	}
 )
 
@@ -75,6 +82,8 @@ func Parse(rw http.ResponseWriter, req *http.Request, schemas *types.Schemas, ur
 	result := types.NewAPIContext(req, rw, schemas)
 	result.Method = parseMethod(req)
 	result.ResponseFormat = parseResponseFormat(req)
+	result.RequestID = parseRequestID(req)
+	rw.Header().Set(types.RequestIDHeader, result.RequestID)
 	result.URLBuilder, _ = urlbuilder.New(req, types.APIVersion{}, schemas)
 
 	// The response format is guarenteed to be set even in the event of an error
@@ -98,6 +107,12 @@ func Parse(rw http.ResponseWriter, req *http.Request, schemas *types.Schemas, ur
 		return result, err
 	}
 
+	if result.Version != nil && !fixedVersion(parsedURL) {
+		if err := negotiateVersion(req, result); err != nil {
+			return result, err
+		}
+	}
+
 	if result.Version == nil {
 		result.Method = http.MethodGet
 		result.URLBuilder, err = urlbuilder.New(req, types.APIVersion{}, result.Schemas)
@@ -121,7 +136,8 @@ func Parse(rw http.ResponseWriter, req *http.Request, schemas *types.Schemas, ur
 
 	if result.Schema == nil {
 		if result.Type != "" {
-			err = httperror.NewAPIError(httperror.NotFound, "failed to find schema "+result.Type)
+			err = httperror.NewAPIError(httperror.NotFound, "failed to find schema "+result.Type+
+				name.DidYouMean(result.Type, schemaNames(result.Schemas, result.Version)))
 		}
 		result.Method = http.MethodGet
 		result.Type = "apiRoot"
@@ -139,6 +155,39 @@ func Parse(rw http.ResponseWriter, req *http.Request, schemas *types.Schemas, ur
 	return result, nil
 }
 
+// fixedVersion is true if the path addresses a subcontext, whose attributes and schemas only exist in the version
+// of the path, so the version can't be negotiated.
+func fixedVersion(parsedURL ParsedURL) bool {
+	return parsedURL.Version.SubContext || parsedURL.SchemasVersion != nil || parsedURL.SubContextPrefix != "" ||
+		len(parsedURL.SubContext) > 0
+}
+
+// negotiateVersion allows the X-API-Version header to select the registered version that serves the request,
+// regardless of which version the URL path points to, unless the path addresses a subcontext. The header may contain
+// the version, group/version or path.
+func negotiateVersion(req *http.Request, apiContext *types.APIContext) error {
+	requested := strings.TrimSpace(req.Header.Get(APIVersionHeader))
+	if requested == "" {
+		return nil
+	}
+
+	for _, version := range apiContext.Schemas.Versions() {
+		if version.SubContext {
+			continue
+		}
+		if requested == version.Path ||
+			requested == version.Version ||
+			requested == version.Group+"/"+version.Version {
+			v := version
+			apiContext.Version = &v
+			apiContext.Response.Header().Set(APIVersionHeader, version.Version)
+			return nil
+		}
+	}
+
+	return httperror.NewAPIError(httperror.NotFound, "unknown API version "+requested)
+}
+
 func versionsForPath(schemas *types.Schemas, path string) []types.APIVersion {
 	var matchedVersion []types.APIVersion
 	for _, version := range schemas.Versions() {
@@ -264,6 +313,10 @@ func parseResponseFormat(req *http.Request) string {
 	if isYaml(req) {
 		return "yaml"
 	}
+
+	if isNDJSON(req) {
+		return "ndjson"
+	}
 	return "json"
 }
 
@@ -271,6 +324,25 @@ func isYaml(req *http.Request) bool {
 	return strings.Contains(req.Header.Get("Accept"), "application/yaml")
 }
 
+func isNDJSON(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), "application/x-ndjson")
+}
+
+func schemaNames(schemas *types.Schemas, version *types.APIVersion) []string {
+	var names []string
+	for _, schema := range schemas.SchemasForVersion(*version) {
+		names = append(names, schema.ID, schema.PluralName)
+	}
+	return names
+}
+
+func parseRequestID(req *http.Request) string {
+	if id := strings.TrimSpace(req.Header.Get(types.RequestIDHeader)); id != "" && len(id) <= 128 {
+		return id
+	}
+	return uuid.NewRandom().String()
+}
+
 func parseMethod(req *http.Request) string {
 	method := req.URL.Query().Get("_method")
 	if method == "" {
@@ -0,0 +1,70 @@
+package parse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type widget struct {
+	types.Resource
+	Name string `json:"name"`
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	v1 := types.APIVersion{Version: "v1", Path: "/v1"}
+	v2 := types.APIVersion{Group: "example.io", Version: "v2", Path: "/v2"}
+	clusters := types.APIVersion{Version: "v1", Path: "/v1/clusters", SubContext: true, SubContextSchema: "cluster"}
+	schemas := types.NewSchemas().
+		MustImport(&v1, widget{}).
+		MustImport(&v2, widget{}).
+		MustImport(&clusters, widget{})
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		expected   string
+		subContext map[string]string
+		err        *httperror.ErrorCode
+	}{
+		{"no header", "/v1/widgets", "", "/v1", nil, nil},
+		{"version", "/v1/widgets", "v2", "/v2", nil, nil},
+		{"group and version", "/v1/widgets", "example.io/v2", "/v2", nil, nil},
+		{"path", "/v2/widgets", "/v1", "/v1", nil, nil},
+		{"unknown version", "/v1/widgets", "v3", "", nil, &httperror.NotFound},
+		{"subcontext keeps the path version", "/v1/clusters/c1/widgets", "v2", "/v1/clusters",
+			map[string]string{"cluster": "c1"}, nil},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.header != "" {
+			req.Header.Set(APIVersionHeader, test.header)
+		}
+		rw := httptest.NewRecorder()
+
+		apiContext, err := Parse(rw, req, schemas, DefaultURLParser, DefaultResolver)
+		if test.err != nil {
+			if assert.Error(t, err, test.name) {
+				assert.Equal(t, *test.err, err.(*httperror.APIError).Code, test.name)
+			}
+			continue
+		}
+		if !assert.NoError(t, err, test.name) {
+			continue
+		}
+		assert.Equal(t, test.expected, apiContext.Version.Path, test.name)
+		assert.Equal(t, "widget", apiContext.Type, test.name)
+		if test.subContext != nil {
+			assert.Equal(t, test.subContext, apiContext.SubContext, test.name)
+			assert.Empty(t, rw.Header().Get(APIVersionHeader), test.name)
+		} else if test.header != "" {
+			assert.Equal(t, apiContext.Version.Version, rw.Header().Get(APIVersionHeader), test.name)
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package parse
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 
 	"github.com/rancher/norman/httperror"
-	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
 const reqMaxSize = (2 * 1 << 20) + 1
@@ -36,10 +37,64 @@ func ReadBody(req *http.Request) (map[string]interface{}, error) {
 }
 
 func getDecoder(req *http.Request, reader io.Reader) Decode {
-	if req.Header.Get("Content-type") == "application/yaml" {
-		return yaml.NewYAMLToJSONDecoder(reader).Decode
+	if isYAML(req) {
+		return decodeYAML(reader)
 	}
 	decoder := json.NewDecoder(reader)
 	decoder.UseNumber()
 	return decoder.Decode
 }
+
+// ReadBodyList reads a JSON array of objects. If the body is no array it returns nil and leaves the body to be
+// read by ReadBody.
+func ReadBodyList(req *http.Request) ([]map[string]interface{}, error) {
+	if req.Method != http.MethodPost || req.Body == nil {
+		return nil, nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != "" && mediaType != "application/json" {
+		return nil, nil
+	}
+
+	reader := bufio.NewReader(req.Body)
+	req.Body = readCloser{
+		Reader: reader,
+		Closer: req.Body,
+	}
+	if !startsWithArray(reader) {
+		return nil, nil
+	}
+
+	var data []map[string]interface{}
+	decoder := json.NewDecoder(io.LimitReader(reader, maxFormSize))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, httperror.NewAPIError(httperror.InvalidBodyContent,
+			fmt.Sprintf("Failed to parse body: %v", err))
+	}
+	if data == nil {
+		data = []map[string]interface{}{}
+	}
+	return data, nil
+}
+
+func startsWithArray(reader *bufio.Reader) bool {
+	for i := 1; ; i++ {
+		peeked, err := reader.Peek(i)
+		if err != nil || len(peeked) < i {
+			return false
+		}
+		switch peeked[i-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '[':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,25 @@
+package parse
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBodyList(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/v3/clusters", strings.NewReader(` [{"name":"a"},{"name":"b"}]`))
+	items, err := ReadBodyList(req)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"name": "a"}, {"name": "b"}}, items)
+
+	req, _ = http.NewRequest(http.MethodPost, "/v3/clusters", strings.NewReader(`{"name":"a"}`))
+	items, err = ReadBodyList(req)
+	assert.NoError(t, err)
+	assert.Nil(t, items)
+
+	data, err := ReadBody(req)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "a"}, data)
+}
@@ -0,0 +1,153 @@
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	yamlMediaTypes = map[string]bool{
+		"application/yaml":   true,
+		"application/x-yaml": true,
+		"text/yaml":          true,
+		"text/x-yaml":        true,
+	}
+
+	octalNumber = regexp.MustCompile(`^[-+]?0[0-9_]+$`)
+)
+
+func isYAML(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && yamlMediaTypes[mediaType]
+}
+
+// decodeYAML decodes a YAML document into the same representation as a JSON body. Duplicate keys and scalars
+// YAML 1.1 reads differently than most writers expect, like yes or 0755, are rejected.
+func decodeYAML(reader io.Reader) Decode {
+	return func(out interface{}) error {
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		doc := &yamlValue{}
+		if err := yaml.Unmarshal(content, doc); err != nil {
+			return err
+		}
+		if doc.value == nil {
+			doc.value = map[string]interface{}{}
+		}
+		if _, ok := doc.value.(map[string]interface{}); !ok {
+			return errors.New("body must be an object")
+		}
+
+		data, err := json.Marshal(doc.value)
+		if err != nil {
+			return err
+		}
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.UseNumber()
+		return decoder.Decode(out)
+	}
+}
+
+type yamlValue struct {
+	value interface{}
+}
+
+func (v *yamlValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var resolved interface{}
+	if err := unmarshal(&resolved); err != nil {
+		return err
+	}
+
+	switch resolved.(type) {
+	case nil:
+		return nil
+	case map[interface{}]interface{}:
+		return v.unmarshalMap(unmarshal)
+	case []interface{}:
+		var items []*yamlValue
+		if err := unmarshal(&items); err != nil {
+			return err
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			if item != nil {
+				result[i] = item.value
+			}
+		}
+		v.value = result
+		return nil
+	}
+
+	var raw string
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	value, err := scalar(raw, resolved)
+	v.value = value
+	return err
+}
+
+func (v *yamlValue) unmarshalMap(unmarshal func(interface{}) error) error {
+	var items yaml.MapSlice
+	if err := unmarshal(&items); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, item := range items {
+		key := fmt.Sprint(item.Key)
+		if seen[key] {
+			return errors.Errorf("duplicate key %q", key)
+		}
+		seen[key] = true
+	}
+
+	var values map[string]*yamlValue
+	if err := unmarshal(&values); err != nil {
+		return err
+	}
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if value == nil {
+			result[key] = nil
+		} else {
+			result[key] = value.value
+		}
+	}
+	v.value = result
+	return nil
+}
+
+func scalar(raw string, resolved interface{}) (interface{}, error) {
+	switch value := resolved.(type) {
+	case bool:
+		if lower := strings.ToLower(raw); lower != "true" && lower != "false" {
+			return nil, errors.Errorf("ambiguous value %q is read as %v, quote it if a string is meant", raw, value)
+		}
+	case int, int64, uint64:
+		if octalNumber.MatchString(raw) {
+			return nil, errors.Errorf("ambiguous value %q is read as the octal number %v, quote it if a string is meant",
+				raw, value)
+		}
+		return json.Number(fmt.Sprint(value)), nil
+	case float64:
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return nil, errors.Errorf("value %q is not a valid number", raw)
+		}
+		return json.Number(strconv.FormatFloat(value, 'g', -1, 64)), nil
+	}
+	return resolved, nil
+}
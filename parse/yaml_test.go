@@ -0,0 +1,35 @@
+package parse
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readYAML(body string) (map[string]interface{}, error) {
+	req, _ := http.NewRequest(http.MethodPost, "/v3/clusters", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-yaml; charset=utf-8")
+	return ReadBody(req)
+}
+
+func TestReadYAMLBody(t *testing.T) {
+	data, err := readYAML("name: test\nreplicas: 3\nenabled: true\nlabels:\n  on: \"yes\"\nports:\n- 80\n- null\n")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]interface{}{
+		"name":     "test",
+		"replicas": json.Number("3"),
+		"enabled":  true,
+		"labels":   map[string]interface{}{"on": "yes"},
+		"ports":    []interface{}{json.Number("80"), nil},
+	}, data)
+
+	for _, invalid := range []string{"name: a\nname: b\n", "enabled: yes\n", "mode: 0755\n", "- a\n"} {
+		_, err := readYAML(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
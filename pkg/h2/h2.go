@@ -0,0 +1,125 @@
+package h2
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+)
+
+// prefaceRest is the part of the HTTP/2 client preface following the "PRI * HTTP/2.0" request parsed by the
+// HTTP/1 server.
+const prefaceRest = "SM\r\n\r\n"
+
+// Config tunes HTTP/2 serving. Zero values use the defaults, which allow many concurrent streams and larger flow
+// control windows than the HTTP/2 package, since UI traffic and subscriptions share a connection.
+type Config struct {
+	// MaxConcurrentStreams per connection, 1000 by default.
+	MaxConcurrentStreams uint32
+	// MaxUploadBufferPerConnection and MaxUploadBufferPerStream are the flow control windows for request bodies,
+	// 4MB and 1MB by default.
+	MaxUploadBufferPerConnection int32
+	MaxUploadBufferPerStream     int32
+	// IdleTimeout closes connections without streams, 5 minutes by default.
+	IdleTimeout time.Duration
+	// H2C serves HTTP/2 without TLS to clients connecting with prior knowledge, for plaintext traffic within a
+	// cluster. Upgrading HTTP/1.1 connections with "Upgrade: h2c" is not supported.
+	H2C bool
+}
+
+func (c Config) server() *http2.Server {
+	s := &http2.Server{
+		MaxConcurrentStreams:         c.MaxConcurrentStreams,
+		MaxUploadBufferPerConnection: c.MaxUploadBufferPerConnection,
+		MaxUploadBufferPerStream:     c.MaxUploadBufferPerStream,
+		IdleTimeout:                  c.IdleTimeout,
+	}
+	if s.MaxConcurrentStreams == 0 {
+		s.MaxConcurrentStreams = 1000
+	}
+	if s.MaxUploadBufferPerConnection == 0 {
+		s.MaxUploadBufferPerConnection = 4 << 20
+	}
+	if s.MaxUploadBufferPerStream == 0 {
+		s.MaxUploadBufferPerStream = 1 << 20
+	}
+	if s.IdleTimeout == 0 {
+		s.IdleTimeout = 5 * time.Minute
+	}
+	return s
+}
+
+// Configure makes server serve HTTP/2 over TLS and, if enabled, h2c with the settings of config. It has to be
+// called before the server starts. Connections served as h2c are hijacked, so server.Shutdown does not wait for
+// them.
+func Configure(server *http.Server, config Config) error {
+	h2Server := config.server()
+	if err := http2.ConfigureServer(server, h2Server); err != nil {
+		return err
+	}
+
+	if config.H2C {
+		handler := server.Handler
+		if handler == nil {
+			handler = http.DefaultServeMux
+		}
+		server.Handler = &h2cHandler{
+			Handler:    handler,
+			h2Server:   h2Server,
+			baseConfig: server,
+		}
+	}
+	return nil
+}
+
+type h2cHandler struct {
+	http.Handler
+	h2Server   *http2.Server
+	baseConfig *http.Server
+}
+
+func (h *h2cHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != "PRI" || req.URL.Path != "*" || req.Proto != "HTTP/2.0" || req.TLS != nil {
+		h.Handler.ServeHTTP(rw, req)
+		return
+	}
+
+	hijacker, ok := rw.(http.Hijacker)
+	if !ok {
+		http.Error(rw, "h2c is not supported", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		logrus.Errorf("Failed to hijack h2c connection: %v", err)
+		return
+	}
+
+	rest := make([]byte, len(prefaceRest))
+	if _, err := io.ReadFull(buffered, rest); err != nil || string(rest) != prefaceRest {
+		conn.Close()
+		return
+	}
+
+	// The HTTP/2 server reads the whole preface again, followed by what the HTTP/1 server already buffered
+	h.h2Server.ServeConn(&prefacedConn{
+		Conn:   conn,
+		reader: io.MultiReader(strings.NewReader(http2.ClientPreface), buffered),
+	}, &http2.ServeConnOpts{
+		BaseConfig: h.baseConfig,
+		Handler:    h.Handler,
+	})
+}
+
+type prefacedConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c *prefacedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
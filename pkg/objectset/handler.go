@@ -0,0 +1,61 @@
+package objectset
+
+import (
+	"github.com/rancher/norman/controller"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Generator returns the full desired set of children of owner. Children created earlier for owner and missing from
+// the set are deleted.
+type Generator func(owner runtime.Object) (*ObjectSet, error)
+
+// Handler returns a controller handler for owners of kind gvk that applies the children returned by generate,
+// creating, updating and pruning objects as needed. When the owner is deleted all of its children are removed.
+func (t Processor) Handler(gvk schema.GroupVersionKind, generate Generator) controller.HandlerFunc {
+	return func(key string, obj interface{}) (interface{}, error) {
+		if obj == nil {
+			owner, err := ownerFromKey(gvk, key)
+			if err != nil {
+				return nil, err
+			}
+			return nil, t.Remove(owner)
+		}
+
+		owner, ok := obj.(runtime.Object)
+		if !ok {
+			return obj, nil
+		}
+
+		// Objects from the cache usually lack their type, which is part of the ownership labels
+		owner = owner.DeepCopyObject()
+		if owner.GetObjectKind().GroupVersionKind().Empty() {
+			owner.GetObjectKind().SetGroupVersionKind(gvk)
+		}
+
+		objs, err := generate(owner)
+		if err != nil {
+			return obj, err
+		}
+		if objs == nil {
+			objs = NewObjectSet()
+		}
+
+		return obj, t.NewDesiredSet(owner, objs).Apply()
+	}
+}
+
+func ownerFromKey(gvk schema.GroupVersionKind, key string) (runtime.Object, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(gvk)
+	owner.SetNamespace(namespace)
+	owner.SetName(name)
+	return owner, nil
+}
@@ -0,0 +1,94 @@
+package subscribe
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+	"github.com/rancher/norman/api/writer"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+)
+
+// EncodingParam selects the encoding of the events of a subscription, JSONEncoding in text messages by default
+// or MsgpackEncoding in binary messages.
+const (
+	EncodingParam   = "encoding"
+	JSONEncoding    = "json"
+	MsgpackEncoding = "msgpack"
+)
+
+type eventEncoder struct {
+	apiContext  *types.APIContext
+	msgpack     bool
+	messageType int
+}
+
+func newEventEncoder(apiContext *types.APIContext) (*eventEncoder, error) {
+	switch encoding := apiContext.Request.URL.Query().Get(EncodingParam); encoding {
+	case "", JSONEncoding:
+		return &eventEncoder{
+			apiContext:  apiContext,
+			messageType: websocket.TextMessage,
+		}, nil
+	case MsgpackEncoding:
+		return &eventEncoder{
+			apiContext:  apiContext,
+			msgpack:     true,
+			messageType: websocket.BinaryMessage,
+		}, nil
+	default:
+		return nil, httperror.NewAPIError(httperror.InvalidOption, "unsupported "+EncodingParam+" "+encoding)
+	}
+}
+
+// encode returns the message of the event. Resources of version are formatted like API responses of the version,
+// other data is sent as is if version is nil. The revision, if any, lets the client resume the subscription.
+func (e *eventEncoder) encode(name, revision string, version *types.APIVersion, data interface{}) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+
+	if e.msgpack {
+		encoder := func(w io.Writer, v interface{}) error {
+			event := map[string]interface{}{
+				"name": name,
+				"data": v,
+			}
+			if revision != "" {
+				event["revision"] = revision
+			}
+			return types.MsgpackEncoder(w, event)
+		}
+		if version == nil {
+			err := encoder(buffer, data)
+			return buffer.Bytes(), err
+		}
+		w := writer.EncodingResponseWriter{
+			ContentType: "application/msgpack",
+			Encoder:     encoder,
+		}
+		err := w.VersionBody(e.apiContext, version, buffer, data)
+		return buffer.Bytes(), err
+	}
+
+	buffer.WriteString(`{"name":"` + name + `",`)
+	if revision != "" {
+		buffer.WriteString(`"revision":` + strconv.Quote(revision) + `,`)
+	}
+	buffer.WriteString(`"data":`)
+	if version == nil {
+		if err := types.JSONEncoder(buffer, data); err != nil {
+			return nil, err
+		}
+	} else {
+		w := writer.EncodingResponseWriter{
+			ContentType: "application/json",
+			Encoder:     types.JSONEncoder,
+		}
+		if err := w.VersionBody(e.apiContext, version, buffer, data); err != nil {
+			return nil, err
+		}
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,119 @@
+package subscribe
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types/convert"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Query parameters of the subscribe endpoint restricting the events and fields of the subscription.
+const (
+	NamespaceParam     = "namespaceId"
+	LabelSelectorParam = "labelSelector"
+	FieldSelectorParam = "fieldSelector"
+	FieldsParam        = "fields"
+	// RevisionParam resumes the watches of types after the revisions of the last events received, given as
+	// type:revision, so one subscription can resume many types.
+	RevisionParam = "revision"
+)
+
+// alwaysSent are the fields sent even if they are not part of the field mask.
+var alwaysSent = []string{"id", "type", "baseType", ".removed"}
+
+type filter struct {
+	namespaces    []string
+	labelSelector labels.Selector
+	fieldSelector fields.Selector
+	fields        map[string]bool
+}
+
+func newFilter(query url.Values) (*filter, error) {
+	f := &filter{
+		namespaces: query[NamespaceParam],
+	}
+
+	if selector := query.Get(LabelSelectorParam); selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return nil, httperror.WrapAPIError(err, httperror.InvalidFormat, "invalid "+LabelSelectorParam)
+		}
+		f.labelSelector = parsed
+	}
+
+	if selector := query.Get(FieldSelectorParam); selector != "" {
+		parsed, err := fields.ParseSelector(selector)
+		if err != nil {
+			return nil, httperror.WrapAPIError(err, httperror.InvalidFormat, "invalid "+FieldSelectorParam)
+		}
+		f.fieldSelector = parsed
+	}
+
+	for _, value := range query[FieldsParam] {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field == "" {
+				continue
+			}
+			if f.fields == nil {
+				f.fields = map[string]bool{}
+				for _, name := range alwaysSent {
+					f.fields[name] = true
+				}
+			}
+			f.fields[field] = true
+		}
+	}
+
+	return f, nil
+}
+
+func (f *filter) matches(item map[string]interface{}) bool {
+	if len(f.namespaces) > 0 && !matches(f.namespaces, convert.ToString(item["namespaceId"])) {
+		return false
+	}
+
+	if f.labelSelector != nil {
+		itemLabels := labels.Set{}
+		for k, v := range convert.ToMapInterface(item["labels"]) {
+			itemLabels[k] = convert.ToString(v)
+		}
+		if !f.labelSelector.Matches(itemLabels) {
+			return false
+		}
+	}
+
+	if f.fieldSelector != nil {
+		itemFields := fields.Set{}
+		for k, v := range item {
+			switch v.(type) {
+			case string, bool, int, int64, float64:
+				itemFields[k] = convert.ToString(v)
+			}
+		}
+		itemFields["metadata.name"] = itemFields["name"]
+		itemFields["metadata.namespace"] = itemFields["namespaceId"]
+		if !f.fieldSelector.Matches(itemFields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mask drops all fields not asked for, returning item unchanged if the subscription did not ask for fields.
+func (f *filter) mask(item map[string]interface{}) map[string]interface{} {
+	if f.fields == nil {
+		return item
+	}
+
+	result := make(map[string]interface{}, len(f.fields))
+	for k, v := range item {
+		if f.fields[k] {
+			result[k] = v
+		}
+	}
+	return result
+}
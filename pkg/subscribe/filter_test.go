@@ -0,0 +1,42 @@
+package subscribe
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	f, err := newFilter(url.Values{
+		NamespaceParam:     {"default"},
+		LabelSelectorParam: {"app=web"},
+		FieldSelectorParam: {"state=active"},
+		FieldsParam:        {"name,state"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	item := map[string]interface{}{
+		"id":          "default:web",
+		"type":        "pod",
+		"name":        "web",
+		"namespaceId": "default",
+		"state":       "active",
+		"labels":      map[string]interface{}{"app": "web"},
+	}
+	assert.True(t, f.matches(item))
+	assert.Equal(t, map[string]interface{}{
+		"id":    "default:web",
+		"type":  "pod",
+		"name":  "web",
+		"state": "active",
+	}, f.mask(item))
+
+	item["state"] = "removing"
+	assert.False(t, f.matches(item))
+
+	_, err = newFilter(url.Values{LabelSelectorParam: {"a in ("}})
+	assert.Error(t, err)
+}
@@ -1,13 +1,13 @@
 package subscribe
 
 import (
-	"bytes"
 	"context"
 	"errors"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"github.com/rancher/norman/api/writer"
 	"github.com/rancher/norman/httperror"
 	"github.com/rancher/norman/parse"
 	"github.com/rancher/norman/types"
@@ -17,20 +17,55 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-var upgrader = websocket.Upgrader{}
-
 type Subscribe struct {
 	ResourceTypes []string
 	APIVersions   []string
 	ProjectID     string `norman:"type=reference[/v3/schemas/project]"`
 }
 
-func Handler(apiContext *types.APIContext, _ types.RequestHandler) error {
-	err := handler(apiContext)
-	if err != nil {
-		logrus.Errorf("Error during subscribe %v", err)
+// Options configure the subscribe handler.
+type Options struct {
+	// BufferSize is how many events are buffered per connection for a slow client. Defaults to 1000.
+	BufferSize int
+	// SlowConsumerPolicy decides what happens when the buffer of a client is full. Defaults to DropOldest.
+	SlowConsumerPolicy SlowConsumerPolicy
+	// PingInterval is how often the server pings the client. Defaults to 5s.
+	PingInterval time.Duration
+	// PongTimeout closes the connection if the client neither answered a ping nor sent a message for this
+	// long. Defaults to 30s.
+	PongTimeout time.Duration
+	// WriteTimeout closes the connection if writing a message takes longer. Defaults to 10s.
+	WriteTimeout time.Duration
+	// DisableCompression stops negotiating permessage-deflate with clients that offer it.
+	DisableCompression bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.PingInterval <= 0 {
+		o.PingInterval = 5 * time.Second
+	}
+	if o.PongTimeout <= 0 {
+		o.PongTimeout = 30 * time.Second
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = 10 * time.Second
+	}
+	return o
+}
+
+func Handler(apiContext *types.APIContext, next types.RequestHandler) error {
+	return NewHandler(Options{})(apiContext, next)
+}
+
+// NewHandler returns a subscribe handler configured by opts.
+func NewHandler(opts Options) types.RequestHandler {
+	return func(apiContext *types.APIContext, _ types.RequestHandler) error {
+		err := handler(apiContext, opts.withDefaults())
+		if err != nil {
+			logrus.Errorf("Error during subscribe %v", err)
+		}
+		return err
 	}
-	return err
 }
 
 func getMatchingSchemas(apiContext *types.APIContext) []*types.Schema {
@@ -49,12 +84,35 @@ func getMatchingSchemas(apiContext *types.APIContext) []*types.Schema {
 	return schemas
 }
 
-func handler(apiContext *types.APIContext) error {
+func handler(apiContext *types.APIContext, opts Options) error {
 	schemas := getMatchingSchemas(apiContext)
 	if len(schemas) == 0 {
 		return httperror.NewAPIError(httperror.NotFound, "no resources types matched")
 	}
+	// QueryOptions ignores invalid filters, so they are rejected before the connection is upgraded
+	for _, schema := range schemas {
+		if err := parse.ValidateFilter(apiContext, schema); err != nil {
+			return err
+		}
+	}
+
+	filter, err := newFilter(apiContext.Request.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	encoder, err := newEventEncoder(apiContext)
+	if err != nil {
+		return err
+	}
+	resync, err := encoder.encode("resource.resync", "", nil, map[string]interface{}{})
+	if err != nil {
+		return err
+	}
 
+	upgrader := websocket.Upgrader{
+		EnableCompression: !opts.DisableCompression,
+	}
 	c, err := upgrader.Upgrade(apiContext.Response, apiContext.Request, nil)
 	if err != nil {
 		return err
@@ -62,9 +120,18 @@ func handler(apiContext *types.APIContext) error {
 	defer c.Close()
 
 	cancelCtx, cancel := context.WithCancel(apiContext.Request.Context())
+	defer cancel()
 	readerGroup, ctx := errgroup.WithContext(cancelCtx)
 	apiContext.Request = apiContext.Request.WithContext(ctx)
 
+	alive := func() error {
+		return c.SetReadDeadline(time.Now().Add(opts.PongTimeout))
+	}
+	alive()
+	c.SetPongHandler(func(string) error {
+		return alive()
+	})
+
 	go func() {
 		for {
 			if _, _, err := c.NextReader(); err != nil {
@@ -72,12 +139,39 @@ func handler(apiContext *types.APIContext) error {
 				c.Close()
 				break
 			}
+			alive()
 		}
 	}()
 
+	q := newQueue(opts.BufferSize, opts.SlowConsumerPolicy, resync)
+	send := func(name, revision string, version *types.APIVersion, data interface{}) {
+		msg, err := encoder.encode(name, revision, version, data)
+		if err != nil {
+			logrus.Errorf("failed to encode %s event: %v", name, err)
+			return
+		}
+		if !q.push(msg) {
+			logrus.Debugf("closing subscription of slow client %s", apiContext.Request.RemoteAddr)
+			cancel()
+		}
+	}
+	go func() {
+		for {
+			msg, ok := q.pop(ctx)
+			if !ok {
+				return
+			}
+			if err := writeMessage(c, encoder.messageType, msg, opts.WriteTimeout); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	revisions := revisions(apiContext.Request.URL.Query())
 	events := make(chan map[string]interface{})
 	for _, schema := range schemas {
-		streamStore(ctx, readerGroup, apiContext, schema, events)
+		streamStore(ctx, readerGroup, apiContext, schema, revisions[schema.ID], events)
 	}
 
 	go func() {
@@ -85,13 +179,12 @@ func handler(apiContext *types.APIContext) error {
 		close(events)
 	}()
 
-	jsonWriter := writer.EncodingResponseWriter{
-		ContentType: "application/json",
-		Encoder:     types.JSONEncoder,
-	}
-	t := time.NewTicker(5 * time.Second)
+	t := time.NewTicker(opts.PingInterval)
 	defer t.Stop()
 
+	schemaChanges, stopSchemaWatch := watchSchemas(apiContext.Request.Context())
+	defer stopSchemaWatch()
+
 	done := false
 	for !done {
 		select {
@@ -100,27 +193,32 @@ func handler(apiContext *types.APIContext) error {
 				done = true
 				break
 			}
+			if item[expiredField] == true {
+				send("resource.resync", "", nil, map[string]interface{}{
+					"type": item["type"],
+					"code": httperror.Expired.Code,
+				})
+				continue
+			}
+			if !filter.matches(item) {
+				continue
+			}
 
-			header := `{"name":"resource.change","data":`
+			name := "resource.change"
 			if item[".removed"] == true {
-				header = `{"name":"resource.remove","data":`
+				name = "resource.remove"
 			}
 			schema := apiContext.Schemas.Schema(apiContext.Version, convert.ToString(item["type"]))
 			if schema != nil {
-				buffer := &bytes.Buffer{}
-				if err := jsonWriter.VersionBody(apiContext, &schema.Version, buffer, item); err != nil {
-					cancel()
-					continue
-				}
-
-				if err := writeData(c, header, buffer.Bytes()); err != nil {
-					cancel()
-				}
+				send(name, convert.ToString(item[types.RevisionField]), &schema.Version, filter.mask(item))
 			}
+		case <-schemaChanges:
+			send("schema.change", "", nil, map[string]interface{}{})
 		case <-t.C:
-			if err := writeData(c, `{"name":"ping","data":`, []byte("{}")); err != nil {
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(opts.WriteTimeout)); err != nil {
 				cancel()
 			}
+			send("ping", "", nil, map[string]interface{}{})
 		}
 	}
 
@@ -128,28 +226,50 @@ func handler(apiContext *types.APIContext) error {
 	return nil
 }
 
-func writeData(c *websocket.Conn, header string, buf []byte) error {
-	messageWriter, err := c.NextWriter(websocket.TextMessage)
-	if err != nil {
+func writeMessage(c *websocket.Conn, messageType int, msg []byte, timeout time.Duration) error {
+	if err := c.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
 		return err
 	}
+	return c.WriteMessage(messageType, msg)
+}
 
-	if _, err := messageWriter.Write([]byte(header)); err != nil {
-		return err
-	}
-	if _, err := messageWriter.Write(buf); err != nil {
-		return err
-	}
-	if _, err := messageWriter.Write([]byte(`}`)); err != nil {
-		return err
+// revisions returns the revisions to resume the watches from by type, given as type:revision.
+func revisions(query url.Values) map[string]string {
+	result := map[string]string{}
+	for _, value := range query[RevisionParam] {
+		for _, typeRevision := range strings.Split(value, ",") {
+			parts := strings.SplitN(strings.TrimSpace(typeRevision), ":", 2)
+			if len(parts) == 2 && parts[1] != "" {
+				result[parts[0]] = parts[1]
+			}
+		}
 	}
-	return messageWriter.Close()
+	return result
 }
 
-func streamStore(ctx context.Context, eg *errgroup.Group, apiContext *types.APIContext, schema *types.Schema, result chan map[string]interface{}) {
+// expiredField marks the item streamStore sends when the revision a watch should resume after has expired.
+const expiredField = ".expired"
+
+func streamStore(ctx context.Context, eg *errgroup.Group, apiContext *types.APIContext, schema *types.Schema, revision string, result chan map[string]interface{}) {
 	eg.Go(func() error {
 		opts := parse.QueryOptions(apiContext, schema)
+		if revision != "" {
+			if opts.Options == nil {
+				opts.Options = map[string]string{}
+			}
+			opts.Options[types.RevisionOption] = revision
+		}
 		events, err := schema.Store.Watch(apiContext, schema, &opts)
+		if revision != "" && isExpired(err) {
+			// The client has to list again, the watch continues with the current state
+			select {
+			case result <- map[string]interface{}{"type": schema.ID, expiredField: true}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			delete(opts.Options, types.RevisionOption)
+			events, err = schema.Store.Watch(apiContext, schema, &opts)
+		}
 		if err != nil || events == nil {
 			if err != nil {
 				logrus.Errorf("failed on subscribe %s: %v", schema.ID, err)
@@ -167,6 +287,11 @@ func streamStore(ctx context.Context, eg *errgroup.Group, apiContext *types.APIC
 	})
 }
 
+func isExpired(err error) bool {
+	apiError, ok := err.(*httperror.APIError)
+	return ok && apiError.Code.Status == httperror.Expired.Status
+}
+
 func matches(items []string, item string) bool {
 	if len(items) == 0 {
 		return true
@@ -0,0 +1,105 @@
+package subscribe
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack"
+)
+
+type widget struct {
+	types.Resource
+	Name string `json:"name"`
+}
+
+// expiringStore reports every revision to resume after as expired.
+type expiringStore struct {
+	*memory.Store
+}
+
+func (e expiringStore) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	if opt.Options[types.RevisionOption] != "" {
+		return nil, httperror.NewAPIError(httperror.Expired, "revision expired")
+	}
+	return e.Store.Watch(apiContext, schema, opt)
+}
+
+func newSubscribeServer() *httptest.Server {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().MustImport(&version, widget{})
+	schemas.Schema(&version, "widget").Store = expiringStore{memory.NewStore()}
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		NewHandler(Options{PingInterval: 10 * time.Millisecond})(&types.APIContext{
+			Request:  req,
+			Response: rw,
+			Query:    req.URL.Query(),
+			Schemas:  schemas,
+			Version:  &version,
+		}, nil)
+	}))
+}
+
+func TestSubscribeCompressedMsgpack(t *testing.T) {
+	server := newSubscribeServer()
+	defer server.Close()
+
+	dialer := websocket.Dialer{EnableCompression: true}
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/subscribe?" + EncodingParam + "=" + MsgpackEncoding
+	c, resp, err := dialer.Dial(url, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer c.Close()
+	assert.Contains(t, resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate")
+
+	messageType, data, err := c.ReadMessage()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+
+	event := map[string]interface{}{}
+	if assert.NoError(t, msgpack.Unmarshal(data, &event)) {
+		assert.Equal(t, "ping", event["name"])
+	}
+}
+
+func TestSubscribeExpiredRevision(t *testing.T) {
+	server := newSubscribeServer()
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/subscribe?" + RevisionParam + "=widget:5"
+	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer c.Close()
+
+	event := map[string]interface{}{}
+	if assert.NoError(t, c.ReadJSON(&event)) {
+		assert.Equal(t, "resource.resync", event["name"])
+		assert.Equal(t, map[string]interface{}{"type": "widget", "code": httperror.Expired.Code}, event["data"])
+	}
+	// The watch goes on from the current state
+	if assert.NoError(t, c.ReadJSON(&event)) {
+		assert.Equal(t, "ping", event["name"])
+	}
+}
+
+func TestSubscribeInvalidFilter(t *testing.T) {
+	server := newSubscribeServer()
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/subscribe?filter=name%3Da&filterLanguage=unknown"
+	_, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Equal(t, websocket.ErrBadHandshake, err)
+}
@@ -0,0 +1,98 @@
+package subscribe
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rancher/norman/metrics"
+)
+
+// SlowConsumerPolicy decides what happens when a client reads events slower than they are produced and its
+// buffer is full.
+type SlowConsumerPolicy string
+
+const (
+	// DropOldest drops the oldest buffered event and sends a resource.resync event, telling the client to list
+	// the resources again.
+	DropOldest SlowConsumerPolicy = "dropOldest"
+	// Disconnect closes the connection of the client.
+	Disconnect SlowConsumerPolicy = "disconnect"
+
+	DefaultBufferSize = 1000
+)
+
+// queue buffers the messages of one connection so a slow client never blocks the watches feeding it.
+type queue struct {
+	sync.Mutex
+	messages [][]byte
+	size     int
+	policy   SlowConsumerPolicy
+	resync   bool
+	// resyncMessage is sent in place of dropped messages.
+	resyncMessage []byte
+	ready         chan struct{}
+}
+
+func newQueue(size int, policy SlowConsumerPolicy, resyncMessage []byte) *queue {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	if policy == "" {
+		policy = DropOldest
+	}
+	return &queue{
+		size:          size,
+		policy:        policy,
+		resyncMessage: resyncMessage,
+		ready:         make(chan struct{}, 1),
+	}
+}
+
+// push adds msg to the queue. It returns false if the queue is full and the client should be disconnected.
+func (q *queue) push(msg []byte) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	if len(q.messages) >= q.size {
+		metrics.IncSubscribeDropped(string(q.policy))
+		if q.policy == Disconnect {
+			return false
+		}
+		q.messages[0] = nil
+		q.messages = q.messages[1:]
+		q.resync = true
+	}
+
+	q.messages = append(q.messages, msg)
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// pop returns the next message, waiting for one until ctx is done.
+func (q *queue) pop(ctx context.Context) ([]byte, bool) {
+	for {
+		q.Lock()
+		if q.resync {
+			q.resync = false
+			q.Unlock()
+			return q.resyncMessage, true
+		}
+		if len(q.messages) > 0 {
+			msg := q.messages[0]
+			q.messages[0] = nil
+			q.messages = q.messages[1:]
+			q.Unlock()
+			return msg, true
+		}
+		q.Unlock()
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package subscribe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueDropOldest(t *testing.T) {
+	q := newQueue(2, DropOldest, []byte("resync"))
+	assert.True(t, q.push([]byte("1")))
+	assert.True(t, q.push([]byte("2")))
+	assert.True(t, q.push([]byte("3")))
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		msg, ok := q.pop(context.Background())
+		assert.True(t, ok)
+		got = append(got, string(msg))
+	}
+	assert.Equal(t, []string{"resync", "2", "3"}, got)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ok := q.pop(ctx)
+	assert.False(t, ok)
+}
+
+func TestQueueDisconnect(t *testing.T) {
+	q := newQueue(1, Disconnect, nil)
+	assert.True(t, q.push([]byte("1")))
+	assert.False(t, q.push([]byte("2")))
+}
@@ -7,10 +7,15 @@ import (
 )
 
 func Register(version *types.APIVersion, schemas *types.Schemas) {
+	RegisterWithOptions(version, schemas, Options{})
+}
+
+// RegisterWithOptions registers the subscribe type with a handler configured by opts.
+func RegisterWithOptions(version *types.APIVersion, schemas *types.Schemas, opts Options) {
 	schemas.MustImportAndCustomize(version, Subscribe{}, func(schema *types.Schema) {
 		schema.CollectionMethods = []string{http.MethodGet}
 		schema.ResourceMethods = []string{}
-		schema.ListHandler = Handler
+		schema.ListHandler = NewHandler(opts)
 		schema.PluralName = "subscribe"
 	})
 }
@@ -0,0 +1,64 @@
+package subscribe
+
+import (
+	"context"
+	"sync"
+)
+
+// SchemaNotifier tells the open subscriptions of a server that its served schemas have changed so clients can
+// refresh their schema cache and resubscribe. Subscriptions find the notifier of their server in the request
+// context, see WithSchemaNotifier.
+type SchemaNotifier struct {
+	sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func NewSchemaNotifier() *SchemaNotifier {
+	return &SchemaNotifier{
+		subs: map[chan struct{}]struct{}{},
+	}
+}
+
+// Notify signals every subscription watching n.
+func (n *SchemaNotifier) Notify() {
+	n.Lock()
+	defer n.Unlock()
+
+	for sub := range n.subs {
+		select {
+		case sub <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (n *SchemaNotifier) watch() (chan struct{}, func()) {
+	sub := make(chan struct{}, 1)
+
+	n.Lock()
+	n.subs[sub] = struct{}{}
+	n.Unlock()
+
+	return sub, func() {
+		n.Lock()
+		delete(n.subs, sub)
+		n.Unlock()
+	}
+}
+
+type schemaNotifierKey struct{}
+
+// WithSchemaNotifier returns a copy of ctx whose subscriptions are notified by n.
+func WithSchemaNotifier(ctx context.Context, n *SchemaNotifier) context.Context {
+	return context.WithValue(ctx, schemaNotifierKey{}, n)
+}
+
+// watchSchemas returns a channel signaled when the schemas served change, or nil if the server of the request
+// never replaces its schemas.
+func watchSchemas(ctx context.Context) (chan struct{}, func()) {
+	n, ok := ctx.Value(schemaNotifierKey{}).(*SchemaNotifier)
+	if !ok || n == nil {
+		return nil, func() {}
+	}
+	return n.watch()
+}
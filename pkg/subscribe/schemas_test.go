@@ -0,0 +1,38 @@
+package subscribe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaNotifierPerServer(t *testing.T) {
+	a, b := NewSchemaNotifier(), NewSchemaNotifier()
+
+	changesA, stopA := watchSchemas(WithSchemaNotifier(context.Background(), a))
+	defer stopA()
+	changesB, stopB := watchSchemas(WithSchemaNotifier(context.Background(), b))
+	defer stopB()
+
+	a.Notify()
+
+	select {
+	case <-changesA:
+	default:
+		t.Fatal("subscription of the reloaded server was not notified")
+	}
+	select {
+	case <-changesB:
+		t.Fatal("subscription of another server was notified")
+	default:
+	}
+
+	changes, stop := watchSchemas(context.Background())
+	defer stop()
+	assert.Nil(t, changes)
+
+	changes, stop = watchSchemas(WithSchemaNotifier(context.Background(), nil))
+	defer stop()
+	assert.Nil(t, changes)
+}
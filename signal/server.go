@@ -0,0 +1,30 @@
+package signal
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ShutdownTimeout is how long ListenAndServe waits for active requests when shutting down.
+var ShutdownTimeout = 30 * time.Second
+
+// ListenAndServe serves server until ctx is cancelled, then shuts it down as part of the hooks registered with
+// OnShutdown.
+func ListenAndServe(ctx context.Context, server *http.Server) error {
+	OnShutdown(ctx, "http server "+server.Addr, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logrus.Warnf("Failed to shut down http server %s: %v", server.Addr, err)
+		}
+	})
+
+	err := server.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
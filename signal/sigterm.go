@@ -4,16 +4,46 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"github.com/sirupsen/logrus"
 )
 
+type lifecycleKey struct{}
+
+// lifecycle runs the shutdown hooks registered on a context once it is cancelled.
+type lifecycle struct {
+	sync.Mutex
+	hooks   []*hook
+	done    chan struct{}
+	stopped bool
+	// stop ends the goroutine of a detached lifecycle once its last hook was removed
+	stop chan struct{}
+}
+
+type hook struct {
+	name string
+	f    func()
+}
+
+var (
+	// detached holds the lifecycles of contexts that don't come from SigTermCancelContext, so a single goroutine
+	// waits for each of them regardless of how many hooks are registered.
+	detachedLock sync.Mutex
+	detached     = map[context.Context]*lifecycle{}
+)
+
+// SigTermCancelContext returns a context that is cancelled on the first SIGTERM or SIGINT, after which the hooks
+// registered with OnShutdown run. A second signal exits the process right away.
 func SigTermCancelContext(ctx context.Context) context.Context {
-	term := make(chan os.Signal)
+	term := make(chan os.Signal, 2)
 	signal.Notify(term, os.Interrupt, syscall.SIGTERM)
 
-	ctx, cancel := context.WithCancel(ctx)
+	l := &lifecycle{
+		done: make(chan struct{}),
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, lifecycleKey{}, l))
 
 	go func() {
 		select {
@@ -22,7 +52,121 @@ func SigTermCancelContext(ctx context.Context) context.Context {
 			cancel()
 		case <-ctx.Done():
 		}
+
+		go l.shutdown(ctx)
+
+		select {
+		case <-term:
+			logrus.Warnf("Received second SIGTERM, exiting")
+			os.Exit(1)
+		case <-l.done:
+			signal.Stop(term)
+		}
 	}()
 
 	return ctx
 }
+
+// OnShutdown registers f to run once ctx is cancelled. Hooks run one after another in the reverse order of their
+// registration, so something started later is stopped first. Without a context from SigTermCancelContext the
+// hooks of ctx run in a goroutine shared by all of them once ctx is done. The returned func unregisters f, for
+// work that ended before ctx.
+func OnShutdown(ctx context.Context, name string, f func()) func() {
+	h := &hook{name: name, f: f}
+
+	l, ok := ctx.Value(lifecycleKey{}).(*lifecycle)
+	if !ok {
+		detachedLock.Lock()
+		l = detachedLifecycle(ctx)
+		l.add(h)
+		detachedLock.Unlock()
+	} else if !l.add(h) {
+		// Shutdown already started, f runs right away like it would for a context that is already done
+		go f()
+	}
+
+	return func() {
+		l.remove(ctx, h)
+	}
+}
+
+// detachedLifecycle returns the lifecycle of ctx, starting the goroutine waiting for it if there is none yet.
+// detachedLock has to be held.
+func detachedLifecycle(ctx context.Context) *lifecycle {
+	if l, ok := detached[ctx]; ok {
+		return l
+	}
+
+	l := &lifecycle{
+		done: make(chan struct{}),
+		stop: make(chan struct{}),
+	}
+	detached[ctx] = l
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.shutdown(ctx)
+		case <-l.stop:
+		}
+	}()
+	return l
+}
+
+// Done is closed once ctx is cancelled and all hooks registered with OnShutdown returned. Without a context from
+// SigTermCancelContext it is ctx.Done().
+func Done(ctx context.Context) <-chan struct{} {
+	if l, ok := ctx.Value(lifecycleKey{}).(*lifecycle); ok {
+		return l.done
+	}
+	return ctx.Done()
+}
+
+func (l *lifecycle) add(h *hook) bool {
+	l.Lock()
+	defer l.Unlock()
+	if l.stopped {
+		return false
+	}
+	l.hooks = append(l.hooks, h)
+	return true
+}
+
+// remove unregisters h, a detached lifecycle without hooks left stops waiting for ctx.
+func (l *lifecycle) remove(ctx context.Context, h *hook) {
+	detachedLock.Lock()
+	defer detachedLock.Unlock()
+	l.Lock()
+	defer l.Unlock()
+
+	for i, existing := range l.hooks {
+		if existing == h {
+			l.hooks = append(l.hooks[:i], l.hooks[i+1:]...)
+			break
+		}
+	}
+	if len(l.hooks) == 0 && l.stop != nil && !l.stopped {
+		l.stopped = true
+		close(l.stop)
+		delete(detached, ctx)
+	}
+}
+
+func (l *lifecycle) shutdown(ctx context.Context) {
+	defer close(l.done)
+
+	detachedLock.Lock()
+	l.Lock()
+	l.stopped = true
+	hooks := l.hooks
+	l.hooks = nil
+	if detached[ctx] == l {
+		delete(detached, ctx)
+	}
+	l.Unlock()
+	detachedLock.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		logrus.Infof("Shutting down %s", hooks[i].name)
+		hooks[i].f()
+	}
+}
@@ -0,0 +1,70 @@
+package signal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnShutdown(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx := SigTermCancelContext(parent)
+
+	var order []string
+	OnShutdown(ctx, "controllers", func() {
+		order = append(order, "controllers")
+	})
+	OnShutdown(ctx, "server", func() {
+		order = append(order, "server")
+	})
+
+	cancel()
+	<-Done(ctx)
+	assert.Equal(t, []string{"server", "controllers"}, order)
+}
+
+func TestOnShutdownDetached(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ran := make(chan string, 2)
+	var unregister []func()
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		unregister = append(unregister, OnShutdown(ctx, name, func() {
+			ran <- name
+		}))
+	}
+
+	detachedLock.Lock()
+	assert.Contains(t, detached, ctx)
+	detachedLock.Unlock()
+
+	unregister[1]()
+	cancel()
+	for _, expected := range []string{"c", "a"} {
+		select {
+		case name := <-ran:
+			assert.Equal(t, expected, name)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for shutdown hook")
+		}
+	}
+}
+
+func TestOnShutdownUnregister(t *testing.T) {
+	ctx := context.Background()
+
+	unregister := []func(){
+		OnShutdown(ctx, "a", func() {}),
+		OnShutdown(ctx, "b", func() {}),
+	}
+	for _, f := range unregister {
+		f()
+	}
+
+	detachedLock.Lock()
+	defer detachedLock.Unlock()
+	assert.NotContains(t, detached, ctx)
+}
@@ -0,0 +1,92 @@
+package authz
+
+import (
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+)
+
+// Authorizer decides whether the caller of apiContext may run verb, one of the types.Verb constants, on schema. id
+// is empty for list and watch, unless the caller names the namespace checked as "namespace:", or ":" for all
+// namespaces. Creates of namespaced objects pass the namespaceId of the object as "namespace:". Returning an error
+// rejects the operation.
+type Authorizer interface {
+	Authorize(apiContext *types.APIContext, schema *types.Schema, verb, id string) error
+}
+
+type AuthorizerFunc func(apiContext *types.APIContext, schema *types.Schema, verb, id string) error
+
+func (a AuthorizerFunc) Authorize(apiContext *types.APIContext, schema *types.Schema, verb, id string) error {
+	return a(apiContext, schema, verb, id)
+}
+
+// Wrap checks every operation on s with authorizer before it reaches s, so the same checks apply no matter if
+// the store is called from the API handlers, actions or other stores.
+func Wrap(s types.Store, authorizer Authorizer) types.Store {
+	return store.Wrap(s, Middleware(authorizer))
+}
+
+func Middleware(authorizer Authorizer) store.Middleware {
+	return store.Middleware{
+		ByID: func(next store.ByIDFunc) store.ByIDFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+				if err := authorizer.Authorize(apiContext, schema, types.VerbGet, id); err != nil {
+					return nil, err
+				}
+				return next(apiContext, schema, id)
+			}
+		},
+		List: func(next store.ListFunc) store.ListFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+				if err := authorizer.Authorize(apiContext, schema, types.VerbList, ""); err != nil {
+					return nil, err
+				}
+				return next(apiContext, schema, opt)
+			}
+		},
+		Create: func(next store.CreateFunc) store.CreateFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+				if err := authorizer.Authorize(apiContext, schema, types.VerbCreate, createID(schema, data)); err != nil {
+					return nil, err
+				}
+				return next(apiContext, schema, data)
+			}
+		},
+		Update: func(next store.UpdateFunc) store.UpdateFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+				if err := authorizer.Authorize(apiContext, schema, types.VerbUpdate, id); err != nil {
+					return nil, err
+				}
+				return next(apiContext, schema, data, id)
+			}
+		},
+		Delete: func(next store.DeleteFunc) store.DeleteFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+				if err := authorizer.Authorize(apiContext, schema, types.VerbDelete, id); err != nil {
+					return nil, err
+				}
+				return next(apiContext, schema, id)
+			}
+		},
+		Watch: func(next store.WatchFunc) store.WatchFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+				if err := authorizer.Authorize(apiContext, schema, types.VerbWatch, ""); err != nil {
+					return nil, err
+				}
+				return next(apiContext, schema, opt)
+			}
+		},
+	}
+}
+
+// createID names the namespace of data, so creates are authorized in the namespace the object is created in
+// and not the one of the request.
+func createID(schema *types.Schema, data map[string]interface{}) string {
+	if schema.Scope != types.NamespaceScope {
+		return ""
+	}
+	if namespace := convert.ToString(data["namespaceId"]); namespace != "" {
+		return namespace + ":"
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package authz
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	authzv1 "k8s.io/api/authorization/v1"
+	authzclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// namespaceReviews allows creates in a single namespace.
+type namespaceReviews struct {
+	allowed string
+}
+
+func (n *namespaceReviews) SubjectAccessReviews() authzclient.SubjectAccessReviewInterface {
+	return n
+}
+
+func (n *namespaceReviews) Create(sar *authzv1.SubjectAccessReview) (*authzv1.SubjectAccessReview, error) {
+	sar.Status.Allowed = sar.Spec.ResourceAttributes.Namespace == n.allowed
+	return sar, nil
+}
+
+func TestCreateNamespace(t *testing.T) {
+	schema := &types.Schema{ID: "pod", PluralName: "pods", Scope: types.NamespaceScope}
+	s := Wrap(memory.NewStore(), NewSubjectAccessReview(&namespaceReviews{allowed: "a"}, "", "pods"))
+
+	tests := []struct {
+		name      string
+		query     string
+		namespace string
+		allowed   bool
+	}{
+		{"body namespace allowed", "", "a", true},
+		{"body namespace denied", "", "b", false},
+		{"body namespace wins over query", "a", "b", false},
+		{"query namespace without body namespace", "a", "", true},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest(http.MethodPost, "/", nil)
+		apiContext := &types.APIContext{
+			Request:    req,
+			Query:      url.Values{},
+			SubContext: map[string]string{},
+			Identity:   &types.Identity{Name: "alice"},
+		}
+		if test.query != "" {
+			apiContext.Query.Set("namespaceId", test.query)
+		}
+		data := map[string]interface{}{"name": "nginx"}
+		if test.namespace != "" {
+			data["namespaceId"] = test.namespace
+		}
+
+		_, err := s.Create(apiContext, schema, data)
+		if test.allowed {
+			assert.NoError(t, err, test.name)
+		} else {
+			assert.Error(t, err, test.name)
+		}
+	}
+}
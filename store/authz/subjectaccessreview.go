@@ -0,0 +1,17 @@
+package authz
+
+import (
+	"github.com/rancher/norman/authorization"
+	"github.com/rancher/norman/types"
+	authzclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// NewSubjectAccessReview returns an authorizer reviewing operations on resource of group against Kubernetes RBAC.
+// It is an authorization.RBACAccess, so stores and the API authorize with the same reviews, cache and identity.
+func NewSubjectAccessReview(client authzclient.SubjectAccessReviewsGetter, group, resource string) Authorizer {
+	access := authorization.NewRBACAccess(client, nil, 0)
+	access.Resource = func(*types.Schema) (string, string) {
+		return group, resource
+	}
+	return access
+}
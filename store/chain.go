@@ -0,0 +1,100 @@
+package store
+
+import (
+	"github.com/rancher/norman/types"
+)
+
+type ByIDFunc func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error)
+type ListFunc func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error)
+type CreateFunc func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error)
+type UpdateFunc func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error)
+type DeleteFunc func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error)
+type WatchFunc func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error)
+
+// Middleware decorates individual store verbs. Each hook receives the next function in the chain and returns
+// the function to call instead, so it can change the input, look at or change the output, or return without
+// calling next at all. Nil hooks leave the verb untouched.
+type Middleware struct {
+	ByID   func(next ByIDFunc) ByIDFunc
+	List   func(next ListFunc) ListFunc
+	Create func(next CreateFunc) CreateFunc
+	Update func(next UpdateFunc) UpdateFunc
+	Delete func(next DeleteFunc) DeleteFunc
+	Watch  func(next WatchFunc) WatchFunc
+}
+
+// Wrap applies middlewares to store. The first middleware is the outermost, it sees the request first and the
+// response last.
+func Wrap(store types.Store, middlewares ...Middleware) types.Store {
+	c := &chainStore{
+		store:  store,
+		byID:   store.ByID,
+		list:   store.List,
+		create: store.Create,
+		update: store.Update,
+		delete: store.Delete,
+		watch:  store.Watch,
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		m := middlewares[i]
+		if m.ByID != nil {
+			c.byID = m.ByID(c.byID)
+		}
+		if m.List != nil {
+			c.list = m.List(c.list)
+		}
+		if m.Create != nil {
+			c.create = m.Create(c.create)
+		}
+		if m.Update != nil {
+			c.update = m.Update(c.update)
+		}
+		if m.Delete != nil {
+			c.delete = m.Delete(c.delete)
+		}
+		if m.Watch != nil {
+			c.watch = m.Watch(c.watch)
+		}
+	}
+
+	return c
+}
+
+type chainStore struct {
+	store  types.Store
+	byID   ByIDFunc
+	list   ListFunc
+	create CreateFunc
+	update UpdateFunc
+	delete DeleteFunc
+	watch  WatchFunc
+}
+
+func (c *chainStore) Context() types.StorageContext {
+	return c.store.Context()
+}
+
+func (c *chainStore) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	return c.byID(apiContext, schema, id)
+}
+
+func (c *chainStore) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	return c.list(apiContext, schema, opt)
+}
+
+func (c *chainStore) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	return c.create(apiContext, schema, data)
+}
+
+func (c *chainStore) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	return c.update(apiContext, schema, data, id)
+}
+
+func (c *chainStore) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	return c.delete(apiContext, schema, id)
+}
+
+func (c *chainStore) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	return c.watch(apiContext, schema, opt)
+}
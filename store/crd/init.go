@@ -60,7 +60,7 @@ func (f *Factory) BatchCreateCRDs(ctx context.Context, storageContext types.Stor
 			schemasToCreate = append(schemasToCreate, s)
 		}
 
-		err := f.AssignStores(ctx, storageContext, schemasToCreate...)
+		err := f.assignStores(ctx, storageContext, schemas, schemasToCreate...)
 		if err != nil {
 			panic("creating CRD store " + err.Error())
 		}
@@ -68,7 +68,11 @@ func (f *Factory) BatchCreateCRDs(ctx context.Context, storageContext types.Stor
 }
 
 func (f *Factory) AssignStores(ctx context.Context, storageContext types.StorageContext, schemas ...*types.Schema) error {
-	schemaStatus, err := f.CreateCRDs(ctx, storageContext, schemas...)
+	return f.assignStores(ctx, storageContext, nil, schemas...)
+}
+
+func (f *Factory) assignStores(ctx context.Context, storageContext types.StorageContext, allSchemas *types.Schemas, schemas ...*types.Schema) error {
+	schemaStatus, err := f.createCRDs(ctx, storageContext, allSchemas, schemas...)
 	if err != nil {
 		return err
 	}
@@ -92,6 +96,12 @@ func (f *Factory) AssignStores(ctx context.Context, storageContext types.Storage
 }
 
 func (f *Factory) CreateCRDs(ctx context.Context, storageContext types.StorageContext, schemas ...*types.Schema) (map[*types.Schema]*apiext.CustomResourceDefinition, error) {
+	return f.createCRDs(ctx, storageContext, nil, schemas...)
+}
+
+// createCRDs creates missing CRDs. When allSchemas is set nested types are resolved for the CRD validation and
+// printer columns, otherwise only the top level fields are described.
+func (f *Factory) createCRDs(ctx context.Context, storageContext types.StorageContext, allSchemas *types.Schemas, schemas ...*types.Schema) (map[*types.Schema]*apiext.CustomResourceDefinition, error) {
 	schemaStatus := map[*types.Schema]*apiext.CustomResourceDefinition{}
 
 	apiClient, err := f.ClientGetter.APIExtClient(nil, storageContext)
@@ -105,7 +115,7 @@ func (f *Factory) CreateCRDs(ctx context.Context, storageContext types.StorageCo
 	}
 
 	for _, schema := range schemas {
-		crd, err := f.createCRD(apiClient, schema, ready)
+		crd, err := f.createCRD(apiClient, allSchemas, schema, ready)
 		if err != nil {
 			return nil, err
 		}
@@ -164,7 +174,7 @@ func (f *Factory) waitCRD(ctx context.Context, apiClient clientset.Interface, cr
 	})
 }
 
-func (f *Factory) createCRD(apiClient clientset.Interface, schema *types.Schema, ready map[string]*apiext.CustomResourceDefinition) (*apiext.CustomResourceDefinition, error) {
+func (f *Factory) createCRD(apiClient clientset.Interface, schemas *types.Schemas, schema *types.Schema, ready map[string]*apiext.CustomResourceDefinition) (*apiext.CustomResourceDefinition, error) {
 	plural := strings.ToLower(schema.PluralName)
 	name := strings.ToLower(plural + "." + schema.Version.Group)
 
@@ -181,9 +191,12 @@ func (f *Factory) createCRD(apiClient clientset.Interface, schema *types.Schema,
 			Group:   schema.Version.Group,
 			Version: schema.Version.Version,
 			Names: apiext.CustomResourceDefinitionNames{
-				Plural: plural,
-				Kind:   convert.Capitalize(schema.ID),
+				Plural:     plural,
+				Kind:       convert.Capitalize(schema.ID),
+				ShortNames: shortNames(schema, takenNames(ready)),
 			},
+			Validation:               validation(schemas, schema),
+			AdditionalPrinterColumns: printerColumns(schemas, schema),
 		},
 	}
 
@@ -0,0 +1,181 @@
+package crd
+
+import (
+	"strings"
+
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/definition"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+const maxSchemaDepth = 10
+
+var (
+	// short names used by core Kubernetes resources
+	builtinShortNames = map[string]bool{
+		"cm": true, "cs": true, "csr": true, "ds": true, "deploy": true, "ep": true, "ev": true, "hpa": true,
+		"ing": true, "limits": true, "netpol": true, "no": true, "ns": true, "pdb": true, "po": true,
+		"psp": true, "pv": true, "pvc": true, "quota": true, "rc": true, "rs": true, "sa": true, "sc": true,
+		"svc": true, "crd": true, "crds": true,
+	}
+	printerFields = []string{"displayName", "state", "phase"}
+)
+
+// validation builds the openAPIV3Schema for the internal representation of schema. Only fields whose type is
+// known are typed, nullable fields and types that can't be resolved are left open so existing objects keep
+// validating.
+func validation(schemas *types.Schemas, schema *types.Schema) *apiext.CustomResourceValidation {
+	internal := internalSchema(schema)
+	props := objectProps(schemas, internal, 0)
+	for _, name := range []string{"apiVersion", "kind", "metadata"} {
+		delete(props.Properties, name)
+	}
+
+	return &apiext.CustomResourceValidation{
+		OpenAPIV3Schema: props,
+	}
+}
+
+func internalSchema(schema *types.Schema) *types.Schema {
+	if schema != nil && schema.InternalSchema != nil {
+		return schema.InternalSchema
+	}
+	return schema
+}
+
+func objectProps(schemas *types.Schemas, schema *types.Schema, depth int) *apiext.JSONSchemaProps {
+	props := &apiext.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]apiext.JSONSchemaProps{},
+	}
+
+	for name, field := range schema.ResourceFields {
+		if field.Nullable {
+			props.Properties[name] = apiext.JSONSchemaProps{}
+			continue
+		}
+		props.Properties[name] = typeProps(schemas, schema, field.Type, depth)
+	}
+
+	return props
+}
+
+func typeProps(schemas *types.Schemas, parent *types.Schema, fieldType string, depth int) apiext.JSONSchemaProps {
+	switch {
+	case definition.IsArrayType(fieldType):
+		items := typeProps(schemas, parent, definition.SubType(fieldType), depth)
+		return apiext.JSONSchemaProps{
+			Type: "array",
+			Items: &apiext.JSONSchemaPropsOrArray{
+				Schema: &items,
+			},
+		}
+	case definition.IsMapType(fieldType):
+		values := typeProps(schemas, parent, definition.SubType(fieldType), depth)
+		return apiext.JSONSchemaProps{
+			Type: "object",
+			AdditionalProperties: &apiext.JSONSchemaPropsOrBool{
+				Allows: true,
+				Schema: &values,
+			},
+		}
+	case definition.IsReferenceType(fieldType):
+		return apiext.JSONSchemaProps{Type: "string"}
+	}
+
+	switch fieldType {
+	case "string", "password", "enum", "date", "dnsLabel", "dnsLabelRestricted", "hostname", "base64":
+		return apiext.JSONSchemaProps{Type: "string"}
+	case "int":
+		return apiext.JSONSchemaProps{Type: "integer"}
+	case "float":
+		return apiext.JSONSchemaProps{Type: "number"}
+	case "boolean":
+		return apiext.JSONSchemaProps{Type: "boolean"}
+	}
+
+	if schemas == nil || depth >= maxSchemaDepth {
+		return apiext.JSONSchemaProps{}
+	}
+
+	sub := internalSchema(schemas.Schema(&parent.Version, fieldType))
+	if sub == nil {
+		return apiext.JSONSchemaProps{}
+	}
+	return *objectProps(schemas, sub, depth+1)
+}
+
+func printerColumns(schemas *types.Schemas, schema *types.Schema) []apiext.CustomResourceColumnDefinition {
+	var columns []apiext.CustomResourceColumnDefinition
+
+	internal := internalSchema(schema)
+	for _, parentField := range []string{"spec", "status"} {
+		field, ok := internal.ResourceFields[parentField]
+		if !ok || schemas == nil {
+			continue
+		}
+		sub := internalSchema(schemas.Schema(&internal.Version, field.Type))
+		if sub == nil {
+			continue
+		}
+		for _, name := range printerFields {
+			if f, ok := sub.ResourceFields[name]; ok && (f.Type == "string" || f.Type == "enum") {
+				columns = append(columns, apiext.CustomResourceColumnDefinition{
+					Name:     columnName(name),
+					Type:     "string",
+					JSONPath: "." + parentField + "." + name,
+				})
+			}
+		}
+	}
+
+	return append(columns, apiext.CustomResourceColumnDefinition{
+		Name:     "Age",
+		Type:     "date",
+		JSONPath: ".metadata.creationTimestamp",
+	})
+}
+
+func columnName(field string) string {
+	var words []string
+	start := 0
+	for i := 1; i < len(field); i++ {
+		if field[i] >= 'A' && field[i] <= 'Z' {
+			words = append(words, field[start:i])
+			start = i
+		}
+	}
+	words = append(words, field[start:])
+	for i, word := range words {
+		words[i] = strings.Title(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// shortNames uses the initials of the camel cased schema ID, such as "np" for nodePool, unless that name is
+// already taken. Single word IDs don't get a short name since a single letter is too likely to collide.
+func shortNames(schema *types.Schema, taken map[string]bool) []string {
+	short := strings.ToLower(schema.ID[:1])
+	for _, c := range schema.ID[1:] {
+		if c >= 'A' && c <= 'Z' {
+			short += strings.ToLower(string(c))
+		}
+	}
+
+	if len(short) < 2 || builtinShortNames[short] || taken[short] {
+		return nil
+	}
+	return []string{short}
+}
+
+func takenNames(ready map[string]*apiext.CustomResourceDefinition) map[string]bool {
+	taken := map[string]bool{}
+	for _, crd := range ready {
+		taken[crd.Spec.Names.Plural] = true
+		taken[crd.Spec.Names.Singular] = true
+		for _, name := range crd.Spec.Names.ShortNames {
+			taken[name] = true
+		}
+	}
+	return taken
+}
@@ -0,0 +1,161 @@
+package crd
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+type nodePoolSpec struct {
+	DisplayName string               `json:"displayName"`
+	Quantity    int64                `json:"quantity"`
+	Ratio       float64              `json:"ratio"`
+	Enabled     bool                 `json:"enabled"`
+	Optional    *int64               `json:"optional"`
+	Names       []string             `json:"names"`
+	Labels      map[string]string    `json:"labels"`
+	ClusterID   string               `json:"clusterId" norman:"type=reference[cluster]"`
+	Taints      []nodeTaint          `json:"taints"`
+	Templates   map[string]nodeTaint `json:"templates"`
+}
+
+type nodeTaint struct {
+	Key string `json:"key"`
+}
+
+type nodePool struct {
+	types.Namespaced
+	Spec nodePoolSpec `json:"spec"`
+}
+
+var (
+	stringProps  = apiext.JSONSchemaProps{Type: "string"}
+	integerProps = apiext.JSONSchemaProps{Type: "integer"}
+	taintProps   = apiext.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]apiext.JSONSchemaProps{"key": {}},
+	}
+)
+
+func newTestSchemas() (*types.Schemas, *types.Schema) {
+	version := types.APIVersion{Group: "test.cattle.io", Version: "v3", Path: "/v3"}
+	schemas := types.NewSchemas().MustImport(&version, nodePool{})
+	return schemas, schemas.Schema(&version, "nodePool")
+}
+
+func TestObjectProps(t *testing.T) {
+	schemas, schema := newTestSchemas()
+	spec := schemas.Schema(&schema.Version, "nodePoolSpec")
+
+	// Strings, arrays, maps and pointers are nullable, so they are left open
+	tests := []struct {
+		field    string
+		expected apiext.JSONSchemaProps
+	}{
+		{"displayName", apiext.JSONSchemaProps{}},
+		{"quantity", integerProps},
+		{"ratio", apiext.JSONSchemaProps{Type: "number"}},
+		{"enabled", apiext.JSONSchemaProps{Type: "boolean"}},
+		{"optional", apiext.JSONSchemaProps{}},
+		{"names", apiext.JSONSchemaProps{}},
+		{"labels", apiext.JSONSchemaProps{}},
+		{"clusterId", apiext.JSONSchemaProps{}},
+		{"taints", apiext.JSONSchemaProps{}},
+		{"templates", apiext.JSONSchemaProps{}},
+	}
+
+	props := objectProps(schemas, spec, 0)
+	assert.Equal(t, "object", props.Type)
+	for _, test := range tests {
+		assert.Equal(t, test.expected, props.Properties[test.field], test.field)
+	}
+	assert.Len(t, props.Properties, len(tests))
+}
+
+func TestTypeProps(t *testing.T) {
+	schemas, schema := newTestSchemas()
+
+	tests := []struct {
+		name      string
+		schemas   *types.Schemas
+		fieldType string
+		depth     int
+		expected  apiext.JSONSchemaProps
+	}{
+		{"string", schemas, "string", 0, stringProps},
+		{"enum", schemas, "enum", 0, stringProps},
+		{"int", schemas, "int", 0, integerProps},
+		{"reference", schemas, "reference[cluster]", 0, stringProps},
+		{"array", schemas, "array[string]", 0, apiext.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiext.JSONSchemaPropsOrArray{Schema: &stringProps},
+		}},
+		{"array of references", schemas, "array[reference[cluster]]", 0, apiext.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiext.JSONSchemaPropsOrArray{Schema: &stringProps},
+		}},
+		{"map", schemas, "map[int]", 0, apiext.JSONSchemaProps{
+			Type:                 "object",
+			AdditionalProperties: &apiext.JSONSchemaPropsOrBool{Allows: true, Schema: &integerProps},
+		}},
+		{"array of objects", schemas, "array[nodeTaint]", 0, apiext.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiext.JSONSchemaPropsOrArray{Schema: &taintProps},
+		}},
+		{"map of objects", schemas, "map[nodeTaint]", 0, apiext.JSONSchemaProps{
+			Type:                 "object",
+			AdditionalProperties: &apiext.JSONSchemaPropsOrBool{Allows: true, Schema: &taintProps},
+		}},
+		{"unknown type", schemas, "missing", 0, apiext.JSONSchemaProps{}},
+		{"without schemas", nil, "nodeTaint", 0, apiext.JSONSchemaProps{}},
+		{"too deep", schemas, "nodeTaint", maxSchemaDepth, apiext.JSONSchemaProps{}},
+		{"array of unknown type", schemas, "array[missing]", 0, apiext.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiext.JSONSchemaPropsOrArray{Schema: &apiext.JSONSchemaProps{}},
+		}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, typeProps(test.schemas, schema, test.fieldType, test.depth), test.name)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	schemas, schema := newTestSchemas()
+
+	props := validation(schemas, schema).OpenAPIV3Schema
+	assert.Equal(t, "object", props.Type)
+	assert.NotContains(t, props.Properties, "apiVersion")
+	assert.NotContains(t, props.Properties, "kind")
+	assert.NotContains(t, props.Properties, "metadata")
+	assert.Contains(t, props.Properties, "spec")
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[string]string{
+		"state":       "State",
+		"displayName": "Display Name",
+		"a":           "A",
+	}
+	for field, expected := range tests {
+		assert.Equal(t, expected, columnName(field))
+	}
+}
+
+func TestShortNames(t *testing.T) {
+	tests := []struct {
+		id       string
+		taken    map[string]bool
+		expected []string
+	}{
+		{"nodePool", nil, []string{"np"}},
+		{"nodePool", map[string]bool{"np": true}, nil},
+		{"node", nil, nil},
+		{"podSecurityPolicy", nil, nil},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, shortNames(&types.Schema{ID: test.id}, test.taken), test.id)
+	}
+}
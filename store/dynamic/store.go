@@ -0,0 +1,266 @@
+package dynamic
+
+import (
+	"strings"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/convert/merge"
+	"github.com/rancher/norman/types/values"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicclient "k8s.io/client-go/dynamic"
+)
+
+// Store serves any Kubernetes resource as plain maps through the dynamic client, without generated types or a
+// compiled in schema. IDs are "<namespace>:<name>" for namespaced resources and "<name>" otherwise.
+type Store struct {
+	client     dynamicclient.Interface
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+}
+
+func NewStore(client dynamicclient.Interface, gvr schema.GroupVersionResource, kind string, namespaced bool) *Store {
+	return &Store{
+		client:     client,
+		gvr:        gvr,
+		kind:       kind,
+		namespaced: namespaced,
+	}
+}
+
+// NewSchema returns a schema without fields for the resource, served by a new Store. Objects are passed through
+// as is.
+func NewSchema(version *types.APIVersion, client dynamicclient.Interface, gvr schema.GroupVersionResource, kind string, namespaced bool) *types.Schema {
+	s := &types.Schema{
+		ID:                convert.LowerTitle(kind),
+		CodeName:          kind,
+		PluralName:        gvr.Resource,
+		Version:           *version,
+		ResourceMethods:   []string{"GET", "PUT", "DELETE"},
+		CollectionMethods: []string{"GET", "POST"},
+		ResourceFields:    map[string]types.Field{},
+		Store:             NewStore(client, gvr, kind, namespaced),
+	}
+	if namespaced {
+		s.Scope = types.NamespaceScope
+	}
+	return s
+}
+
+func (s *Store) Context() types.StorageContext {
+	return types.DefaultStorageContext
+}
+
+func (s *Store) resource(namespace string) dynamicclient.ResourceInterface {
+	if s.namespaced && namespace != "" {
+		return s.client.Resource(s.gvr).Namespace(namespace)
+	}
+	return s.client.Resource(s.gvr)
+}
+
+func (s *Store) splitID(id string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimSpace(id), ":", 2)
+	if s.namespaced {
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return "", "", httperror.NewAPIError(httperror.NotFound, "failed to find resource by id")
+		}
+		return parts[0], parts[1], nil
+	}
+	if len(parts) != 1 || parts[0] == "" {
+		return "", "", httperror.NewAPIError(httperror.NotFound, "failed to find resource by id")
+	}
+	return "", parts[0], nil
+}
+
+func (s *Store) fromInternal(obj *unstructured.Unstructured) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	data := obj.Object
+	if s.namespaced {
+		data["id"] = obj.GetNamespace() + ":" + obj.GetName()
+		data["namespaceId"] = obj.GetNamespace()
+	} else {
+		data["id"] = obj.GetName()
+	}
+	return data
+}
+
+func (s *Store) toInternal(data map[string]interface{}) *unstructured.Unstructured {
+	delete(data, "id")
+	delete(data, "type")
+	delete(data, "links")
+	delete(data, "actions")
+	if ns, ok := data["namespaceId"]; ok {
+		values.PutValue(data, ns, "metadata", "namespace")
+		delete(data, "namespaceId")
+	}
+
+	obj := &unstructured.Unstructured{Object: data}
+	obj.SetAPIVersion(s.gvr.GroupVersion().String())
+	obj.SetKind(s.kind)
+	return obj
+}
+
+func (s *Store) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	namespace, name, err := s.splitID(id)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.resource(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.fromInternal(obj), nil
+}
+
+func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	list, err := s.resource(namespace(opt)).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var result []map[string]interface{}
+	for i := range list.Items {
+		result = append(result, s.fromInternal(&list.Items[i]))
+	}
+	return result, nil
+}
+
+func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	obj := s.toInternal(data)
+	if obj.GetName() == "" && obj.GetGenerateName() == "" {
+		name, err := schema.GenerateObjectName(func(name string) (bool, error) {
+			_, err := s.resource(obj.GetNamespace()).Get(name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return err == nil, err
+		})
+		if err != nil {
+			return nil, translateError(err)
+		}
+		obj.SetName(name)
+	}
+
+	result, err := s.resource(obj.GetNamespace()).Create(obj, metav1.CreateOptions{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.fromInternal(result), nil
+}
+
+func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	namespace, name, err := s.splitID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := s.resource(namespace)
+	obj := s.toInternal(data)
+	for i := 0; i < 5; i++ {
+		existing, err := resource.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		merged := merge.APIUpdateMerge(schema, apiContext.Schemas, existing.Object, values.DeepCopyMap(obj.Object),
+			apiContext.Option("replace") == "true")
+		existing.Object = merged
+		existing.SetName(name)
+		existing.SetNamespace(namespace)
+
+		result, err := resource.Update(existing, metav1.UpdateOptions{})
+		if errors.IsConflict(err) {
+			continue
+		}
+		if err != nil {
+			return nil, translateError(err)
+		}
+		return s.fromInternal(result), nil
+	}
+
+	return nil, httperror.NewAPIError(httperror.Conflict, "failed to update "+id+" after retries")
+}
+
+func (s *Store) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	namespace, name, err := s.splitID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	prop := metav1.DeletePropagationBackground
+	if err := s.resource(namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &prop}); err != nil {
+		return nil, translateError(err)
+	}
+
+	obj, err := s.resource(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil
+	}
+	return s.fromInternal(obj), nil
+}
+
+// Watch streams changes until the request context is done or the server closes the watch.
+func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	watcher, err := s.resource(namespace(opt)).Watch(metav1.ListOptions{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	ctx := apiContext.Request.Context()
+	result := make(chan map[string]interface{})
+	go func() {
+		<-ctx.Done()
+		watcher.Stop()
+	}()
+
+	go func() {
+		defer close(result)
+		for event := range watcher.ResultChan() {
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			data := s.fromInternal(obj)
+			if event.Type == watch.Deleted {
+				data[".removed"] = true
+			}
+			select {
+			case result <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+func namespace(opt *types.QueryOptions) string {
+	if opt == nil {
+		return ""
+	}
+	for _, condition := range opt.Conditions {
+		if (condition.Field == "namespaceId" || condition.Field == "namespace") &&
+			condition.ToCondition().Modifier == types.ModifierEQ {
+			return condition.Value
+		}
+	}
+	return ""
+}
+
+// translateError returns the errors of the Kubernetes API as API errors with the same status, like the proxy store.
+func translateError(err error) error {
+	if apiError, ok := err.(errors.APIStatus); ok {
+		status := apiError.Status()
+		return httperror.NewAPIErrorLong(int(status.Code), string(status.Reason), status.Message)
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+package dynamic
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTranslateError(t *testing.T) {
+	err := translateError(errors.NewNotFound(schema.GroupResource{Resource: "widgets"}, "foo"))
+	if assert.True(t, httperror.IsNotFound(err)) {
+		assert.Equal(t, `widgets "foo" not found`, err.(*httperror.APIError).Message)
+	}
+
+	err = translateError(errors.NewConflict(schema.GroupResource{Resource: "widgets"}, "foo", nil))
+	if assert.True(t, httperror.IsAPIError(err)) {
+		assert.Equal(t, httperror.Conflict.Status, err.(*httperror.APIError).Code.Status)
+	}
+}
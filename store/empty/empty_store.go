@@ -1,6 +1,7 @@
 package empty
 
 import (
+	"github.com/rancher/norman/httperror"
 	"github.com/rancher/norman/types"
 )
 
@@ -31,6 +32,16 @@ func (e *Store) Update(apiContext *types.APIContext, schema *types.Schema, data
 	return nil, nil
 }
 
+// Watch returns a channel without events, closed when the request context is done.
 func (e *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
-	return nil, nil
+	if apiContext.Request == nil {
+		return nil, httperror.NewAPIError(httperror.ServerError, "watching "+schema.ID+" requires a request")
+	}
+
+	c := make(chan map[string]interface{})
+	go func() {
+		<-apiContext.Request.Context().Done()
+		close(c)
+	}()
+	return c, nil
 }
@@ -0,0 +1,59 @@
+package encryption
+
+import (
+	"fmt"
+	"sync"
+)
+
+// KeyProvider supplies the keys used to encrypt fields. New values are always encrypted with the current key,
+// older keys are looked up by ID so values written before a rotation can still be read.
+type KeyProvider interface {
+	CurrentKey() (string, []byte, error)
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider holding AES keys in memory. Keys must be 16, 24 or 32 bytes long.
+type StaticKeys struct {
+	sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+func NewStaticKeys(currentID string, keys map[string][]byte) *StaticKeys {
+	k := &StaticKeys{
+		current: currentID,
+		keys:    map[string][]byte{},
+	}
+	for id, key := range keys {
+		k.keys[id] = key
+	}
+	return k
+}
+
+// Rotate adds key and makes it the key used for new writes. Existing keys are kept for decryption.
+func (s *StaticKeys) Rotate(id string, key []byte) {
+	s.Lock()
+	defer s.Unlock()
+	s.keys[id] = key
+	s.current = id
+}
+
+func (s *StaticKeys) CurrentKey() (string, []byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+	key, ok := s.keys[s.current]
+	if !ok {
+		return "", nil, fmt.Errorf("current encryption key %s not found", s.current)
+	}
+	return s.current, key, nil
+}
+
+func (s *StaticKeys) Key(id string) ([]byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %s not found", id)
+	}
+	return key, nil
+}
@@ -0,0 +1,278 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/values"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	prefix = "enc:v2:"
+	// legacyPrefix marks values sealed without associated data, they are still read and sealed again on write
+	legacyPrefix = "enc:v1:"
+)
+
+// Wrap encrypts fields before they are written to s and decrypts them when read. The fields are the password
+// fields of the schema plus the given fields, nested fields are addressed with dots. Values that aren't
+// encrypted yet are returned as is, so existing data is encrypted on its next write. Every value written is
+// encrypted, including values that already look encrypted, so user values can't be mistaken for encrypted ones.
+// Values are bound to the schema, field and object ID, so they can't be copied to another field or object. Objects
+// created without a name are given one, as their ID has to be known before they are written. Lists and watches
+// return objects whose values can't be decrypted with the values cleared.
+func Wrap(s types.Store, keys KeyProvider, fields ...string) types.Store {
+	return store.Wrap(s, Middleware(keys, fields...))
+}
+
+func Middleware(keys KeyProvider, fields ...string) store.Middleware {
+	e := &encrypter{
+		keys:   keys,
+		fields: fields,
+	}
+
+	return store.Middleware{
+		ByID: func(next store.ByIDFunc) store.ByIDFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+				return e.decrypt(schema)(next(apiContext, schema, id))
+			}
+		},
+		List: func(next store.ListFunc) store.ListFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+				data, err := next(apiContext, schema, opt)
+				if err != nil {
+					return nil, err
+				}
+				for _, obj := range data {
+					e.decryptOrClear(schema, obj)
+				}
+				return data, nil
+			}
+		},
+		Create: func(next store.CreateFunc) store.CreateFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+				id, err := createID(schema, data)
+				if err != nil {
+					return nil, err
+				}
+				if err := e.encrypt(schema, data, id); err != nil {
+					return nil, err
+				}
+				return e.decrypt(schema)(next(apiContext, schema, data))
+			}
+		},
+		Update: func(next store.UpdateFunc) store.UpdateFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+				if err := e.encrypt(schema, data, id); err != nil {
+					return nil, err
+				}
+				return e.decrypt(schema)(next(apiContext, schema, data, id))
+			}
+		},
+		Delete: func(next store.DeleteFunc) store.DeleteFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+				return e.decrypt(schema)(next(apiContext, schema, id))
+			}
+		},
+		Watch: func(next store.WatchFunc) store.WatchFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+				c, err := next(apiContext, schema, opt)
+				if err != nil || c == nil {
+					return c, err
+				}
+				return convert.Chan(c, func(data map[string]interface{}) map[string]interface{} {
+					e.decryptOrClear(schema, data)
+					return data
+				}), nil
+			}
+		},
+	}
+}
+
+type encrypter struct {
+	keys   KeyProvider
+	fields []string
+}
+
+func (e *encrypter) fieldsFor(schema *types.Schema) [][]string {
+	var result [][]string
+	for name, field := range schema.ResourceFields {
+		if field.Type == "password" {
+			result = append(result, []string{name})
+		}
+	}
+	for _, field := range e.fields {
+		result = append(result, strings.Split(field, "."))
+	}
+	return result
+}
+
+// createID returns the ID data will be created with, naming data if the store would pick the name.
+func createID(schema *types.Schema, data map[string]interface{}) (string, error) {
+	if id := convert.ToString(data["id"]); id != "" {
+		return id, nil
+	}
+	name := convert.ToString(data["name"])
+	if name == "" {
+		var err error
+		name, err = schema.GenerateObjectName(func(string) (bool, error) {
+			return false, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		data["name"] = name
+	}
+	if namespace := convert.ToString(data["namespaceId"]); schema.Scope == types.NamespaceScope && namespace != "" {
+		return namespace + ":" + name, nil
+	}
+	return name, nil
+}
+
+// additionalData binds a sealed value to the field of an object.
+func additionalData(schema *types.Schema, path []string, id string) []byte {
+	return []byte(schema.ID + "\x00" + strings.Join(path, ".") + "\x00" + id)
+}
+
+func (e *encrypter) encrypt(schema *types.Schema, data map[string]interface{}, id string) error {
+	for _, path := range e.fieldsFor(schema) {
+		val, ok := values.GetValue(data, path...)
+		if !ok {
+			continue
+		}
+		// Values starting with prefix are sealed too, so a stored value with the prefix is always encrypted
+		str, ok := val.(string)
+		if !ok || str == "" {
+			continue
+		}
+		encrypted, err := e.seal(str, additionalData(schema, path, id))
+		if err != nil {
+			return err
+		}
+		values.PutValue(data, encrypted, path...)
+	}
+	return nil
+}
+
+func (e *encrypter) decrypt(schema *types.Schema) func(map[string]interface{}, error) (map[string]interface{}, error) {
+	return func(data map[string]interface{}, err error) (map[string]interface{}, error) {
+		if err != nil || data == nil {
+			return data, err
+		}
+		for _, path := range e.fieldsFor(schema) {
+			if err := e.decryptField(schema, data, path); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}
+}
+
+// decryptOrClear decrypts the fields of data, clearing the values that can't be decrypted so a single broken
+// object doesn't fail a whole list.
+func (e *encrypter) decryptOrClear(schema *types.Schema, data map[string]interface{}) {
+	if data == nil {
+		return
+	}
+	for _, path := range e.fieldsFor(schema) {
+		if err := e.decryptField(schema, data, path); err != nil {
+			logrus.Errorf("Failed to decrypt %s of %s %s, clearing it: %v", strings.Join(path, "."), schema.ID,
+				convert.ToString(data["id"]), err)
+			values.PutValue(data, "", path...)
+		}
+	}
+}
+
+func (e *encrypter) decryptField(schema *types.Schema, data map[string]interface{}, path []string) error {
+	val, ok := values.GetValue(data, path...)
+	if !ok {
+		return nil
+	}
+	str, ok := val.(string)
+	if !ok {
+		return nil
+	}
+
+	var (
+		plain string
+		err   error
+	)
+	switch {
+	case strings.HasPrefix(str, prefix):
+		plain, err = e.open(strings.TrimPrefix(str, prefix), additionalData(schema, path, convert.ToString(data["id"])))
+	case strings.HasPrefix(str, legacyPrefix):
+		plain, err = e.open(strings.TrimPrefix(str, legacyPrefix), nil)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	values.PutValue(data, plain, path...)
+	return nil
+}
+
+func (e *encrypter) seal(plain string, additionalData []byte) (string, error) {
+	id, key, err := e.keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), additionalData)
+	return prefix + id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts value, the sealed value without its prefix.
+func (e *encrypter) open(value string, additionalData []byte) (string, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid encrypted value")
+	}
+
+	key, err := e.keys.Key(parts[0])
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted value")
+	}
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], additionalData)
+	return string(plain), err
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,184 @@
+package encryption
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptRotate(t *testing.T) {
+	keys := NewStaticKeys("one", map[string][]byte{
+		"one": []byte("0123456789abcdef"),
+	})
+	backing := memory.NewStore()
+	s := Wrap(backing, keys, "spec.token")
+
+	schema := &types.Schema{
+		ID: "foo",
+		ResourceFields: map[string]types.Field{
+			"password": {Type: "password"},
+		},
+	}
+	apiContext := &types.APIContext{}
+
+	obj, err := s.Create(apiContext, schema, map[string]interface{}{
+		"name":     "a",
+		"password": "secret",
+		"spec": map[string]interface{}{
+			"token": "abc",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", obj["password"])
+
+	raw, _ := backing.ByID(apiContext, schema, "a")
+	assert.True(t, strings.HasPrefix(raw["password"].(string), prefix+"one:"))
+	assert.True(t, strings.HasPrefix(raw["spec"].(map[string]interface{})["token"].(string), prefix+"one:"))
+
+	keys.Rotate("two", []byte("fedcba9876543210"))
+	obj, err = s.ByID(apiContext, schema, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", obj["password"])
+	assert.Equal(t, "abc", obj["spec"].(map[string]interface{})["token"])
+}
+
+func TestEncryptPrefixedValue(t *testing.T) {
+	keys := NewStaticKeys("one", map[string][]byte{
+		"one": []byte("0123456789abcdef"),
+	})
+	backing := memory.NewStore()
+	s := Wrap(backing, keys)
+
+	schema := &types.Schema{
+		ID: "foo",
+		ResourceFields: map[string]types.Field{
+			"password": {Type: "password"},
+		},
+	}
+	apiContext := &types.APIContext{}
+
+	value := prefix + "one:notbase64"
+	obj, err := s.Create(apiContext, schema, map[string]interface{}{
+		"name":     "a",
+		"password": value,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, value, obj["password"])
+
+	raw, _ := backing.ByID(apiContext, schema, "a")
+	assert.NotEqual(t, value, raw["password"])
+
+	obj, err = s.ByID(apiContext, schema, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, value, obj["password"])
+}
+
+func TestEncryptBoundToObject(t *testing.T) {
+	keys := NewStaticKeys("one", map[string][]byte{
+		"one": []byte("0123456789abcdef"),
+	})
+	backing := memory.NewStore()
+	s := Wrap(backing, keys, "token")
+
+	schema := &types.Schema{
+		ID: "foo",
+		ResourceFields: map[string]types.Field{
+			"password": {Type: "password"},
+		},
+	}
+	apiContext := &types.APIContext{}
+
+	for _, name := range []string{"a", "b"} {
+		_, err := s.Create(apiContext, schema, map[string]interface{}{
+			"name":     name,
+			"password": "secret-" + name,
+			"token":    "token-" + name,
+		})
+		assert.Nil(t, err)
+	}
+
+	// Values copied to another object or field can't be decrypted
+	rawA, _ := backing.ByID(apiContext, schema, "a")
+	rawB, _ := backing.ByID(apiContext, schema, "b")
+	_, err := backing.Update(apiContext, schema, map[string]interface{}{
+		"password": rawB["password"],
+		"token":    rawA["password"],
+	}, "a")
+	assert.Nil(t, err)
+
+	_, err = s.ByID(apiContext, schema, "a")
+	assert.NotNil(t, err)
+
+	// A list returns the broken object with the values cleared
+	objs, err := s.List(apiContext, schema, nil)
+	assert.Nil(t, err)
+	passwords := map[string]string{}
+	tokens := map[string]string{}
+	for _, obj := range objs {
+		passwords[obj["name"].(string)] = obj["password"].(string)
+		tokens[obj["name"].(string)] = obj["token"].(string)
+	}
+	assert.Equal(t, map[string]string{"a": "", "b": "secret-b"}, passwords)
+	assert.Equal(t, map[string]string{"a": "", "b": "token-b"}, tokens)
+}
+
+func TestEncryptLegacyValue(t *testing.T) {
+	keys := NewStaticKeys("one", map[string][]byte{
+		"one": []byte("0123456789abcdef"),
+	})
+	backing := memory.NewStore()
+	s := Wrap(backing, keys)
+	e := &encrypter{keys: keys}
+
+	schema := &types.Schema{
+		ID: "foo",
+		ResourceFields: map[string]types.Field{
+			"password": {Type: "password"},
+		},
+	}
+	apiContext := &types.APIContext{}
+
+	sealed, err := e.seal("secret", nil)
+	assert.Nil(t, err)
+	_, err = backing.Create(apiContext, schema, map[string]interface{}{
+		"name":     "a",
+		"password": legacyPrefix + strings.TrimPrefix(sealed, prefix),
+	})
+	assert.Nil(t, err)
+
+	obj, err := s.ByID(apiContext, schema, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", obj["password"])
+}
+
+func TestEncryptGeneratedName(t *testing.T) {
+	keys := NewStaticKeys("one", map[string][]byte{
+		"one": []byte("0123456789abcdef"),
+	})
+	s := Wrap(memory.NewStore(), keys)
+
+	schema := &types.Schema{
+		ID:    "foo",
+		Scope: types.NamespaceScope,
+		ResourceFields: map[string]types.Field{
+			"password": {Type: "password"},
+		},
+	}
+	apiContext := &types.APIContext{}
+
+	obj, err := s.Create(apiContext, schema, map[string]interface{}{
+		"namespaceId": "ns",
+		"password":    "secret",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.NotEmpty(t, obj["name"])
+
+	obj, err = s.ByID(apiContext, schema, obj["id"].(string))
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", obj["password"])
+}
@@ -0,0 +1,122 @@
+package fallback
+
+import (
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Condition decides from the result of the primary store whether the secondary store is tried. empty is true
+// when the primary returned no object or an empty list.
+type Condition func(empty bool, err error) bool
+
+// Never keeps the result of the primary store.
+func Never(empty bool, err error) bool {
+	return false
+}
+
+// OnError falls back when the primary store fails.
+func OnError(empty bool, err error) bool {
+	return err != nil
+}
+
+// OnNotFound falls back when the primary store doesn't have the object.
+func OnNotFound(empty bool, err error) bool {
+	return httperror.IsNotFound(err) || (err == nil && empty)
+}
+
+// OnErrorOrEmpty falls back when the primary store fails or has nothing.
+func OnErrorOrEmpty(empty bool, err error) bool {
+	return err != nil || empty
+}
+
+// DefaultConditions fall back on reads only, so writes always go to the primary store.
+var DefaultConditions = map[string]Condition{
+	types.VerbGet:   OnNotFound,
+	types.VerbList:  OnError,
+	types.VerbWatch: OnError,
+}
+
+// Store sends every operation to Primary and retries it on Secondary when the Condition configured for the verb
+// matches, for example to read through a cache or to move a resource to a new backend while the old one still
+// serves the objects not yet migrated. Verbs without a condition never fall back.
+type Store struct {
+	Primary    types.Store
+	Secondary  types.Store
+	Conditions map[string]Condition
+}
+
+func NewStore(primary, secondary types.Store) *Store {
+	conditions := map[string]Condition{}
+	for verb, condition := range DefaultConditions {
+		conditions[verb] = condition
+	}
+	return &Store{
+		Primary:    primary,
+		Secondary:  secondary,
+		Conditions: conditions,
+	}
+}
+
+func (s *Store) Context() types.StorageContext {
+	return s.Primary.Context()
+}
+
+func (s *Store) fallback(schema *types.Schema, verb string, empty bool, err error) bool {
+	condition := s.Conditions[verb]
+	if s.Secondary == nil || condition == nil || !condition(empty, err) {
+		return false
+	}
+	if err != nil {
+		logrus.Debugf("falling back on %s %s: %v", verb, schema.ID, err)
+	}
+	return true
+}
+
+func (s *Store) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	data, err := s.Primary.ByID(apiContext, schema, id)
+	if s.fallback(schema, types.VerbGet, data == nil, err) {
+		return s.Secondary.ByID(apiContext, schema, id)
+	}
+	return data, err
+}
+
+func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	data, err := s.Primary.List(apiContext, schema, opt)
+	if s.fallback(schema, types.VerbList, len(data) == 0, err) {
+		return s.Secondary.List(apiContext, schema, opt)
+	}
+	return data, err
+}
+
+func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	c, err := s.Primary.Watch(apiContext, schema, opt)
+	if s.fallback(schema, types.VerbWatch, c == nil, err) {
+		return s.Secondary.Watch(apiContext, schema, opt)
+	}
+	return c, err
+}
+
+func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	result, err := s.Primary.Create(apiContext, schema, data)
+	if s.fallback(schema, types.VerbCreate, result == nil, err) {
+		return s.Secondary.Create(apiContext, schema, data)
+	}
+	return result, err
+}
+
+func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	result, err := s.Primary.Update(apiContext, schema, data, id)
+	if s.fallback(schema, types.VerbUpdate, result == nil, err) {
+		return s.Secondary.Update(apiContext, schema, data, id)
+	}
+	return result, err
+}
+
+func (s *Store) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	result, err := s.Primary.Delete(apiContext, schema, id)
+	if s.fallback(schema, types.VerbDelete, result == nil, err) {
+		return s.Secondary.Delete(apiContext, schema, id)
+	}
+	return result, err
+}
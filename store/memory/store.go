@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rancher/norman/api/handler"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/convert/merge"
+	"github.com/rancher/norman/types/values"
+)
+
+// Store is a thread safe store keeping all objects in memory, meant for tests and prototypes. Objects are copied
+// on the way in and out so callers can't modify the stored data.
+type Store struct {
+	sync.RWMutex
+	objects  map[string]map[string]map[string]interface{}
+	watchers map[chan map[string]interface{}]watcher
+}
+
+type watcher struct {
+	schema     *types.Schema
+	conditions []*types.QueryCondition
+}
+
+func NewStore() *Store {
+	return &Store{
+		objects:  map[string]map[string]map[string]interface{}{},
+		watchers: map[chan map[string]interface{}]watcher{},
+	}
+}
+
+func (s *Store) Context() types.StorageContext {
+	return types.DefaultStorageContext
+}
+
+func (s *Store) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	obj, ok := s.objects[schema.ID][id]
+	if !ok {
+		return nil, httperror.NewAPIError(httperror.NotFound, "failed to find "+schema.ID+" "+id)
+	}
+	return values.DeepCopyMap(obj), nil
+}
+
+// List returns the objects matching the conditions of opt, sorted. Pagination is left to the API layer.
+func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	s.RLock()
+	var result []map[string]interface{}
+	for _, obj := range s.objects[schema.ID] {
+		result = append(result, values.DeepCopyMap(obj))
+	}
+	s.RUnlock()
+
+	if opt == nil {
+		return handler.ApplySort(types.Sort{}, result), nil
+	}
+	result = handler.ApplyQueryConditions(opt.Conditions, schema, result)
+	return handler.ApplySort(opt.Sort, result), nil
+}
+
+func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	data = values.DeepCopyMap(data)
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	id := convert.ToString(data["id"])
+	if id == "" {
+		ns := convert.ToString(data["namespaceId"])
+		toID := func(name string) string {
+			if schema.Scope == types.NamespaceScope && ns != "" {
+				return ns + ":" + name
+			}
+			return name
+		}
+
+		name := convert.ToString(data["name"])
+		if name == "" {
+			var err error
+			name, err = schema.GenerateObjectName(func(name string) (bool, error) {
+				s.RLock()
+				defer s.RUnlock()
+				_, ok := s.objects[schema.ID][toID(name)]
+				return ok, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		id = toID(name)
+	}
+
+	data["id"] = id
+	data["type"] = schema.ID
+	if _, ok := data["created"]; !ok {
+		data["created"] = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.objects[schema.ID][id]; ok {
+		return nil, httperror.NewAPIError(httperror.NotUnique, "resource "+id+" already exists")
+	}
+	s.put(schema.ID, id, data)
+	return values.DeepCopyMap(data), nil
+}
+
+func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	existing, ok := s.objects[schema.ID][id]
+	if !ok {
+		return nil, httperror.NewAPIError(httperror.NotFound, "failed to find "+schema.ID+" "+id)
+	}
+
+	result := merge.APIUpdateMerge(schema, apiContext.Schemas, values.DeepCopyMap(existing), values.DeepCopyMap(data),
+		apiContext.Option("replace") == "true")
+	result["id"] = id
+	result["type"] = schema.ID
+	s.put(schema.ID, id, result)
+	return values.DeepCopyMap(result), nil
+}
+
+func (s *Store) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	existing, ok := s.objects[schema.ID][id]
+	if !ok {
+		return nil, httperror.NewAPIError(httperror.NotFound, "failed to find "+schema.ID+" "+id)
+	}
+	delete(s.objects[schema.ID], id)
+
+	removed := values.DeepCopyMap(existing)
+	removed[".removed"] = true
+	s.notify(schema.ID, removed)
+
+	return values.DeepCopyMap(existing), nil
+}
+
+// Watch returns the changes to objects of the schema matching the conditions of opt until the request context is
+// done. Watches need a request, nothing else would end them.
+func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	if apiContext.Request == nil {
+		return nil, httperror.NewAPIError(httperror.ServerError, "watching "+schema.ID+" requires a request")
+	}
+
+	c := make(chan map[string]interface{}, 100)
+
+	w := watcher{schema: schema}
+	if opt != nil {
+		w.conditions = opt.Conditions
+	}
+	s.Lock()
+	s.watchers[c] = w
+	s.Unlock()
+
+	go func() {
+		<-apiContext.Request.Context().Done()
+		s.Lock()
+		delete(s.watchers, c)
+		close(c)
+		s.Unlock()
+	}()
+
+	return c, nil
+}
+
+// put stores data and notifies watchers, the caller must hold the lock.
+func (s *Store) put(schemaID, id string, data map[string]interface{}) {
+	if s.objects[schemaID] == nil {
+		s.objects[schemaID] = map[string]map[string]interface{}{}
+	}
+	s.objects[schemaID][id] = data
+	s.notify(schemaID, data)
+}
+
+func (s *Store) notify(schemaID string, data map[string]interface{}) {
+	for c, w := range s.watchers {
+		if w.schema.ID != schemaID {
+			continue
+		}
+		if len(w.conditions) > 0 && len(handler.ApplyQueryConditions(w.conditions, w.schema, []map[string]interface{}{data})) == 0 {
+			continue
+		}
+		select {
+		case c <- values.DeepCopyMap(data):
+		default:
+			// Slow consumer, drop
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore()
+	schema := &types.Schema{ID: "foo"}
+	apiContext := &types.APIContext{}
+
+	_, err := s.Create(apiContext, schema, map[string]interface{}{"name": "b", "value": "two"})
+	assert.Nil(t, err)
+	_, err = s.Create(apiContext, schema, map[string]interface{}{"name": "a", "value": "one"})
+	assert.Nil(t, err)
+	_, err = s.Create(apiContext, schema, map[string]interface{}{"name": "a"})
+	assert.NotNil(t, err)
+
+	obj, err := s.ByID(apiContext, schema, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, "one", obj["value"])
+
+	obj["value"] = "changed"
+	obj, _ = s.ByID(apiContext, schema, "a")
+	assert.Equal(t, "one", obj["value"])
+
+	list, err := s.List(apiContext, schema, &types.QueryOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "a", list[0]["id"])
+
+	_, err = s.Delete(apiContext, schema, "a")
+	assert.Nil(t, err)
+	_, err = s.ByID(apiContext, schema, "a")
+	assert.NotNil(t, err)
+}
+
+func TestWatch(t *testing.T) {
+	s := NewStore()
+	schema := &types.Schema{ID: "foo"}
+
+	_, err := s.Watch(&types.APIContext{}, schema, &types.QueryOptions{})
+	assert.NotNil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	apiContext := &types.APIContext{
+		Request: httptest.NewRequest("GET", "/", nil).WithContext(ctx),
+	}
+	c, err := s.Watch(apiContext, schema, &types.QueryOptions{
+		Conditions: []*types.QueryCondition{types.EQ("value", "one")},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	_, err = s.Create(apiContext, schema, map[string]interface{}{"name": "b", "value": "two"})
+	assert.Nil(t, err)
+	_, err = s.Create(apiContext, schema, map[string]interface{}{"name": "a", "value": "one"})
+	assert.Nil(t, err)
+	assert.Equal(t, "a", (<-c)["id"])
+
+	cancel()
+	select {
+	case _, ok := <-c:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not end with the request")
+	}
+}
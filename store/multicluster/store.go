@@ -0,0 +1,259 @@
+package multicluster
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/store/proxy"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/rest"
+)
+
+const DefaultClusterField = "clusterId"
+
+// Store aggregates the same resource type across several clusters. Reads are fanned out to every cluster and
+// each object gets the cluster name in ClusterField and an ID of the form "<cluster>:<id>". Writes are routed
+// to the cluster named by that field or ID prefix.
+type Store struct {
+	sync.RWMutex
+	ClusterField string
+	clusters     map[string]types.Store
+}
+
+func NewStore(clusters map[string]types.Store) *Store {
+	s := &Store{
+		ClusterField: DefaultClusterField,
+		clusters:     map[string]types.Store{},
+	}
+	for name, store := range clusters {
+		s.clusters[name] = store
+	}
+	return s
+}
+
+// NewProxyStores creates a proxy store per cluster from its client config.
+func NewProxyStores(ctx context.Context, configs map[string]rest.Config, storageContext types.StorageContext,
+	prefix []string, group, version, kind, resourcePlural string) (map[string]types.Store, error) {
+	result := map[string]types.Store{}
+	for name, config := range configs {
+		getter, err := proxy.NewClientGetterFromConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = proxy.NewProxyStore(ctx, getter, storageContext, prefix, group, version, kind, resourcePlural)
+	}
+	return result, nil
+}
+
+func (s *Store) AddCluster(name string, store types.Store) {
+	s.Lock()
+	defer s.Unlock()
+	s.clusters[name] = store
+}
+
+func (s *Store) RemoveCluster(name string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.clusters, name)
+}
+
+func (s *Store) snapshot() map[string]types.Store {
+	s.RLock()
+	defer s.RUnlock()
+	result := make(map[string]types.Store, len(s.clusters))
+	for name, store := range s.clusters {
+		result[name] = store
+	}
+	return result
+}
+
+func (s *Store) cluster(name string) (types.Store, error) {
+	s.RLock()
+	defer s.RUnlock()
+	store, ok := s.clusters[name]
+	if !ok {
+		return nil, httperror.NewAPIError(httperror.NotFound, "unknown cluster "+name)
+	}
+	return store, nil
+}
+
+func (s *Store) route(id string) (types.Store, string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 {
+		return nil, "", "", httperror.NewAPIError(httperror.NotFound, "failed to find resource by id")
+	}
+	store, err := s.cluster(parts[0])
+	return store, parts[0], parts[1], err
+}
+
+func (s *Store) annotate(cluster string, data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	data[s.ClusterField] = cluster
+	if id, ok := data["id"]; ok {
+		data["id"] = cluster + ":" + convert.ToString(id)
+	}
+	return data
+}
+
+func (s *Store) Context() types.StorageContext {
+	return types.DefaultStorageContext
+}
+
+func (s *Store) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	store, cluster, id, err := s.route(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.ByID(apiContext, schema, id)
+	return s.annotate(cluster, data), err
+}
+
+// List returns the merged lists of all clusters. Clusters that fail are logged and left out so one unreachable
+// cluster doesn't break the aggregated view, unless every cluster fails.
+func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	var (
+		eg     errgroup.Group
+		lock   sync.Mutex
+		result []map[string]interface{}
+		errs   []error
+	)
+
+	clusters := s.snapshot()
+	for name, store := range clusters {
+		name, store := name, store
+		eg.Go(func() error {
+			data, err := store.List(apiContext, schema, copyOptions(opt))
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				logrus.Warnf("failed to list %s in cluster %s: %v", schema.ID, name, err)
+				errs = append(errs, errors.Wrapf(err, "cluster %s", name))
+				return nil
+			}
+			for _, obj := range data {
+				result = append(result, s.annotate(name, obj))
+			}
+			return nil
+		})
+	}
+	eg.Wait()
+
+	if len(clusters) > 0 && len(errs) == len(clusters) {
+		return nil, httperror.WrapAPIError(types.NewErrors(errs...), httperror.ServerError,
+			"failed to list "+schema.ID+" in every cluster")
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return convert.ToString(result[i]["id"]) < convert.ToString(result[j]["id"])
+	})
+	return result, nil
+}
+
+func copyOptions(opt *types.QueryOptions) *types.QueryOptions {
+	if opt == nil {
+		return nil
+	}
+	result := *opt
+	result.Conditions = append([]*types.QueryCondition{}, opt.Conditions...)
+	return &result
+}
+
+func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	cluster := convert.ToString(data[s.ClusterField])
+	if cluster == "" {
+		return nil, httperror.NewFieldAPIError(httperror.MissingRequired, s.ClusterField, "")
+	}
+	store, err := s.cluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(data, s.ClusterField)
+	result, err := store.Create(apiContext, schema, data)
+	return s.annotate(cluster, result), err
+}
+
+func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	store, cluster, id, err := s.route(id)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(data, s.ClusterField)
+	result, err := store.Update(apiContext, schema, data, id)
+	return s.annotate(cluster, result), err
+}
+
+func (s *Store) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	store, cluster, id, err := s.route(id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := store.Delete(apiContext, schema, id)
+	return s.annotate(cluster, result), err
+}
+
+// Watch merges the watches of all clusters. The returned channel is closed once every cluster watch has ended or
+// the request is done. It fails if no cluster could be watched.
+func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	ctx := context.Background()
+	if apiContext.Request != nil {
+		ctx = apiContext.Request.Context()
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs []error
+	)
+	result := make(chan map[string]interface{})
+
+	clusters := s.snapshot()
+	for name, store := range clusters {
+		c, err := store.Watch(apiContext, schema, copyOptions(opt))
+		if err != nil {
+			logrus.Warnf("failed to watch %s in cluster %s: %v", schema.ID, name, err)
+			errs = append(errs, errors.Wrapf(err, "cluster %s", name))
+			continue
+		}
+		if c == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, c chan map[string]interface{}) {
+			defer wg.Done()
+			for data := range c {
+				select {
+				case result <- s.annotate(name, data):
+				case <-ctx.Done():
+					// The cluster watch ends with the request, don't block it until then
+					for range c {
+					}
+					return
+				}
+			}
+		}(name, c)
+	}
+
+	if len(clusters) > 0 && len(errs) == len(clusters) {
+		return nil, httperror.WrapAPIError(types.NewErrors(errs...), httperror.ServerError,
+			"failed to watch "+schema.ID+" in every cluster")
+	}
+
+	go func() {
+		wg.Wait()
+		close(result)
+	}()
+
+	return result, nil
+}
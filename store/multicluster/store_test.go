@@ -0,0 +1,89 @@
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/store/empty"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct {
+	empty.Store
+}
+
+func (f *failingStore) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	return nil, errors.New("unreachable")
+}
+
+func (f *failingStore) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	return nil, errors.New("unreachable")
+}
+
+func TestListFailures(t *testing.T) {
+	schema := &types.Schema{ID: "widget"}
+	apiContext := &types.APIContext{}
+
+	healthy := memory.NewStore()
+	_, err := healthy.Create(apiContext, schema, map[string]interface{}{"name": "a"})
+	assert.NoError(t, err)
+
+	s := NewStore(map[string]types.Store{
+		"local":  healthy,
+		"remote": &failingStore{},
+	})
+	list, err := s.List(apiContext, schema, &types.QueryOptions{})
+	if assert.NoError(t, err) && assert.Len(t, list, 1) {
+		assert.Equal(t, "local:a", list[0]["id"])
+	}
+
+	s.RemoveCluster("local")
+	_, err = s.List(apiContext, schema, &types.QueryOptions{})
+	assert.Error(t, err)
+	_, err = s.Watch(apiContext, schema, &types.QueryOptions{})
+	assert.Error(t, err)
+}
+
+func TestWatchEndsWithRequest(t *testing.T) {
+	schema := &types.Schema{ID: "widget"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	apiContext := &types.APIContext{
+		Request: httptest.NewRequest("GET", "/", nil).WithContext(ctx),
+	}
+
+	local := memory.NewStore()
+	s := NewStore(map[string]types.Store{"local": local})
+	c, err := s.Watch(apiContext, schema, &types.QueryOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Nobody reads the event, so the forwarder has to give up on the request being done
+	_, err = local.Create(&types.APIContext{}, schema, map[string]interface{}{"name": "a"})
+	assert.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-waitClosed(c):
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not end with the request")
+	}
+}
+
+func waitClosed(c chan map[string]interface{}) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		// Only the end of the channel matters, events may or may not have been forwarded
+		for range c {
+		}
+		close(done)
+	}()
+	return done
+}
@@ -0,0 +1,147 @@
+package normalize
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/definition"
+)
+
+// Normalizer rewrites data in place into its canonical form before it is written.
+type Normalizer func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{})
+
+// Defaults trims strings and sorts set fields.
+var Defaults = []Normalizer{TrimStrings, SortSets}
+
+// CreateDefaults canonicalizes names. They only run on create, see CreateMiddleware.
+var CreateDefaults = []Normalizer{CanonicalName}
+
+// Wrap runs normalizers on the input of every create and update of s, so equal objects are always written the
+// same way and don't cause spurious update events.
+func Wrap(s types.Store, normalizers ...Normalizer) types.Store {
+	return store.Wrap(s, Middleware(normalizers...))
+}
+
+// WrapDefaults runs Defaults on every create and update of s and CreateDefaults on every create.
+func WrapDefaults(s types.Store) types.Store {
+	return store.Wrap(s, Middleware(Defaults...), CreateMiddleware(CreateDefaults...))
+}
+
+// Middleware runs normalizers on the input of every create and update.
+func Middleware(normalizers ...Normalizer) store.Middleware {
+	return store.Middleware{
+		Create: createMiddleware(normalizers),
+		Update: func(next store.UpdateFunc) store.UpdateFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+				normalize(normalizers, apiContext, schema, data)
+				return next(apiContext, schema, data, id)
+			}
+		},
+	}
+}
+
+// CreateMiddleware runs normalizers on the input of every create only, for normalizations that would change
+// existing objects on their next update, like the case of names.
+func CreateMiddleware(normalizers ...Normalizer) store.Middleware {
+	return store.Middleware{
+		Create: createMiddleware(normalizers),
+	}
+}
+
+func createMiddleware(normalizers []Normalizer) func(next store.CreateFunc) store.CreateFunc {
+	return func(next store.CreateFunc) store.CreateFunc {
+		return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+			normalize(normalizers, apiContext, schema, data)
+			return next(apiContext, schema, data)
+		}
+	}
+}
+
+func normalize(normalizers []Normalizer, apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) {
+	for _, n := range normalizers {
+		n(apiContext, schema, data)
+	}
+}
+
+// TrimStrings removes leading and trailing white space from string fields.
+func TrimStrings(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) {
+	walk(apiContext, schema, data, func(field types.Field, value interface{}) interface{} {
+		switch field.Type {
+		case "string", "dnsLabel", "dnsLabelRestricted", "hostname", "enum":
+			if str, ok := value.(string); ok {
+				return strings.TrimSpace(str)
+			}
+		}
+		return value
+	})
+}
+
+// CanonicalName lower cases and trims the name field. Names of existing objects must not be rewritten, so it
+// belongs in CreateMiddleware.
+func CanonicalName(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) {
+	if name, ok := data["name"].(string); ok {
+		data["name"] = strings.ToLower(strings.TrimSpace(name))
+	}
+}
+
+// SortSets sorts the values of array fields that are marked as sets with the norman:"set" tag.
+func SortSets(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) {
+	walk(apiContext, schema, data, func(field types.Field, value interface{}) interface{} {
+		if !field.Set {
+			return value
+		}
+		slice, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		sort.SliceStable(slice, func(i, j int) bool {
+			return convert.ToString(slice[i]) < convert.ToString(slice[j])
+		})
+		return slice
+	})
+}
+
+func walk(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, f func(types.Field, interface{}) interface{}) {
+	walkDepth(apiContext, schema, data, f, 0)
+}
+
+func walkDepth(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, f func(types.Field, interface{}) interface{}, depth int) {
+	if schema == nil || data == nil || depth > 10 {
+		return
+	}
+
+	for name, field := range schema.ResourceFields {
+		value, ok := data[name]
+		if !ok || value == nil {
+			continue
+		}
+
+		fieldType := field.Type
+		if definition.IsArrayType(fieldType) || definition.IsMapType(fieldType) {
+			fieldType = definition.SubType(fieldType)
+		}
+		if apiContext != nil && apiContext.Schemas != nil {
+			if sub := apiContext.Schemas.Schema(&schema.Version, fieldType); sub != nil {
+				switch v := value.(type) {
+				case map[string]interface{}:
+					if definition.IsMapType(field.Type) {
+						for _, item := range v {
+							walkDepth(apiContext, sub, convert.ToMapInterface(item), f, depth+1)
+						}
+					} else {
+						walkDepth(apiContext, sub, v, f, depth+1)
+					}
+				case []interface{}:
+					for _, item := range v {
+						walkDepth(apiContext, sub, convert.ToMapInterface(item), f, depth+1)
+					}
+				}
+			}
+		}
+
+		data[name] = f(field, value)
+	}
+}
@@ -0,0 +1,63 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type thing struct {
+	Name  string   `json:"name"`
+	Value string   `json:"value"`
+	Tags  []string `json:"tags" norman:"set"`
+}
+
+func TestWrapDefaults(t *testing.T) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().MustImport(&version, thing{})
+	schema := schemas.Schema(&version, "thing")
+	apiContext := &types.APIContext{Schemas: schemas}
+
+	backend := memory.NewStore()
+	s := WrapDefaults(backend)
+
+	created, err := s.Create(apiContext, schema, map[string]interface{}{
+		"name":  " Foo ",
+		"value": " bar ",
+		"tags":  []interface{}{"b", "a"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "foo", created["name"])
+	assert.Equal(t, "bar", created["value"])
+	assert.Equal(t, []interface{}{"a", "b"}, created["tags"])
+
+	// Updates keep the case of names written before the store was wrapped
+	_, err = backend.Create(apiContext, schema, map[string]interface{}{"name": "Legacy"})
+	assert.NoError(t, err)
+	updated, err := s.Update(apiContext, schema, map[string]interface{}{"name": "Legacy", "value": " baz "}, "Legacy")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Legacy", updated["name"])
+		assert.Equal(t, "baz", updated["value"])
+	}
+}
+
+func TestCanonicalName(t *testing.T) {
+	tests := []struct {
+		name     interface{}
+		expected interface{}
+	}{
+		{name: "Foo", expected: "foo"},
+		{name: " foo\t", expected: "foo"},
+		{name: 1, expected: 1},
+	}
+
+	for _, test := range tests {
+		data := map[string]interface{}{"name": test.name}
+		CanonicalName(nil, nil, data)
+		assert.Equal(t, test.expected, data["name"])
+	}
+}
@@ -0,0 +1,109 @@
+package partition
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+)
+
+// Partition is one slice of the objects of a schema. Conditions are applied to everything listed from Store, so
+// several partitions can share a store, for example one per namespace set.
+type Partition struct {
+	Name       string
+	Store      types.Store
+	Conditions []*types.QueryCondition
+}
+
+// Partitioner returns the partitions to list and picks the partition owning a single object. Lookup gets the
+// object ID for reads, updates and deletes and the submitted data on create.
+type Partitioner interface {
+	All(apiContext *types.APIContext, schema *types.Schema) ([]Partition, error)
+	Lookup(apiContext *types.APIContext, schema *types.Schema, id string, data map[string]interface{}) (Partition, error)
+}
+
+// NamespaceSets partitions store by sets of namespaces, keyed by partition name.
+type NamespaceSets struct {
+	Store types.Store
+	Sets  map[string][]string
+}
+
+func (n *NamespaceSets) All(apiContext *types.APIContext, schema *types.Schema) ([]Partition, error) {
+	var result []Partition
+	for name, namespaces := range n.Sets {
+		result = append(result, Partition{
+			Name:  name,
+			Store: n.Store,
+			Conditions: []*types.QueryCondition{
+				types.NewConditionFromString("namespaceId", types.ModifierIn, namespaces...),
+			},
+		})
+	}
+	return result, nil
+}
+
+func (n *NamespaceSets) Lookup(apiContext *types.APIContext, schema *types.Schema, id string, data map[string]interface{}) (Partition, error) {
+	namespace := convert.ToString(data["namespaceId"])
+	if namespace == "" {
+		namespace = strings.SplitN(id, ":", 2)[0]
+	}
+
+	for name, namespaces := range n.Sets {
+		for _, ns := range namespaces {
+			if ns == namespace {
+				return Partition{Name: name, Store: n.Store}, nil
+			}
+		}
+	}
+
+	return Partition{}, httperror.NewAPIError(httperror.NotFound, "no partition for namespace "+namespace)
+}
+
+// Shards spreads objects over Stores by a hash of Field, "name" if empty. IDs are expected to end in the value of
+// Field so reads are routed to the same shard that created the object.
+type Shards struct {
+	Field  string
+	Stores []types.Store
+}
+
+func (s *Shards) All(apiContext *types.APIContext, schema *types.Schema) ([]Partition, error) {
+	var result []Partition
+	for i, store := range s.Stores {
+		result = append(result, Partition{
+			Name:  shardName(i),
+			Store: store,
+		})
+	}
+	return result, nil
+}
+
+func (s *Shards) Lookup(apiContext *types.APIContext, schema *types.Schema, id string, data map[string]interface{}) (Partition, error) {
+	if len(s.Stores) == 0 {
+		return Partition{}, httperror.NewAPIError(httperror.NotFound, "no shards")
+	}
+
+	field := s.Field
+	if field == "" {
+		field = "name"
+	}
+
+	key := convert.ToString(data[field])
+	if key == "" {
+		parts := strings.Split(id, ":")
+		key = parts[len(parts)-1]
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	i := int(h.Sum32() % uint32(len(s.Stores)))
+	return Partition{
+		Name:  shardName(i),
+		Store: s.Stores[i],
+	}, nil
+}
+
+func shardName(i int) string {
+	return "shard-" + convert.ToString(i)
+}
@@ -0,0 +1,385 @@
+package partition
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"golang.org/x/sync/errgroup"
+)
+
+const DefaultConcurrency = 4
+
+// Store lists all partitions in parallel, at most Concurrency at a time, and merges the results ordered by ID.
+// Single object operations go to the partition picked by the Partitioner.
+type Store struct {
+	Partitioner Partitioner
+	Concurrency int
+}
+
+func NewStore(partitioner Partitioner, concurrency int) *Store {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	return &Store{
+		Partitioner: partitioner,
+		Concurrency: concurrency,
+	}
+}
+
+func (s *Store) Context() types.StorageContext {
+	return types.DefaultStorageContext
+}
+
+func (s *Store) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	p, err := s.Partitioner.Lookup(apiContext, schema, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.Store.ByID(apiContext, schema, id)
+}
+
+func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	lists, err := s.listPartitions(apiContext, schema, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]interface{}
+	for _, list := range lists {
+		result = append(result, list...)
+	}
+	sortByID(result)
+	return result, nil
+}
+
+// Pager is implemented by stores listing a page at a time, like Store itself. ListPage passes such partitions the
+// limit and their own continue token instead of listing everything. Pages have to be sorted by ID.
+type Pager interface {
+	ListPage(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions, limit int, token string) ([]map[string]interface{}, string, error)
+}
+
+// listPager pages stores that can only list everything as a single page.
+type listPager struct {
+	store types.Store
+}
+
+func (l listPager) ListPage(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions, limit int, token string) ([]map[string]interface{}, string, error) {
+	data, err := l.store.List(apiContext, schema, opt)
+	sortByID(data)
+	return data, "", err
+}
+
+func pager(store types.Store) Pager {
+	if p, ok := store.(Pager); ok {
+		return p
+	}
+	return listPager{store: store}
+}
+
+// position is where the next page of a partition starts: the page of the partition with Token, after the
+// object with the ID Marker.
+type position struct {
+	Token  string `json:"token,omitempty"`
+	Marker string `json:"marker,omitempty"`
+}
+
+type candidate struct {
+	obj       map[string]interface{}
+	partition string
+	token     string
+}
+
+// ListPage returns up to limit objects following token, the continue token of the previous page, or the first
+// page if token is empty. Every partition keeps its own position in the token, so pages stay consistent while
+// objects are added to other partitions. Partitions implementing Pager are only asked for the pages needed. The
+// returned token is empty on the last page.
+func (s *Store) ListPage(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions, limit int, token string) ([]map[string]interface{}, string, error) {
+	positions, err := decodeToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	partitions, err := s.Partitioner.All(apiContext, schema)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		eg         errgroup.Group
+		lock       sync.Mutex
+		sem        = make(chan struct{}, s.concurrency())
+		candidates []candidate
+		more       bool
+	)
+	for _, p := range partitions {
+		p := p
+		eg.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			found, exhausted, err := s.partitionPage(apiContext, schema, opt, p, positions[p.Name], limit)
+			if err != nil {
+				return err
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			candidates = append(candidates, found...)
+			if !exhausted {
+				more = true
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, "", err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return convert.ToString(candidates[i].obj["id"]) < convert.ToString(candidates[j].obj["id"])
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+		more = true
+	}
+	if !more {
+		return objects(candidates), "", nil
+	}
+
+	next := map[string]position{}
+	for _, p := range partitions {
+		if pos, ok := positions[p.Name]; ok {
+			next[p.Name] = pos
+		}
+	}
+	for _, c := range candidates {
+		next[c.partition] = position{
+			Token:  c.token,
+			Marker: convert.ToString(c.obj["id"]),
+		}
+	}
+	nextToken, err := encodeToken(next)
+	return objects(candidates), nextToken, err
+}
+
+// partitionPage returns up to limit objects of the partition following pos, and whether the partition has no
+// objects after them.
+func (s *Store) partitionPage(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions, p Partition, pos position, limit int) ([]candidate, bool, error) {
+	var (
+		result []candidate
+		token  = pos.Token
+	)
+	for {
+		data, next, err := pager(p.Store).ListPage(apiContext, schema, copyOptions(opt), limit, token)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, obj := range filter(schema, p.Conditions, data) {
+			if pos.Marker != "" && convert.ToString(obj["id"]) <= pos.Marker {
+				continue
+			}
+			result = append(result, candidate{
+				obj:       obj,
+				partition: p.Name,
+				token:     token,
+			})
+		}
+		if next == "" {
+			return result, true, nil
+		}
+		if limit > 0 && len(result) >= limit {
+			return result, false, nil
+		}
+		token = next
+	}
+}
+
+func objects(candidates []candidate) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, c := range candidates {
+		result = append(result, c.obj)
+	}
+	return result
+}
+
+func (s *Store) listPartitions(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (map[string][]map[string]interface{}, error) {
+	partitions, err := s.Partitioner.All(apiContext, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		eg     errgroup.Group
+		lock   sync.Mutex
+		sem    = make(chan struct{}, s.concurrency())
+		result = map[string][]map[string]interface{}{}
+	)
+
+	for _, p := range partitions {
+		p := p
+		eg.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			data, err := p.Store.List(apiContext, schema, copyOptions(opt))
+			if err != nil {
+				return err
+			}
+			data = filter(schema, p.Conditions, data)
+			sortByID(data)
+
+			lock.Lock()
+			defer lock.Unlock()
+			result[p.Name] = data
+			return nil
+		})
+	}
+
+	return result, eg.Wait()
+}
+
+func (s *Store) concurrency() int {
+	if s.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return s.Concurrency
+}
+
+func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	p, err := s.Partitioner.Lookup(apiContext, schema, "", data)
+	if err != nil {
+		return nil, err
+	}
+	return p.Store.Create(apiContext, schema, data)
+}
+
+func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	p, err := s.Partitioner.Lookup(apiContext, schema, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.Store.Update(apiContext, schema, data, id)
+}
+
+func (s *Store) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	p, err := s.Partitioner.Lookup(apiContext, schema, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.Store.Delete(apiContext, schema, id)
+}
+
+// Watch merges the watches of all partitions. The returned channel is closed once every partition watch has ended or
+// the request is done.
+func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	partitions, err := s.Partitioner.All(apiContext, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if apiContext.Request != nil {
+		ctx = apiContext.Request.Context()
+	}
+
+	var wg sync.WaitGroup
+	result := make(chan map[string]interface{})
+
+	for _, p := range partitions {
+		c, err := p.Store.Watch(apiContext, schema, copyOptions(opt))
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(p Partition, c chan map[string]interface{}) {
+			defer wg.Done()
+			for data := range c {
+				if len(filter(schema, p.Conditions, []map[string]interface{}{data})) == 0 {
+					continue
+				}
+				select {
+				case result <- data:
+				case <-ctx.Done():
+					// The partition watch ends with the request, don't block it until then
+					for range c {
+					}
+					return
+				}
+			}
+		}(p, c)
+	}
+
+	go func() {
+		wg.Wait()
+		close(result)
+	}()
+
+	return result, nil
+}
+
+func filter(schema *types.Schema, conditions []*types.QueryCondition, data []map[string]interface{}) []map[string]interface{} {
+	if len(conditions) == 0 {
+		return data
+	}
+
+	var result []map[string]interface{}
+outer:
+	for _, obj := range data {
+		for _, condition := range conditions {
+			if !condition.Valid(schema, obj) {
+				continue outer
+			}
+		}
+		result = append(result, obj)
+	}
+	return result
+}
+
+func sortByID(data []map[string]interface{}) {
+	sort.SliceStable(data, func(i, j int) bool {
+		return convert.ToString(data[i]["id"]) < convert.ToString(data[j]["id"])
+	})
+}
+
+func copyOptions(opt *types.QueryOptions) *types.QueryOptions {
+	if opt == nil {
+		return nil
+	}
+	result := *opt
+	result.Conditions = append([]*types.QueryCondition{}, opt.Conditions...)
+	return &result
+}
+
+func encodeToken(positions map[string]position) (string, error) {
+	bytes, err := json.Marshal(positions)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func decodeToken(token string) (map[string]position, error) {
+	positions := map[string]position{}
+	if token == "" {
+		return positions, nil
+	}
+
+	bytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, httperror.NewAPIError(httperror.InvalidFormat, "invalid continue token")
+	}
+	if err := json.Unmarshal(bytes, &positions); err != nil {
+		return nil, httperror.NewAPIError(httperror.InvalidFormat, "invalid continue token")
+	}
+	return positions, nil
+}
@@ -0,0 +1,140 @@
+package partition
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/store/empty"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPage(t *testing.T) {
+	schema := &types.Schema{ID: "thing"}
+	apiContext := &types.APIContext{}
+
+	var stores []types.Store
+	for _, ids := range [][]string{{"a", "c", "e"}, {"b", "d"}} {
+		s := memory.NewStore()
+		for _, id := range ids {
+			_, err := s.Create(apiContext, schema, map[string]interface{}{"id": id})
+			assert.NoError(t, err)
+		}
+		stores = append(stores, s)
+	}
+
+	s := NewStore(&Shards{Stores: stores}, 2)
+
+	var (
+		ids   []string
+		token string
+	)
+	for {
+		page, next, err := s.ListPage(apiContext, schema, &types.QueryOptions{}, 2, token)
+		assert.NoError(t, err)
+		assert.True(t, len(page) <= 2)
+		for _, obj := range page {
+			ids = append(ids, obj["id"].(string))
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, ids)
+}
+
+// pagerStore pages ids, the continue token being the index of the first object of the page.
+type pagerStore struct {
+	empty.Store
+	ids    []string
+	limits []int
+	tokens []string
+}
+
+func (p *pagerStore) ListPage(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions, limit int, token string) ([]map[string]interface{}, string, error) {
+	p.limits = append(p.limits, limit)
+	p.tokens = append(p.tokens, token)
+
+	start := 0
+	if token != "" {
+		start, _ = strconv.Atoi(token)
+	}
+	end := start + limit
+	next := strconv.Itoa(end)
+	if end >= len(p.ids) {
+		end = len(p.ids)
+		next = ""
+	}
+
+	var result []map[string]interface{}
+	for _, id := range p.ids[start:end] {
+		result = append(result, map[string]interface{}{"id": id})
+	}
+	return result, next, nil
+}
+
+func TestListPagePassesLimitAndToken(t *testing.T) {
+	schema := &types.Schema{ID: "thing"}
+	apiContext := &types.APIContext{}
+
+	pager := &pagerStore{ids: []string{"a", "c", "e", "g", "i"}}
+	other := memory.NewStore()
+	for _, id := range []string{"b", "d"} {
+		_, err := other.Create(apiContext, schema, map[string]interface{}{"id": id})
+		assert.NoError(t, err)
+	}
+
+	s := NewStore(&Shards{Stores: []types.Store{pager, other}}, 2)
+
+	var (
+		ids   []string
+		token string
+	)
+	for {
+		page, next, err := s.ListPage(apiContext, schema, &types.QueryOptions{}, 2, token)
+		assert.NoError(t, err)
+		for _, obj := range page {
+			ids = append(ids, obj["id"].(string))
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "g", "i"}, ids)
+	for _, limit := range pager.limits {
+		assert.Equal(t, 2, limit)
+	}
+	// A page of the partition is listed again while some of its objects were not returned yet
+	assert.Equal(t, []string{"", "", "2", "", "2", "2", "4"}, pager.tokens)
+}
+
+func TestWatchEndsWithRequest(t *testing.T) {
+	schema := &types.Schema{ID: "thing"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	apiContext := &types.APIContext{
+		Request: httptest.NewRequest("GET", "/", nil).WithContext(ctx),
+	}
+
+	s := NewStore(&Shards{Stores: []types.Store{memory.NewStore(), memory.NewStore()}}, 2)
+	c, err := s.Watch(apiContext, schema, &types.QueryOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cancel()
+	select {
+	case _, ok := <-c:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not end with the request")
+	}
+}
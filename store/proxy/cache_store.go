@@ -0,0 +1,178 @@
+package proxy
+
+import (
+	"context"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/store/authz"
+	"github.com/rancher/norman/types"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	restclientwatch "k8s.io/client-go/rest/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+type ReadMode int
+
+const (
+	// CacheRead serves List and ByID from the informer cache once it has synced
+	CacheRead ReadMode = iota
+	// DirectRead always reads from the Kubernetes API, like the plain proxy store
+	DirectRead
+)
+
+// cacheStore serves reads from a shared informer cache and only sends writes and watches to the Kubernetes API.
+// The informer runs with cacheClient, so cached reads are not impersonated. They are only served to callers
+// authorized by authorizer, or without an authorizer to requests without an impersonated user. Other reads go to
+// the Kubernetes API as the caller. A single request can ask for a direct read with ?_consistent=true.
+type cacheStore struct {
+	*Store
+
+	readMode   ReadMode
+	informer   cache.SharedIndexInformer
+	authorizer authz.Authorizer
+}
+
+func NewCachingProxyStore(ctx context.Context, cacheClient rest.Interface, clientGetter ClientGetter, storageContext types.StorageContext,
+	readMode ReadMode, authorizer authz.Authorizer, prefix []string, group, version, kind, resourcePlural string) types.Store {
+	s := &cacheStore{
+		Store:      newStore(ctx, clientGetter, storageContext, prefix, group, version, kind, resourcePlural),
+		readMode:   readMode,
+		authorizer: authorizer,
+	}
+
+	s.informer = cache.NewSharedIndexInformer(s.listWatch(cacheClient), &unstructured.Unstructured{}, 0, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	go s.informer.Run(ctx.Done())
+
+	return &errorStore{
+		Store: s,
+	}
+}
+
+func (s *cacheStore) listWatch(client rest.Interface) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			result := &unstructured.UnstructuredList{}
+			err := s.common("", client.Get()).
+				VersionedParams(&opts, metav1.ParameterCodec).
+				Do().
+				Into(result)
+			return result, err
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.Watch = true
+			body, err := s.common("", client.Get()).
+				VersionedParams(&opts, metav1.ParameterCodec).
+				Stream()
+			if err != nil {
+				return nil, err
+			}
+
+			framer := json.Framer.NewFrameReader(body)
+			decoder := streaming.NewDecoder(framer, &unstructuredDecoder{})
+			return watch.NewStreamWatcher(restclientwatch.NewDecoder(decoder, &unstructuredDecoder{})), nil
+		},
+	}
+}
+
+// cached reports if the read may be served from the cache, after checking that the caller may run verb.
+func (s *cacheStore) cached(apiContext *types.APIContext, schema *types.Schema, verb, id string) (bool, error) {
+	if s.readMode == DirectRead || apiContext.Option("consistent") == "true" || !s.informer.HasSynced() {
+		return false, nil
+	}
+	if s.authorizer == nil {
+		return apiContext.Request == nil || s.getUser(apiContext) == "", nil
+	}
+	if err := s.authorizer.Authorize(apiContext, schema, verb, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *cacheStore) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	if cached, err := s.cached(apiContext, schema, types.VerbGet, id); err != nil {
+		return nil, err
+	} else if !cached {
+		return s.Store.ByID(apiContext, schema, id)
+	}
+
+	namespace, name := splitID(id)
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+
+	obj, exists, err := s.informer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, httperror.NewAPIError(httperror.NotFound, "failed to find resource by id")
+	}
+
+	data := obj.(*unstructured.Unstructured).DeepCopy().Object
+	return s.fromInternal(apiContext, schema, data), nil
+}
+
+func (s *cacheStore) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	// The authorizer checks a single namespace, so lists across several namespaces are read as the caller
+	if opt != nil && opt.Namespaces != nil {
+		return s.Store.List(apiContext, schema, opt)
+	}
+	// The namespace read from the cache is the one authorized, whatever the authorizer would pick on its own
+	namespace := getNamespace(apiContext, opt)
+	if cached, err := s.cached(apiContext, schema, types.VerbList, namespace+":"); err != nil {
+		return nil, err
+	} else if !cached {
+		return s.Store.List(apiContext, schema, opt)
+	}
+
+	labelSelector, err := labels.Parse(apiContext.Query.Get("labelSelector"))
+	if err != nil {
+		return nil, httperror.WrapAPIError(err, httperror.InvalidFormat, "invalid labelSelector")
+	}
+	fieldSelector, err := fields.ParseSelector(apiContext.Query.Get("fieldSelector"))
+	if err != nil {
+		return nil, httperror.WrapAPIError(err, httperror.InvalidFormat, "invalid fieldSelector")
+	}
+
+	objs, err := s.byNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]interface{}
+	for _, obj := range objs {
+		u := obj.(*unstructured.Unstructured)
+		if !labelSelector.Matches(labels.Set(u.GetLabels())) {
+			continue
+		}
+		if !fieldSelector.Matches(fields.Set{
+			"metadata.name":      u.GetName(),
+			"metadata.namespace": u.GetNamespace(),
+		}) {
+			continue
+		}
+		result = append(result, s.fromInternal(apiContext, schema, u.DeepCopy().Object))
+	}
+
+	logrus.Debugf("LIST (cached): %d %s", len(result), s.resourcePlural)
+	return apiContext.AccessControl.FilterList(apiContext, schema, result, s.authContext), nil
+}
+
+func (s *cacheStore) byNamespace(namespace string) ([]interface{}, error) {
+	if namespace == "" {
+		return s.informer.GetIndexer().List(), nil
+	}
+	return s.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+}
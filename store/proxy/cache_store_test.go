@@ -0,0 +1,117 @@
+package proxy
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rancher/norman/authorization"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	authzv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	authzclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// namespaceReviews allows reviews of a single namespace.
+type namespaceReviews struct {
+	allowed string
+}
+
+func (n *namespaceReviews) SubjectAccessReviews() authzclient.SubjectAccessReviewInterface {
+	return n
+}
+
+func (n *namespaceReviews) Create(sar *authzv1.SubjectAccessReview) (*authzv1.SubjectAccessReview, error) {
+	sar.Status.Allowed = sar.Spec.ResourceAttributes.Namespace == n.allowed
+	return sar, nil
+}
+
+// syncedInformer serves a fixed indexer as a synced informer.
+type syncedInformer struct {
+	cache.SharedIndexInformer
+	indexer cache.Indexer
+}
+
+func (s *syncedInformer) HasSynced() bool {
+	return true
+}
+
+func (s *syncedInformer) GetIndexer() cache.Indexer {
+	return s.indexer
+}
+
+func newTestCacheStore(allowed string) *cacheStore {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	for _, namespace := range []string{"a", "b"} {
+		obj := &unstructured.Unstructured{}
+		obj.SetNamespace(namespace)
+		obj.SetName("pod-" + namespace)
+		indexer.Add(obj)
+	}
+
+	return &cacheStore{
+		Store:      newStore(context.Background(), nil, types.DefaultStorageContext, nil, "", "v1", "Pod", "pods"),
+		informer:   &syncedInformer{indexer: indexer},
+		authorizer: authorization.NewRBACAccess(&namespaceReviews{allowed: allowed}, nil, 0),
+	}
+}
+
+func TestCachedListNamespace(t *testing.T) {
+	schema := &types.Schema{ID: "pod", PluralName: "pods", Scope: types.NamespaceScope}
+
+	tests := []struct {
+		name       string
+		subContext string
+		query      string
+		allowed    string
+		expected   []string
+	}{
+		{"subcontext wins over query", "a", "b", "b", nil},
+		{"subcontext allowed", "a", "b", "a", []string{"a"}},
+		{"query only", "", "b", "b", []string{"b"}},
+		{"query only denied", "", "b", "a", nil},
+		{"all namespaces denied", "", "", "b", nil},
+	}
+
+	for _, test := range tests {
+		s := newTestCacheStore(test.allowed)
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(userAuthHeader, "bob")
+		apiContext := &types.APIContext{
+			Request:       req,
+			Query:         url.Values{},
+			SubContext:    map[string]string{},
+			AccessControl: &authorization.AllAccess{},
+		}
+		if test.subContext != "" {
+			apiContext.SubContext["namespaces"] = test.subContext
+		}
+		opt := &types.QueryOptions{}
+		if test.query != "" {
+			apiContext.Query.Set("namespaceId", test.query)
+			opt.Conditions = append(opt.Conditions, types.EQ("namespaceId", test.query))
+		}
+
+		result, err := s.List(apiContext, schema, opt)
+		if test.expected == nil {
+			if assert.Error(t, err, test.name) {
+				assert.Equal(t, httperror.PermissionDenied, err.(*httperror.APIError).Code, test.name)
+			}
+			continue
+		}
+		if !assert.NoError(t, err, test.name) {
+			continue
+		}
+		var namespaces []string
+		for _, obj := range result {
+			namespaces = append(namespaces, obj["metadata"].(map[string]interface{})["namespace"].(string))
+		}
+		assert.Equal(t, test.expected, namespaces, test.name)
+	}
+}
@@ -1,7 +1,10 @@
 package proxy
 
 import (
+	"time"
+
 	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/metrics"
 	"github.com/rancher/norman/types"
 	"k8s.io/apimachinery/pkg/api/errors"
 )
@@ -11,36 +14,51 @@ type errorStore struct {
 }
 
 func (e *errorStore) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	start := time.Now()
 	data, err := e.Store.ByID(apiContext, schema, id)
-	return data, translateError(err)
+	err = translateError(err)
+	metrics.ObserveStoreOperation(schema.ID, "byID", start, err)
+	return data, err
 }
 
 func (e *errorStore) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	start := time.Now()
 	data, err := e.Store.List(apiContext, schema, opt)
-	return data, translateError(err)
+	err = translateError(err)
+	metrics.ObserveStoreOperation(schema.ID, "list", start, err)
+	return data, err
 }
 
 func (e *errorStore) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	start := time.Now()
 	data, err := e.Store.Create(apiContext, schema, data)
-	return data, translateError(err)
-
+	err = translateError(err)
+	metrics.ObserveStoreOperation(schema.ID, "create", start, err)
+	return data, err
 }
 
 func (e *errorStore) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	start := time.Now()
 	data, err := e.Store.Update(apiContext, schema, data, id)
-	return data, translateError(err)
-
+	err = translateError(err)
+	metrics.ObserveStoreOperation(schema.ID, "update", start, err)
+	return data, err
 }
 
 func (e *errorStore) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	start := time.Now()
 	data, err := e.Store.Delete(apiContext, schema, id)
-	return data, translateError(err)
-
+	err = translateError(err)
+	metrics.ObserveStoreOperation(schema.ID, "delete", start, err)
+	return data, err
 }
 
 func (e *errorStore) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	start := time.Now()
 	data, err := e.Store.Watch(apiContext, schema, opt)
-	return data, translateError(err)
+	err = translateError(err)
+	metrics.ObserveStoreOperation(schema.ID, "watch", start, err)
+	return data, err
 }
 
 func translateError(err error) error {
@@ -12,6 +12,7 @@ import (
 	"github.com/rancher/norman/objectclient/dynamic"
 	"github.com/rancher/norman/pkg/broadcast"
 	"github.com/rancher/norman/restwatch"
+	"github.com/rancher/norman/store/retry"
 	"github.com/rancher/norman/types"
 	"github.com/rancher/norman/types/convert"
 	"github.com/rancher/norman/types/convert/merge"
@@ -31,6 +32,14 @@ import (
 	restclientwatch "k8s.io/client-go/rest/watch"
 )
 
+const (
+	// maxWatchSeen limits the objects a watch tracks the sent resource versions of.
+	maxWatchSeen = 10000
+	// conflictRetries and conflictBackoff configure how updates failing with a conflict are retried.
+	conflictRetries = 4
+	conflictBackoff = 10 * time.Millisecond
+)
+
 var (
 	userAuthHeader = "Impersonate-User"
 	authHeaders    = []string{
@@ -103,21 +112,26 @@ type Store struct {
 func NewProxyStore(ctx context.Context, clientGetter ClientGetter, storageContext types.StorageContext,
 	prefix []string, group, version, kind, resourcePlural string) types.Store {
 	return &errorStore{
-		Store: &Store{
-			clientGetter:   clientGetter,
-			storageContext: storageContext,
-			prefix:         prefix,
-			group:          group,
-			version:        version,
-			kind:           kind,
-			resourcePlural: resourcePlural,
-			authContext: map[string]string{
-				"apiGroup": group,
-				"resource": resourcePlural,
-			},
-			close:        ctx,
-			broadcasters: map[rest.Interface]*broadcast.Broadcaster{},
+		Store: newStore(ctx, clientGetter, storageContext, prefix, group, version, kind, resourcePlural),
+	}
+}
+
+func newStore(ctx context.Context, clientGetter ClientGetter, storageContext types.StorageContext,
+	prefix []string, group, version, kind, resourcePlural string) *Store {
+	return &Store{
+		clientGetter:   clientGetter,
+		storageContext: storageContext,
+		prefix:         prefix,
+		group:          group,
+		version:        version,
+		kind:           kind,
+		resourcePlural: resourcePlural,
+		authContext: map[string]string{
+			"apiGroup": group,
+			"resource": resourcePlural,
 		},
+		close:        ctx,
+		broadcasters: map[rest.Interface]*broadcast.Broadcaster{},
 	}
 }
 
@@ -134,9 +148,24 @@ func (s *Store) doAuthed(apiContext *types.APIContext, request *rest.Request) re
 	for _, header := range authHeaders {
 		request.SetHeader(header, apiContext.Request.Header[http.CanonicalHeaderKey(header)]...)
 	}
+	setRequestID(apiContext, request)
 	return request.Do()
 }
 
+func setRequestID(apiContext *types.APIContext, request *rest.Request) {
+	if apiContext.RequestID != "" {
+		request.SetHeader(types.RequestIDHeader, apiContext.RequestID)
+	}
+}
+
+// setLabelSelector passes the labelSelector query parameter on to the Kubernetes API. The list is still filtered
+// by the conditions of the query options, so this only saves reading objects that would be dropped.
+func setLabelSelector(apiContext *types.APIContext, request *rest.Request) {
+	if selector := apiContext.Query.Get("labelSelector"); selector != "" {
+		request.Param("labelSelector", selector)
+	}
+}
+
 func (s *Store) k8sClient(apiContext *types.APIContext) (rest.Interface, error) {
 	return s.clientGetter.UnversionedClient(apiContext, s.storageContext)
 }
@@ -239,15 +268,57 @@ func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *ty
 	return apiContext.AccessControl.FilterList(apiContext, schema, result, s.authContext), nil
 }
 
+// ListChunkSize is the page size used when listing from Kubernetes. Large lists are fetched in chunks and
+// stitched together so a single huge response doesn't stall the API server.
+var ListChunkSize int64 = 500
+
 func (s *Store) retryList(namespace string, apiContext *types.APIContext) (*unstructured.UnstructuredList, error) {
-	var resultList *unstructured.UnstructuredList
 	k8sClient, err := s.k8sClient(apiContext)
 	if err != nil {
 		return nil, err
 	}
 
+	resultList := &unstructured.UnstructuredList{}
+	continueToken := ""
+	restarted := false
+	for {
+		page, err := s.listChunk(namespace, continueToken, k8sClient, apiContext)
+		if errors.IsResourceExpired(err) && continueToken != "" && !restarted {
+			// the continue token expired because the chunks took too long, start over once
+			logrus.Infof("Continue token expired on LIST %v, restarting", s.resourcePlural)
+			resultList = &unstructured.UnstructuredList{}
+			continueToken = ""
+			restarted = true
+			continue
+		}
+		if err != nil {
+			return resultList, err
+		}
+
+		resultList.Object = page.Object
+		resultList.Items = append(resultList.Items, page.Items...)
+		continueToken = page.GetContinue()
+		if continueToken == "" {
+			resultList.SetContinue("")
+			return resultList, nil
+		}
+	}
+}
+
+func (s *Store) listChunk(namespace, continueToken string, k8sClient rest.Interface, apiContext *types.APIContext) (*unstructured.UnstructuredList, error) {
+	var (
+		resultList *unstructured.UnstructuredList
+		err        error
+	)
+
 	for i := 0; i < 3; i++ {
 		req := s.common(namespace, k8sClient.Get())
+		setRequestID(apiContext, req)
+		setLabelSelector(apiContext, req)
+		req.VersionedParams(&metav1.ListOptions{
+			Limit:    ListChunkSize,
+			Continue: continueToken,
+		}, metav1.ParameterCodec)
 		start := time.Now()
 		resultList = &unstructured.UnstructuredList{}
 		err = req.Do().Into(resultList)
@@ -265,7 +336,16 @@ func (s *Store) retryList(namespace string, apiContext *types.APIContext) (*unst
 }
 
 func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
-	c, err := s.shareWatch(apiContext, schema, opt)
+	var (
+		c   chan map[string]interface{}
+		err error
+	)
+	if opt != nil && opt.Options[types.RevisionOption] != "" {
+		// a resumed watch can not join the shared watch, which is past the revision
+		c, err = s.realWatch(apiContext, schema, opt)
+	} else {
+		c, err = s.shareWatch(apiContext, schema, opt)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -275,6 +355,10 @@ func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *t
 	}), nil
 }
 
+// realWatch streams changes until the request context is done. Watches that time out are resumed from the last
+// seen resource version and expired watches are replaced by a fresh list, sending whatever changed in between.
+// Events for resource versions that were already sent are dropped. The versions sent are tracked for at most
+// maxWatchSeen objects, further objects may be sent again after a relist and are not reported as removed.
 func (s *Store) realWatch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
 	namespace := getNamespace(apiContext, opt)
 
@@ -287,12 +371,64 @@ func (s *Store) realWatch(apiContext *types.APIContext, schema *types.Schema, op
 		k8sClient = watchClient.WatchClient()
 	}
 
+	resourceVersion := "0"
+	if opt != nil && opt.Options[types.RevisionOption] != "" {
+		resourceVersion = opt.Options[types.RevisionOption]
+	}
+
+	watcher, err := s.startWatch(k8sClient, namespace, resourceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := apiContext.Request.Context()
+	result := make(chan map[string]interface{})
+	go func() {
+		defer close(result)
+
+		seen := map[string]string{}
+		for {
+			var expired bool
+			resourceVersion, expired = s.consumeWatch(ctx, apiContext, schema, watcher, seen, resourceVersion, result)
+			if ctx.Err() != nil {
+				logrus.Debugf("closing watcher for %s", schema.ID)
+				return
+			}
+
+			if expired {
+				logrus.Debugf("watch for %s expired, relisting", schema.ID)
+				if rv, err := s.relist(ctx, apiContext, schema, namespace, seen, result); err != nil {
+					logrus.Errorf("failed to relist %s: %v", schema.ID, err)
+				} else {
+					resourceVersion = rv
+				}
+			}
+
+			for {
+				watcher, err = s.startWatch(k8sClient, namespace, resourceVersion)
+				if err == nil {
+					break
+				}
+				logrus.Errorf("failed to restart watch for %s: %v", schema.ID, err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(2 * time.Second):
+				}
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+func (s *Store) startWatch(k8sClient rest.Interface, namespace, resourceVersion string) (watch.Interface, error) {
 	timeout := int64(60 * 30)
 	req := s.common(namespace, k8sClient.Get())
 	req.VersionedParams(&metav1.ListOptions{
 		Watch:           true,
 		TimeoutSeconds:  &timeout,
-		ResourceVersion: "0",
+		ResourceVersion: resourceVersion,
 	}, metav1.ParameterCodec)
 
 	body, err := req.Stream()
@@ -302,31 +438,118 @@ func (s *Store) realWatch(apiContext *types.APIContext, schema *types.Schema, op
 
 	framer := json.Framer.NewFrameReader(body)
 	decoder := streaming.NewDecoder(framer, &unstructuredDecoder{})
-	watcher := watch.NewStreamWatcher(restclientwatch.NewDecoder(decoder, &unstructuredDecoder{}))
+	return watch.NewStreamWatcher(restclientwatch.NewDecoder(decoder, &unstructuredDecoder{})), nil
+}
 
-	watchingContext, cancelWatchingContext := context.WithCancel(apiContext.Request.Context())
+// consumeWatch sends the events of watcher until it ends. It returns the last resource version seen and true if
+// the watch ended because that version is too old to resume from.
+func (s *Store) consumeWatch(ctx context.Context, apiContext *types.APIContext, schema *types.Schema, watcher watch.Interface,
+	seen map[string]string, resourceVersion string, result chan map[string]interface{}) (string, bool) {
+	stop := make(chan struct{})
+	defer close(stop)
 	go func() {
-		<-watchingContext.Done()
-		logrus.Debugf("stopping watcher for %s", schema.ID)
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
 		watcher.Stop()
 	}()
 
-	result := make(chan map[string]interface{})
-	go func() {
-		for event := range watcher.ResultChan() {
-			data := event.Object.(*unstructured.Unstructured)
-			s.fromInternal(apiContext, schema, data.Object)
-			if event.Type == watch.Deleted && data.Object != nil {
-				data.Object[".removed"] = true
+	for event := range watcher.ResultChan() {
+		data, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case event.Type == watch.Error:
+			code, _ := convert.ToNumber(data.Object["code"])
+			if code == http.StatusGone {
+				return resourceVersion, true
 			}
-			result <- data.Object
+			logrus.Errorf("watch error for %s: %v", schema.ID, data.Object["message"])
+			continue
 		}
-		logrus.Debugf("closing watcher for %s", schema.ID)
-		close(result)
-		cancelWatchingContext()
-	}()
 
-	return result, nil
+		key := data.GetNamespace() + "/" + data.GetName()
+		rv := data.GetResourceVersion()
+		if event.Type == watch.Deleted {
+			delete(seen, key)
+		} else if seen[key] == rv {
+			continue
+		} else if _, ok := seen[key]; ok || len(seen) < maxWatchSeen {
+			seen[key] = rv
+		}
+		resourceVersion = rv
+
+		if !s.send(ctx, apiContext, schema, data.Object, event.Type == watch.Deleted, result) {
+			return resourceVersion, false
+		}
+	}
+
+	return resourceVersion, false
+}
+
+// relist lists all objects and sends the ones that changed or disappeared since they were last seen.
+func (s *Store) relist(ctx context.Context, apiContext *types.APIContext, schema *types.Schema, namespace string,
+	seen map[string]string, result chan map[string]interface{}) (string, error) {
+	list, err := s.retryList(namespace, apiContext)
+	if err != nil {
+		return "", err
+	}
+
+	current := map[string]bool{}
+	for _, obj := range list.Items {
+		key := obj.GetNamespace() + "/" + obj.GetName()
+		current[key] = true
+		if seen[key] == obj.GetResourceVersion() {
+			continue
+		}
+		if _, ok := seen[key]; ok || len(seen) < maxWatchSeen {
+			seen[key] = obj.GetResourceVersion()
+		}
+		if !s.send(ctx, apiContext, schema, obj.Object, false, result) {
+			return "", ctx.Err()
+		}
+	}
+
+	for key := range seen {
+		if current[key] {
+			continue
+		}
+		delete(seen, key)
+
+		namespace, name := "", key
+		if parts := strings.SplitN(key, "/", 2); len(parts) == 2 {
+			namespace, name = parts[0], parts[1]
+		}
+		removed := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		removed.SetName(name)
+		removed.SetNamespace(namespace)
+		if !s.send(ctx, apiContext, schema, removed.Object, true, result) {
+			return "", ctx.Err()
+		}
+	}
+
+	return list.GetResourceVersion(), nil
+}
+
+func (s *Store) send(ctx context.Context, apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, removed bool, result chan map[string]interface{}) bool {
+	revision := convert.ToString(values.GetValueN(data, "metadata", "resourceVersion"))
+	s.fromInternal(apiContext, schema, data)
+	if revision != "" && data != nil {
+		data[types.RevisionField] = revision
+	}
+	if removed && data != nil {
+		data[".removed"] = true
+	}
+
+	select {
+	case result <- data:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 type unstructuredDecoder struct {
@@ -371,7 +594,12 @@ func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data
 	if name == "" {
 		generated, _ := values.GetValueN(data, "metadata", "generateName").(string)
 		if generated == "" {
-			values.PutValue(data, types.GenerateName(schema.ID), "metadata", "name")
+			// Collisions are left to the Kubernetes API, which rejects existing names.
+			name, err := schema.GenerateObjectName(nil)
+			if err != nil {
+				return nil, err
+			}
+			values.PutValue(data, name, "metadata", "name")
 		}
 	}
 
@@ -405,12 +633,10 @@ func (s *Store) toInternal(mapper types.Mapper, data map[string]interface{}) err
 	return nil
 }
 
+// Update merges data into the current object and writes it. If the write conflicts with another change the
+// current object is read again and data merged on top of it, up to conflictRetries times with a backoff starting
+// at conflictBackoff.
 func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
-	var (
-		result map[string]interface{}
-		err    error
-	)
-
 	k8sClient, err := s.k8sClient(apiContext)
 	if err != nil {
 		return nil, err
@@ -421,34 +647,37 @@ func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data
 		return nil, err
 	}
 
-	for i := 0; i < 5; i++ {
-		req := s.common(namespace, k8sClient.Get()).
-			Name(id)
-
-		resourceVersion, existing, rawErr := s.singleResultRaw(apiContext, schema, req)
-		if rawErr != nil {
-			return nil, rawErr
+	for i := 0; ; i++ {
+		result, err := s.update(apiContext, schema, k8sClient, namespace, id, values.DeepCopyMap(data))
+		if !errors.IsConflict(err) || i >= conflictRetries || !retry.Backoff(apiContext, conflictBackoff<<uint(i)) {
+			return result, err
 		}
+	}
+}
 
-		existing = merge.APIUpdateMerge(schema.InternalSchema, apiContext.Schemas, existing, data, apiContext.Option("replace") == "true")
+func (s *Store) update(apiContext *types.APIContext, schema *types.Schema, k8sClient rest.Interface, namespace, id string,
+	data map[string]interface{}) (map[string]interface{}, error) {
+	req := s.common(namespace, k8sClient.Get()).
+		Name(id)
 
-		values.PutValue(existing, resourceVersion, "metadata", "resourceVersion")
-		values.PutValue(existing, namespace, "metadata", "namespace")
-		values.PutValue(existing, id, "metadata", "name")
+	resourceVersion, existing, err := s.singleResultRaw(apiContext, schema, req)
+	if err != nil {
+		return nil, err
+	}
 
-		req = s.common(namespace, k8sClient.Put()).
-			Body(&unstructured.Unstructured{
-				Object: existing,
-			}).
-			Name(id)
+	existing = merge.APIUpdateMerge(schema.InternalSchema, apiContext.Schemas, existing, data, apiContext.Option("replace") == "true")
 
-		_, result, err = s.singleResult(apiContext, schema, req)
-		if errors.IsConflict(err) {
-			continue
-		}
-		return result, err
-	}
+	values.PutValue(existing, resourceVersion, "metadata", "resourceVersion")
+	values.PutValue(existing, namespace, "metadata", "namespace")
+	values.PutValue(existing, id, "metadata", "name")
+
+	req = s.common(namespace, k8sClient.Put()).
+		Body(&unstructured.Unstructured{
+			Object: existing,
+		}).
+		Name(id)
 
+	_, result, err := s.singleResult(apiContext, schema, req)
 	return result, err
 }
 
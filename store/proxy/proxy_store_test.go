@@ -0,0 +1,153 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// conflictServer serves a single ConfigMap, failing the first conflicts writes with a conflict after changing it
+// like a concurrent writer would.
+type conflictServer struct {
+	sync.Mutex
+	conflicts int
+	version   int
+	data      map[string]interface{}
+	puts      []map[string]interface{}
+}
+
+func (c *conflictServer) object() map[string]interface{} {
+	data := map[string]interface{}{}
+	for k, v := range c.data {
+		data[k] = v
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "a",
+			"namespace":       "default",
+			"resourceVersion": strconv.Itoa(c.version),
+		},
+		"data": data,
+	}
+}
+
+func (c *conflictServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	c.Lock()
+	defer c.Unlock()
+
+	rw.Header().Set("Content-Type", "application/json")
+	if req.URL.Path != "/api/v1/namespaces/default/configmaps/a" {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		json.NewEncoder(rw).Encode(c.object())
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(req.Body)
+		put := map[string]interface{}{}
+		json.Unmarshal(body, &put)
+		c.puts = append(c.puts, put)
+
+		metadata, _ := put["metadata"].(map[string]interface{})
+		if len(c.puts) <= c.conflicts || metadata["resourceVersion"] != strconv.Itoa(c.version) {
+			c.version++
+			c.data["other"] = strconv.Itoa(c.version)
+			rw.WriteHeader(http.StatusConflict)
+			json.NewEncoder(rw).Encode(metav1.Status{
+				TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+				Status:   metav1.StatusFailure,
+				Reason:   metav1.StatusReasonConflict,
+				Code:     http.StatusConflict,
+			})
+			return
+		}
+
+		c.version++
+		c.data = map[string]interface{}{}
+		for k, v := range put["data"].(map[string]interface{}) {
+			c.data[k] = v
+		}
+		json.NewEncoder(rw).Encode(c.object())
+	default:
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newConflictStore(t *testing.T, conflicts int) (types.Store, *conflictServer, func()) {
+	backend := &conflictServer{
+		conflicts: conflicts,
+		version:   1,
+		data:      map[string]interface{}{"value": "old"},
+	}
+	server := httptest.NewServer(backend)
+
+	clientGetter, err := NewClientGetterFromConfig(rest.Config{Host: server.URL})
+	if !assert.NoError(t, err) {
+		server.Close()
+		return nil, nil, nil
+	}
+	s := NewProxyStore(context.Background(), clientGetter, types.DefaultStorageContext, []string{"api"}, "", "v1",
+		"ConfigMap", "configmaps")
+	return s, backend, server.Close
+}
+
+func newUpdateContext() *types.APIContext {
+	return &types.APIContext{
+		Request: httptest.NewRequest(http.MethodPut, "/", nil),
+	}
+}
+
+func TestUpdateRetriesConflicts(t *testing.T) {
+	s, backend, done := newConflictStore(t, 2)
+	if s == nil {
+		return
+	}
+	defer done()
+
+	schema := &types.Schema{ID: "configMap", Scope: types.NamespaceScope}
+	result, err := s.Update(newUpdateContext(), schema, map[string]interface{}{
+		"data": map[string]interface{}{"value": "new"},
+	}, "default:a")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Every attempt reads the object again and merges the changes into it
+	if assert.Len(t, backend.puts, 3) {
+		for i, put := range backend.puts {
+			assert.Equal(t, strconv.Itoa(i+1), put["metadata"].(map[string]interface{})["resourceVersion"])
+			assert.Equal(t, "new", put["data"].(map[string]interface{})["value"])
+		}
+	}
+	assert.Equal(t, map[string]interface{}{"value": "new", "other": "3"}, result["data"])
+}
+
+func TestUpdateGivesUpOnConflicts(t *testing.T) {
+	s, backend, done := newConflictStore(t, conflictRetries+1)
+	if s == nil {
+		return
+	}
+	defer done()
+
+	schema := &types.Schema{ID: "configMap", Scope: types.NamespaceScope}
+	_, err := s.Update(newUpdateContext(), schema, map[string]interface{}{
+		"data": map[string]interface{}{"value": "new"},
+	}, "default:a")
+	assert.True(t, httperror.IsConflict(err))
+	assert.Len(t, backend.puts, conflictRetries+1)
+}
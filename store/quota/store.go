@@ -0,0 +1,119 @@
+package quota
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/rancher/norman/authorization"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+)
+
+// Limit caps the number of objects of a schema. With Field set the objects are counted per value of that
+// field, such as namespaceId, projectId or creatorId, otherwise all objects count.
+type Limit struct {
+	Field string
+	Max   int
+}
+
+// Wrap rejects creates on s that would exceed any of limits. Creates are serialized per schema so concurrent
+// requests can't both slip under the limit, as long as all writes go through the wrapped store. Objects are
+// counted as the server, not the caller, so objects the caller can't see count as well.
+func Wrap(s types.Store, limits ...Limit) types.Store {
+	q := &quota{
+		store:  s,
+		limits: limits,
+		locks:  map[string]*sync.Mutex{},
+	}
+
+	return store.Wrap(s, store.Middleware{
+		Create: func(next store.CreateFunc) store.CreateFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+				lock := q.lock(schema.ID)
+				lock.Lock()
+				defer lock.Unlock()
+
+				if err := q.check(apiContext, schema, data); err != nil {
+					return nil, err
+				}
+				return next(apiContext, schema, data)
+			}
+		},
+	})
+}
+
+type quota struct {
+	sync.Mutex
+	store  types.Store
+	limits []Limit
+	locks  map[string]*sync.Mutex
+}
+
+func (q *quota) lock(schemaID string) *sync.Mutex {
+	q.Lock()
+	defer q.Unlock()
+	lock, ok := q.locks[schemaID]
+	if !ok {
+		lock = &sync.Mutex{}
+		q.locks[schemaID] = lock
+	}
+	return lock
+}
+
+func (q *quota) check(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) error {
+	for _, limit := range q.limits {
+		opts := &types.QueryOptions{}
+		value := ""
+		if limit.Field != "" {
+			value = convert.ToString(data[limit.Field])
+			if value == "" {
+				continue
+			}
+			opts.Conditions = append(opts.Conditions, types.EQ(limit.Field, value))
+		}
+
+		existing, err := q.store.List(serverContext(apiContext), schema, opts)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, obj := range existing {
+			if limit.Field == "" || convert.ToString(obj[limit.Field]) == value {
+				count++
+			}
+		}
+
+		if count >= limit.Max {
+			msg := fmt.Sprintf("quota of %d %s exceeded, %d in use", limit.Max, schema.PluralName, count)
+			if limit.Field != "" {
+				msg = fmt.Sprintf("quota of %d %s for %s %s exceeded, %d in use", limit.Max, schema.PluralName, limit.Field, value, count)
+			}
+			return httperror.NewFieldAPIError(httperror.QuotaExceeded, limit.Field, msg)
+		}
+	}
+
+	return nil
+}
+
+// serverContext returns a copy of apiContext listing with the identity of the server: without the identity and
+// impersonation headers of the caller and without access control filtering.
+func serverContext(apiContext *types.APIContext) *types.APIContext {
+	result := *apiContext
+	result.Identity = nil
+	result.AccessControl = &authorization.AllAccess{}
+	if apiContext.Request != nil {
+		result.Request = apiContext.Request.WithContext(apiContext.Request.Context())
+		result.Request.Header = http.Header{}
+		for k, v := range apiContext.Request.Header {
+			if !strings.HasPrefix(k, "Impersonate-") {
+				result.Request.Header[k] = v
+			}
+		}
+	}
+	return &result
+}
@@ -0,0 +1,50 @@
+package quota
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// ownedStore only lists the objects created by the impersonated user, like an access filtered store.
+func ownedStore() types.Store {
+	return store.Wrap(memory.NewStore(), store.Middleware{
+		List: func(next store.ListFunc) store.ListFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+				data, err := next(apiContext, schema, opt)
+				user := apiContext.Request.Header.Get("Impersonate-User")
+				if user == "" {
+					return data, err
+				}
+				var result []map[string]interface{}
+				for _, obj := range data {
+					if obj["creatorId"] == user {
+						result = append(result, obj)
+					}
+				}
+				return result, err
+			}
+		},
+	})
+}
+
+func TestQuotaCountsAllObjects(t *testing.T) {
+	schema := &types.Schema{ID: "thing", PluralName: "things"}
+	s := Wrap(ownedStore(), Limit{Max: 2})
+
+	for i, user := range []string{"alice", "bob", "carol"} {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header.Set("Impersonate-User", user)
+		_, err := s.Create(&types.APIContext{Request: req}, schema, map[string]interface{}{"creatorId": user})
+		if i < 2 {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Equal(t, httperror.QuotaExceeded, err.(*httperror.APIError).Code)
+		}
+	}
+}
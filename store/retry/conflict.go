@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"time"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert/merge"
+	"github.com/rancher/norman/types/values"
+)
+
+// NewConflictRetryStore wraps store so that an update failing with a 409 is retried up to retries times. Before
+// each retry the current object is read again and the changes of the client are merged on top of it. The wait
+// between attempts starts at backoff and doubles each time. The proxy stores retry conflicts themselves, as they
+// merge updates into the object in its Kubernetes form, so they don't need to be wrapped.
+func NewConflictRetryStore(store types.Store, retries int, backoff time.Duration) types.Store {
+	return &conflictStore{
+		Store:   store,
+		retries: retries,
+		backoff: backoff,
+	}
+}
+
+type conflictStore struct {
+	types.Store
+	retries int
+	backoff time.Duration
+}
+
+func (c *conflictStore) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	changes := values.DeepCopyMap(data)
+	result, err := c.Store.Update(apiContext, schema, data, id)
+
+	for i := 0; i < c.retries && httperror.IsConflict(err); i++ {
+		if !Backoff(apiContext, c.backoff<<uint(i)) {
+			return nil, err
+		}
+
+		existing, getErr := c.Store.ByID(apiContext, schema, id)
+		if getErr != nil {
+			return nil, getErr
+		}
+
+		merged := merge.APIUpdateMerge(schema, apiContext.Schemas, existing, values.DeepCopyMap(changes), apiContext.Option("replace") == "true")
+		result, err = c.Store.Update(apiContext, schema, merged, id)
+	}
+
+	return result, err
+}
+
+// Backoff waits for d, returning false if the request of apiContext is done first.
+func Backoff(apiContext *types.APIContext, d time.Duration) bool {
+	if apiContext.Request == nil {
+		time.Sleep(d)
+		return true
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-apiContext.Request.Context().Done():
+		return false
+	}
+}
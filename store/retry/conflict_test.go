@@ -0,0 +1,63 @@
+package retry
+
+import (
+	"testing"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// conflictingStore fails the first conflicts updates with a conflict, changing the object like a concurrent
+// writer would.
+type conflictingStore struct {
+	*memory.Store
+	conflicts int
+	updates   []map[string]interface{}
+}
+
+func (c *conflictingStore) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	c.updates = append(c.updates, data)
+	if len(c.updates) <= c.conflicts {
+		if _, err := c.Store.Update(apiContext, schema, map[string]interface{}{"other": len(c.updates)}, id); err != nil {
+			return nil, err
+		}
+		return nil, httperror.NewAPIError(httperror.Conflict, "object has been modified")
+	}
+	return c.Store.Update(apiContext, schema, data, id)
+}
+
+func newConflictingStore(t *testing.T, conflicts int) (*conflictingStore, *types.Schema) {
+	schema := &types.Schema{ID: "foo"}
+	s := &conflictingStore{Store: memory.NewStore(), conflicts: conflicts}
+	_, err := s.Create(&types.APIContext{}, schema, map[string]interface{}{"name": "a", "value": "old"})
+	assert.NoError(t, err)
+	return s, schema
+}
+
+func TestConflictRetry(t *testing.T) {
+	backing, schema := newConflictingStore(t, 2)
+	s := NewConflictRetryStore(backing, 3, 0)
+
+	result, err := s.Update(&types.APIContext{}, schema, map[string]interface{}{"value": "new"}, "a")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, backing.updates, 3)
+	assert.Equal(t, "new", result["value"])
+	// The change of the concurrent writer is kept
+	assert.Equal(t, 2, result["other"])
+	// Retries send the current object with the changes merged on top
+	assert.Equal(t, 1, backing.updates[1]["other"])
+	assert.Equal(t, "new", backing.updates[1]["value"])
+}
+
+func TestConflictRetryGivesUp(t *testing.T) {
+	backing, schema := newConflictingStore(t, 5)
+	s := NewConflictRetryStore(backing, 2, 0)
+
+	_, err := s.Update(&types.APIContext{}, schema, map[string]interface{}{"value": "new"}, "a")
+	assert.True(t, httperror.IsConflict(err))
+	assert.Len(t, backing.updates, 3)
+}
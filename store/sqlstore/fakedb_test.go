@@ -0,0 +1,245 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fakeDriver is an in-memory database understanding exactly the statements of Store, so the store can be tested
+// without a database driver. Transactions hold a lock on the whole database like SQLite does.
+type fakeDriver struct {
+	sync.Mutex
+	dbs map[string]*fakeDB
+}
+
+type fakeRow struct {
+	typ, id, namespace string
+	revision           int64
+	removed            int64
+	removedAt          int64
+	data               string
+}
+
+type fakeDB struct {
+	sync.Mutex
+	rows      []fakeRow
+	revision  int64
+	compacted int64
+	counter   bool
+}
+
+var fake = &fakeDriver{dbs: map[string]*fakeDB{}}
+
+func init() {
+	sql.Register("sqlstore-fake", fake)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.Lock()
+	defer d.Unlock()
+	db, ok := d.dbs[name]
+	if !ok {
+		db = &fakeDB{}
+		d.dbs[name] = db
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db       *fakeDB
+	inTx     bool
+	snapshot fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.Lock()
+	c.inTx = true
+	c.snapshot = fakeDB{
+		rows:      append([]fakeRow{}, c.db.rows...),
+		revision:  c.db.revision,
+		compacted: c.db.compacted,
+		counter:   c.db.counter,
+	}
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.inTx = false
+	c.db.Unlock()
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.rows = c.snapshot.rows
+	c.db.revision = c.snapshot.revision
+	c.db.compacted = c.snapshot.compacted
+	c.db.counter = c.snapshot.counter
+	return c.Commit()
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return strings.Count(s.query, "?")
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	_, err := s.run(args)
+	return driver.RowsAffected(1), err
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.run(args)
+}
+
+func (s *fakeStmt) run(args []driver.Value) (*fakeRows, error) {
+	if !s.conn.inTx {
+		s.conn.db.Lock()
+		defer s.conn.db.Unlock()
+	}
+	db := s.conn.db
+	q := s.query
+
+	switch {
+	case strings.HasPrefix(q, "CREATE "):
+		return &fakeRows{}, nil
+	case strings.HasPrefix(q, "INSERT INTO test_revision "):
+		if !db.counter {
+			db.counter = true
+		}
+		return &fakeRows{}, nil
+	case q == "UPDATE test_revision SET revision = revision + 1":
+		db.revision++
+		return &fakeRows{}, nil
+	case q == "UPDATE test_revision SET compacted = ?":
+		db.compacted = args[0].(int64)
+		return &fakeRows{}, nil
+	case q == "SELECT revision FROM test_revision":
+		return &fakeRows{columns: []string{"revision"}, values: [][]driver.Value{{db.revision}}}, nil
+	case q == "SELECT revision, compacted FROM test_revision":
+		return &fakeRows{columns: []string{"revision", "compacted"}, values: [][]driver.Value{{db.revision, db.compacted}}}, nil
+	case strings.HasPrefix(q, "INSERT INTO test "):
+		db.rows = append(db.rows, fakeRow{
+			typ:       args[0].(string),
+			id:        args[1].(string),
+			namespace: args[2].(string),
+			revision:  args[3].(int64),
+			removed:   args[4].(int64),
+			removedAt: args[5].(int64),
+			data:      args[6].(string),
+		})
+		return &fakeRows{}, nil
+	case q == "DELETE FROM test WHERE type = ? AND id = ?":
+		db.remove(func(row fakeRow) bool { return row.typ == args[0] && row.id == args[1] })
+		return &fakeRows{}, nil
+	case q == "DELETE FROM test WHERE removed = 1 AND revision <= ?":
+		db.remove(func(row fakeRow) bool { return row.removed == 1 && row.revision <= args[0].(int64) })
+		return &fakeRows{}, nil
+	case q == "SELECT COALESCE(MAX(revision), 0) FROM test WHERE removed = 1 AND removed_at < ?":
+		var max int64
+		for _, row := range db.rows {
+			if row.removed == 1 && row.removedAt < args[0].(int64) && row.revision > max {
+				max = row.revision
+			}
+		}
+		return &fakeRows{columns: []string{"max"}, values: [][]driver.Value{{max}}}, nil
+	case q == "SELECT data FROM test WHERE type = ? AND id = ? AND removed = 0":
+		return db.query([]string{"data"}, func(row fakeRow) bool {
+			return row.typ == args[0] && row.id == args[1] && row.removed == 0
+		}, "id"), nil
+	case strings.HasPrefix(q, "SELECT data FROM test WHERE type = ? AND removed = 0"):
+		return db.query([]string{"data"}, func(row fakeRow) bool {
+			return row.typ == args[0] && row.removed == 0 && (len(args) < 2 || row.namespace == args[1])
+		}, "id"), nil
+	case strings.HasPrefix(q, "SELECT revision, removed, data FROM test WHERE type = ? AND revision > ?"):
+		return db.query([]string{"revision", "removed", "data"}, func(row fakeRow) bool {
+			return row.typ == args[0] && row.revision > args[1].(int64) && (len(args) < 3 || row.namespace == args[2])
+		}, "revision"), nil
+	}
+
+	return nil, fmt.Errorf("unexpected query %q", q)
+}
+
+func (db *fakeDB) remove(match func(row fakeRow) bool) {
+	var rows []fakeRow
+	for _, row := range db.rows {
+		if !match(row) {
+			rows = append(rows, row)
+		}
+	}
+	db.rows = rows
+}
+
+func (db *fakeDB) query(columns []string, match func(row fakeRow) bool, orderBy string) *fakeRows {
+	var rows []fakeRow
+	for _, row := range db.rows {
+		if match(row) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if orderBy == "id" {
+			return rows[i].id < rows[j].id
+		}
+		return rows[i].revision < rows[j].revision
+	})
+
+	result := &fakeRows{columns: columns}
+	for _, row := range rows {
+		var values []driver.Value
+		for _, column := range columns {
+			switch column {
+			case "data":
+				values = append(values, row.data)
+			case "revision":
+				values = append(values, row.revision)
+			case "removed":
+				values = append(values, row.removed)
+			}
+		}
+		result.values = append(result.values, values)
+	}
+	return result
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,441 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rancher/norman/api/handler"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/convert/merge"
+	"github.com/sirupsen/logrus"
+)
+
+// Dialect covers the differences between the supported databases. Queries are written with ? placeholders
+// and rebound for databases that use numbered placeholders.
+type Dialect struct {
+	Name              string
+	NumberedBindVars  bool
+	DefaultPollPeriod time.Duration
+}
+
+var (
+	SQLite = Dialect{
+		Name:              "sqlite",
+		DefaultPollPeriod: time.Second,
+	}
+	Postgres = Dialect{
+		Name:              "postgres",
+		NumberedBindVars:  true,
+		DefaultPollPeriod: time.Second,
+	}
+)
+
+func (d Dialect) rebind(query string) string {
+	if !d.NumberedBindVars {
+		return query
+	}
+
+	buf := strings.Builder{}
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			buf.WriteString(fmt.Sprintf("$%d", n))
+			continue
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
+
+// Store persists resources in a single SQL table. The object is stored as JSON next to indexed columns for the
+// type, id, namespace and a revision used to poll for changes. Deletes leave a tombstone so watchers see them,
+// until they are removed by Compact. The driver for the database has to be registered by the caller.
+//
+// Revisions are taken from a counter row in a second table, which every write updates first. The row stays
+// locked until the write commits, so concurrent writers get increasing revisions in the order they commit and a
+// watcher never passes a revision that is still to be committed.
+type Store struct {
+	db         *sql.DB
+	dialect    Dialect
+	table      string
+	PollPeriod time.Duration
+}
+
+func NewStore(db *sql.DB, dialect Dialect, table string) (*Store, error) {
+	s := &Store{
+		db:         db,
+		dialect:    dialect,
+		table:      table,
+		PollPeriod: dialect.DefaultPollPeriod,
+	}
+	return s, s.init()
+}
+
+func (s *Store) init() error {
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	type VARCHAR(255) NOT NULL,
+	id VARCHAR(512) NOT NULL,
+	namespace VARCHAR(255) NOT NULL DEFAULT '',
+	revision BIGINT NOT NULL,
+	removed INTEGER NOT NULL DEFAULT 0,
+	removed_at BIGINT NOT NULL DEFAULT 0,
+	data TEXT NOT NULL,
+	PRIMARY KEY (type, id))`, s.table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_type_revision ON %s (type, revision)`, s.table, s.table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_type_namespace ON %s (type, namespace)`, s.table, s.table),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s_revision (
+	revision BIGINT NOT NULL,
+	compacted BIGINT NOT NULL)`, s.table),
+		fmt.Sprintf(`INSERT INTO %s_revision (revision, compacted) SELECT 0, 0 WHERE NOT EXISTS (SELECT 1 FROM %s_revision)`,
+			s.table, s.table),
+	}
+
+	for _, stmt := range statements {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Context() types.StorageContext {
+	return types.DefaultStorageContext
+}
+
+func (s *Store) ByID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	data, err := s.get(s.db, schema.ID, id)
+	if err == sql.ErrNoRows {
+		return nil, httperror.NewAPIError(httperror.NotFound, "failed to find "+schema.ID+" "+id)
+	}
+	return data, err
+}
+
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func (s *Store) get(q queryer, schemaID, id string) (map[string]interface{}, error) {
+	var content string
+	err := q.QueryRow(s.dialect.rebind(fmt.Sprintf(`SELECT data FROM %s WHERE type = ? AND id = ? AND removed = 0`, s.table)),
+		schemaID, id).Scan(&content)
+	if err != nil {
+		return nil, err
+	}
+	return decode(content)
+}
+
+func (s *Store) List(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf(`SELECT data FROM %s WHERE type = ? AND removed = 0`, s.table)
+	args := []interface{}{schema.ID}
+	if ns := namespace(apiContext, opt); ns != "" {
+		query += " AND namespace = ?"
+		args = append(args, ns)
+	}
+
+	rows, err := s.db.Query(s.dialect.rebind(query+" ORDER BY id"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		var content string
+		if err := rows.Scan(&content); err != nil {
+			return nil, err
+		}
+		data, err := decode(content)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+	ns := convert.ToString(data["namespaceId"])
+	toID := func(name string) string {
+		if schema.Scope == types.NamespaceScope && ns != "" {
+			return ns + ":" + name
+		}
+		return name
+	}
+
+	name := convert.ToString(data["name"])
+	if name == "" {
+		var err error
+		name, err = schema.GenerateObjectName(func(name string) (bool, error) {
+			_, err := s.get(s.db, schema.ID, toID(name))
+			if err == sql.ErrNoRows {
+				return false, nil
+			}
+			return err == nil, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	id := toID(name)
+
+	data["id"] = id
+	data["type"] = schema.ID
+	if _, ok := data["created"]; !ok {
+		data["created"] = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	err := s.inTx(func(tx *sql.Tx) error {
+		if _, err := s.get(tx, schema.ID, id); err == nil {
+			return httperror.NewAPIError(httperror.NotUnique, "resource "+id+" already exists")
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+		return s.write(tx, schema.ID, id, ns, data, false)
+	})
+	return data, err
+}
+
+func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := s.inTx(func(tx *sql.Tx) error {
+		existing, err := s.get(tx, schema.ID, id)
+		if err == sql.ErrNoRows {
+			return httperror.NewAPIError(httperror.NotFound, "failed to find "+schema.ID+" "+id)
+		} else if err != nil {
+			return err
+		}
+
+		result = merge.APIUpdateMerge(schema, apiContext.Schemas, existing, data, apiContext.Option("replace") == "true")
+		result["id"] = id
+		result["type"] = schema.ID
+		return s.write(tx, schema.ID, id, convert.ToString(result["namespaceId"]), result, false)
+	})
+	return result, err
+}
+
+func (s *Store) Delete(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	var existing map[string]interface{}
+	err := s.inTx(func(tx *sql.Tx) error {
+		var err error
+		existing, err = s.get(tx, schema.ID, id)
+		if err == sql.ErrNoRows {
+			return httperror.NewAPIError(httperror.NotFound, "failed to find "+schema.ID+" "+id)
+		} else if err != nil {
+			return err
+		}
+		return s.write(tx, schema.ID, id, convert.ToString(existing["namespaceId"]), existing, true)
+	})
+	return existing, err
+}
+
+func (s *Store) write(tx *sql.Tx, schemaID, id, ns string, data map[string]interface{}, removed bool) error {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s_revision SET revision = revision + 1`, s.table)); err != nil {
+		return err
+	}
+	var revision int64
+	if err := tx.QueryRow(fmt.Sprintf(`SELECT revision FROM %s_revision`, s.table)).Scan(&revision); err != nil {
+		return err
+	}
+
+	removedInt, removedAt := 0, int64(0)
+	if removed {
+		removedInt, removedAt = 1, time.Now().Unix()
+	}
+
+	if _, err := tx.Exec(s.dialect.rebind(fmt.Sprintf(`DELETE FROM %s WHERE type = ? AND id = ?`, s.table)), schemaID, id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(s.dialect.rebind(fmt.Sprintf(`INSERT INTO %s (type, id, namespace, revision, removed, removed_at, data) VALUES (?, ?, ?, ?, ?, ?, ?)`, s.table)),
+		schemaID, id, ns, revision, removedInt, removedAt, string(content))
+	return err
+}
+
+// Compact removes the tombstones of objects deleted more than retention ago. Watches resuming from a revision
+// before the removed tombstones fail as expired, since they would miss the deletes.
+func (s *Store) Compact(retention time.Duration) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		var revision int64
+		err := tx.QueryRow(s.dialect.rebind(fmt.Sprintf(`SELECT COALESCE(MAX(revision), 0) FROM %s WHERE removed = 1 AND removed_at < ?`, s.table)),
+			time.Now().Add(-retention).Unix()).Scan(&revision)
+		if err != nil || revision == 0 {
+			return err
+		}
+
+		if _, err := tx.Exec(s.dialect.rebind(fmt.Sprintf(`DELETE FROM %s WHERE removed = 1 AND revision <= ?`, s.table)), revision); err != nil {
+			return err
+		}
+		_, err = tx.Exec(s.dialect.rebind(fmt.Sprintf(`UPDATE %s_revision SET compacted = ?`, s.table)), revision)
+		return err
+	})
+}
+
+// StartCompaction runs Compact every interval until ctx is done.
+func (s *Store) StartCompaction(ctx context.Context, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Compact(retention); err != nil {
+					logrus.Errorf("failed to compact %s: %v", s.table, err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *Store) inTx(f func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Watch polls the table for rows of the namespace of the request with a newer revision than the last one seen,
+// sending the ones matching the conditions of opt. It resumes after the revision of the RevisionOption if set,
+// which fails as expired if tombstones after it were compacted. Sent objects carry their revision as
+// RevisionField.
+func (s *Store) Watch(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+	var revision, compacted int64
+	err := s.db.QueryRow(fmt.Sprintf(`SELECT revision, compacted FROM %s_revision`, s.table)).Scan(&revision, &compacted)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt != nil && opt.Options[types.RevisionOption] != "" {
+		since, err := strconv.ParseInt(opt.Options[types.RevisionOption], 10, 64)
+		if err != nil {
+			return nil, httperror.NewAPIError(httperror.InvalidOption, "invalid revision "+opt.Options[types.RevisionOption])
+		}
+		if since < compacted {
+			return nil, httperror.NewAPIError(httperror.Expired, "revision "+opt.Options[types.RevisionOption]+" is compacted")
+		}
+		revision = since
+	}
+
+	var conditions []*types.QueryCondition
+	if opt != nil {
+		conditions = opt.Conditions
+	}
+	ns := namespace(apiContext, opt)
+
+	ctx := context.Background()
+	if apiContext.Request != nil {
+		ctx = apiContext.Request.Context()
+	}
+
+	result := make(chan map[string]interface{})
+	go func() {
+		defer close(result)
+
+		t := time.NewTicker(s.PollPeriod)
+		defer t.Stop()
+
+		for {
+			var err error
+			revision, err = s.changes(ctx, schema, ns, conditions, revision, result)
+			if err != nil && ctx.Err() == nil {
+				logrus.Errorf("failed to poll %s changes: %v", schema.ID, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+func (s *Store) changes(ctx context.Context, schema *types.Schema, ns string, conditions []*types.QueryCondition, since int64,
+	result chan map[string]interface{}) (int64, error) {
+	query := fmt.Sprintf(`SELECT revision, removed, data FROM %s WHERE type = ? AND revision > ?`, s.table)
+	args := []interface{}{schema.ID, since}
+	if ns != "" {
+		query += " AND namespace = ?"
+		args = append(args, ns)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(query+" ORDER BY revision"), args...)
+	if err != nil {
+		return since, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			revision int64
+			removed  int
+			content  string
+		)
+		if err := rows.Scan(&revision, &removed, &content); err != nil {
+			return since, err
+		}
+		data, err := decode(content)
+		if err != nil {
+			return since, err
+		}
+
+		since = revision
+		if len(handler.ApplyQueryConditions(conditions, schema, []map[string]interface{}{data})) == 0 {
+			continue
+		}
+		if removed == 1 {
+			data[".removed"] = true
+		}
+		data[types.RevisionField] = strconv.FormatInt(revision, 10)
+
+		select {
+		case result <- data:
+		case <-ctx.Done():
+			return since, ctx.Err()
+		}
+	}
+
+	return since, rows.Err()
+}
+
+func namespace(apiContext *types.APIContext, opt *types.QueryOptions) string {
+	if val, ok := apiContext.SubContext["namespaces"]; ok {
+		return convert.ToString(val)
+	}
+	if opt == nil {
+		return ""
+	}
+	for _, condition := range opt.Conditions {
+		if condition.Field == "namespaceId" && condition.Value != "" && condition.ToCondition().Modifier == types.ModifierEQ {
+			return condition.Value
+		}
+	}
+	return ""
+}
+
+func decode(content string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	return data, json.Unmarshal([]byte(content), &data)
+}
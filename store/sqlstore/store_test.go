@@ -0,0 +1,175 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type widget struct {
+	types.Namespaced
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func newTestStore(t *testing.T) (*Store, *types.Schema, *types.APIContext) {
+	db, err := sql.Open("sqlstore-fake", t.Name()+strconv.FormatInt(time.Now().UnixNano(), 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStore(db, SQLite, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.PollPeriod = 10 * time.Millisecond
+
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().MustImport(&version, widget{})
+	return s, schemas.Schema(&version, "widget"), &types.APIContext{
+		Schemas:    schemas,
+		SubContext: map[string]string{},
+	}
+}
+
+func watch(t *testing.T, s *Store, schema *types.Schema, apiContext *types.APIContext, revision string) (chan map[string]interface{}, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	watchContext := *apiContext
+	watchContext.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	opt := &types.QueryOptions{}
+	if revision != "" {
+		opt.Options = map[string]string{types.RevisionOption: revision}
+	}
+	c, err := s.Watch(&watchContext, schema, opt)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	return c, cancel
+}
+
+func next(t *testing.T, c chan map[string]interface{}) map[string]interface{} {
+	select {
+	case data := <-c:
+		return data
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch event")
+		return nil
+	}
+}
+
+func TestCRUD(t *testing.T) {
+	s, schema, apiContext := newTestStore(t)
+
+	created, err := s.Create(apiContext, schema, map[string]interface{}{"name": "a", "namespaceId": "ns1", "color": "red"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "ns1:a", created["id"])
+
+	_, err = s.Create(apiContext, schema, map[string]interface{}{"name": "a", "namespaceId": "ns1"})
+	assert.True(t, httperror.IsAPIError(err))
+
+	_, err = s.Create(apiContext, schema, map[string]interface{}{"name": "b", "namespaceId": "ns2"})
+	assert.NoError(t, err)
+
+	data, err := s.ByID(apiContext, schema, "ns1:a")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "red", data["color"])
+	}
+
+	list, err := s.List(apiContext, schema, &types.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	list, err = s.List(apiContext, schema, &types.QueryOptions{
+		Conditions: []*types.QueryCondition{types.EQ("namespaceId", "ns2")},
+	})
+	if assert.NoError(t, err) && assert.Len(t, list, 1) {
+		assert.Equal(t, "ns2:b", list[0]["id"])
+	}
+
+	updated, err := s.Update(apiContext, schema, map[string]interface{}{"color": "blue"}, "ns1:a")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "blue", updated["color"])
+		assert.Equal(t, "a", updated["name"])
+	}
+
+	_, err = s.Delete(apiContext, schema, "ns1:a")
+	assert.NoError(t, err)
+	_, err = s.ByID(apiContext, schema, "ns1:a")
+	assert.True(t, httperror.IsNotFound(err))
+	_, err = s.Update(apiContext, schema, map[string]interface{}{"color": "blue"}, "ns1:a")
+	assert.True(t, httperror.IsNotFound(err))
+}
+
+func TestRevisionOrdering(t *testing.T) {
+	s, schema, apiContext := newTestStore(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.Create(apiContext, schema, map[string]interface{}{"name": strconv.Itoa(i)})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	c, cancel := watch(t, s, schema, apiContext, "0")
+	defer cancel()
+
+	last := int64(0)
+	for i := 0; i < 20; i++ {
+		revision, err := strconv.ParseInt(next(t, c)[types.RevisionField].(string), 10, 64)
+		assert.NoError(t, err)
+		assert.True(t, revision > last, "revision %d after %d", revision, last)
+		last = revision
+	}
+	assert.Equal(t, int64(20), last)
+}
+
+func TestWatchResume(t *testing.T) {
+	s, schema, apiContext := newTestStore(t)
+
+	for _, name := range []string{"a", "b"} {
+		_, err := s.Create(apiContext, schema, map[string]interface{}{"name": name})
+		assert.NoError(t, err)
+	}
+
+	c, cancel := watch(t, s, schema, apiContext, "")
+	_, err := s.Delete(apiContext, schema, "a")
+	assert.NoError(t, err)
+	removed := next(t, c)
+	cancel()
+	assert.Equal(t, "a", removed["id"])
+	assert.Equal(t, true, removed[".removed"])
+
+	c, cancel = watch(t, s, schema, apiContext, "1")
+	assert.Equal(t, "b", next(t, c)["id"])
+	assert.Equal(t, "a", next(t, c)["id"])
+	cancel()
+
+	c, cancel = watch(t, s, schema, apiContext, removed[types.RevisionField].(string))
+	_, err = s.Create(apiContext, schema, map[string]interface{}{"name": "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, "c", next(t, c)["id"])
+	cancel()
+
+	assert.NoError(t, s.Compact(-time.Minute))
+	_, err = s.Watch(apiContext, schema, &types.QueryOptions{
+		Options: map[string]string{types.RevisionOption: "1"},
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, httperror.Expired, err.(*httperror.APIError).Code)
+	}
+}
@@ -0,0 +1,106 @@
+package transform
+
+import (
+	"reflect"
+
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/rancher/norman/types/values"
+)
+
+// ConflictStrategy decides how the value of a field sent by the client is combined with the value currently
+// stored when updating.
+type ConflictStrategy string
+
+const (
+	// ClientWins replaces the stored value with the submitted one. This is the default.
+	ClientWins ConflictStrategy = "client-wins"
+	// ServerWins keeps the stored value and ignores the submitted one.
+	ServerWins ConflictStrategy = "server-wins"
+	// MergeMap merges the submitted keys into the stored map, submitted keys taking precedence.
+	MergeMap ConflictStrategy = "merge-map"
+	// AppendSet adds the submitted values missing from the stored array to its end.
+	AppendSet ConflictStrategy = "append-set"
+)
+
+// ResolveConflicts applies strategies to data, the submitted update, using current as the stored object. Fields
+// without a strategy are left untouched.
+func ResolveConflicts(strategies map[string]ConflictStrategy, current, data map[string]interface{}) map[string]interface{} {
+	if len(strategies) == 0 || current == nil {
+		return data
+	}
+
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+
+	for field, strategy := range strategies {
+		serverValue, serverOK := current[field]
+		clientValue, clientOK := data[field]
+
+		switch strategy {
+		case ServerWins:
+			if serverOK {
+				result[field] = serverValue
+			} else {
+				delete(result, field)
+			}
+		case MergeMap:
+			if !clientOK {
+				continue
+			}
+			clientMap, ok := clientValue.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			merged := values.DeepCopyMap(convert.ToMapInterface(serverValue))
+			if merged == nil {
+				merged = map[string]interface{}{}
+			}
+			for k, v := range clientMap {
+				merged[k] = v
+			}
+			result[field] = merged
+		case AppendSet:
+			if !clientOK {
+				continue
+			}
+			clientSlice, ok := clientValue.([]interface{})
+			if !ok {
+				continue
+			}
+			merged := append([]interface{}{}, convert.ToInterfaceSlice(serverValue)...)
+			for _, v := range clientSlice {
+				if !containsValue(merged, v) {
+					merged = append(merged, v)
+				}
+			}
+			result[field] = merged
+		}
+	}
+
+	return result
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, v := range list {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) resolveConflicts(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+	if len(s.Conflicts) == 0 {
+		return data, nil
+	}
+
+	current, err := s.Store.ByID(apiContext, schema, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ResolveConflicts(s.Conflicts, current, data), nil
+}
@@ -0,0 +1,35 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveConflicts(t *testing.T) {
+	current := map[string]interface{}{
+		"owner":  "server",
+		"labels": map[string]interface{}{"a": "1", "b": "2"},
+		"tags":   []interface{}{"x", "y"},
+		"name":   "old",
+	}
+	data := map[string]interface{}{
+		"owner":  "client",
+		"labels": map[string]interface{}{"b": "3", "c": "4"},
+		"tags":   []interface{}{"y", "z"},
+		"name":   "new",
+	}
+
+	result := ResolveConflicts(map[string]ConflictStrategy{
+		"owner":  ServerWins,
+		"labels": MergeMap,
+		"tags":   AppendSet,
+		"name":   ClientWins,
+	}, current, data)
+
+	assert.Equal(t, "server", result["owner"])
+	assert.Equal(t, map[string]interface{}{"a": "1", "b": "3", "c": "4"}, result["labels"])
+	assert.Equal(t, []interface{}{"x", "y", "z"}, result["tags"])
+	assert.Equal(t, "new", result["name"])
+	assert.Equal(t, "client", data["owner"])
+}
@@ -19,6 +19,8 @@ type Store struct {
 	Transformer       TransformerFunc
 	ListTransformer   ListTransformerFunc
 	StreamTransformer StreamTransformerFunc
+	// Conflicts declares per field how an update is combined with the stored object.
+	Conflicts map[string]ConflictStrategy
 }
 
 func (s *Store) Context() types.StorageContext {
@@ -103,7 +105,11 @@ func (s *Store) Create(apiContext *types.APIContext, schema *types.Schema, data
 }
 
 func (s *Store) Update(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
-	data, err := s.Store.Update(apiContext, schema, data, id)
+	data, err := s.resolveConflicts(apiContext, schema, data, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err = s.Store.Update(apiContext, schema, data, id)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,277 @@
+package trash
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/store"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DeletedAnnotation = "norman.rancher.io/deleted-at"
+	RestoreAction     = "restore"
+	PurgeAction       = "purge"
+)
+
+// Trash turns deletes of a schema into soft deletes. Deleted objects are annotated with the deletion time and
+// hidden from reads, ?_trash=true lists only the deleted objects. They are purged by GC once Retention has passed.
+// As a deleted object keeps its name until it is purged, creating an object of the same name fails with a
+// conflict. With AddActions deleted objects can also be brought back with the restore action, or removed for good
+// with the purge action.
+type Trash struct {
+	Retention time.Duration
+	store     types.Store
+}
+
+// Setup wraps the store of schema. It has to be called after the store of the schema is assigned.
+func Setup(schema *types.Schema, retention time.Duration) *Trash {
+	t := &Trash{
+		Retention: retention,
+		store:     schema.Store,
+	}
+
+	schema.Store = store.Wrap(schema.Store, t.middleware())
+	return t
+}
+
+// AddActions adds the restore and purge actions to the deleted objects of schema, which has to be set up with t.
+func (t *Trash) AddActions(schema *types.Schema) {
+	if schema.ResourceActions == nil {
+		schema.ResourceActions = map[string]types.Action{}
+	}
+	schema.ResourceActions[RestoreAction] = types.Action{}
+	schema.ResourceActions[PurgeAction] = types.Action{}
+
+	formatter := schema.Formatter
+	schema.Formatter = func(apiContext *types.APIContext, resource *types.RawResource) {
+		if formatter != nil {
+			formatter(apiContext, resource)
+		}
+		if deleted(resource.Values) {
+			resource.AddAction(apiContext, RestoreAction)
+			resource.AddAction(apiContext, PurgeAction)
+		}
+	}
+
+	actionHandler := schema.ActionHandler
+	schema.ActionHandler = func(actionName string, action *types.Action, apiContext *types.APIContext) error {
+		switch actionName {
+		case RestoreAction:
+			data, err := t.restore(apiContext, apiContext.Schema, apiContext.ID)
+			if err != nil {
+				return err
+			}
+			apiContext.WriteResponse(http.StatusOK, data)
+			return nil
+		case PurgeAction:
+			if err := t.purge(apiContext, apiContext.Schema, apiContext.ID); err != nil {
+				return err
+			}
+			apiContext.WriteResponse(http.StatusNoContent, nil)
+			return nil
+		}
+		if actionHandler != nil {
+			return actionHandler(actionName, action, apiContext)
+		}
+		return httperror.NewAPIError(httperror.NotFound, "action not found")
+	}
+}
+
+func deleted(data map[string]interface{}) bool {
+	annotations := convert.ToMapInterface(data["annotations"])
+	return convert.ToString(annotations[DeletedAnnotation]) != ""
+}
+
+func deletedAt(data map[string]interface{}) time.Time {
+	annotations := convert.ToMapInterface(data["annotations"])
+	t, _ := time.Parse(time.RFC3339, convert.ToString(annotations[DeletedAnnotation]))
+	return t
+}
+
+func setAnnotation(data map[string]interface{}, value string) map[string]interface{} {
+	annotations := map[string]interface{}{}
+	for k, v := range convert.ToMapInterface(data["annotations"]) {
+		annotations[k] = v
+	}
+	if value == "" {
+		delete(annotations, DeletedAnnotation)
+	} else {
+		annotations[DeletedAnnotation] = value
+	}
+	return map[string]interface{}{
+		"annotations": annotations,
+	}
+}
+
+func (t *Trash) restore(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	existing, err := t.store.ByID(apiContext, schema, id)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted(existing) {
+		return existing, nil
+	}
+	return t.store.Update(apiContext, schema, setAnnotation(existing, ""), id)
+}
+
+// purge removes an object in the trash for good, if the caller may delete it.
+func (t *Trash) purge(apiContext *types.APIContext, schema *types.Schema, id string) error {
+	existing, err := t.store.ByID(apiContext, schema, id)
+	if err != nil {
+		return err
+	}
+	if !deleted(existing) {
+		return httperror.NewAPIError(httperror.InvalidState, schema.ID+" "+id+" is not in the trash")
+	}
+	if err := apiContext.AccessControl.CanDelete(apiContext, existing, schema); err != nil {
+		return err
+	}
+	_, err = t.store.Delete(apiContext, schema, id)
+	return err
+}
+
+// GC purges the deleted objects of schema whose retention has passed.
+func (t *Trash) GC(apiContext *types.APIContext, schema *types.Schema) error {
+	objs, err := t.store.List(apiContext, schema, &types.QueryOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		if !deleted(obj) || time.Since(deletedAt(obj)) < t.Retention {
+			continue
+		}
+		id := convert.ToString(obj["id"])
+		if _, err := t.store.Delete(apiContext, schema, id); err != nil && !httperror.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartGC runs GC every interval until ctx is done. apiContext has to carry an identity that is allowed to
+// list and delete the objects.
+func (t *Trash) StartGC(ctx context.Context, apiContext *types.APIContext, schema *types.Schema, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := t.GC(apiContext, schema); err != nil {
+					logrus.Errorf("failed to purge deleted %s: %v", schema.ID, err)
+				}
+			}
+		}
+	}()
+}
+
+func showTrash(apiContext *types.APIContext) bool {
+	return apiContext.Option("trash") == "true" || apiContext.Action == RestoreAction || apiContext.Action == PurgeAction
+}
+
+// byID returns the object of id, hiding it if it is in the trash and the request is not about the trash.
+func (t *Trash) byID(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+	data, err := t.store.ByID(apiContext, schema, id)
+	if err != nil {
+		return nil, err
+	}
+	if deleted(data) && !showTrash(apiContext) {
+		return nil, httperror.NewAPIError(httperror.NotFound, "failed to find "+schema.ID+" "+id)
+	}
+	return data, nil
+}
+
+// createID returns the ID data would be created with, empty if the store picks the name.
+func createID(schema *types.Schema, data map[string]interface{}) string {
+	if id := convert.ToString(data["id"]); id != "" {
+		return id
+	}
+	name := convert.ToString(data["name"])
+	if name == "" {
+		return ""
+	}
+	if namespace := convert.ToString(data["namespaceId"]); schema.Scope == types.NamespaceScope && namespace != "" {
+		return namespace + ":" + name
+	}
+	return name
+}
+
+func (t *Trash) middleware() store.Middleware {
+	return store.Middleware{
+		ByID: func(next store.ByIDFunc) store.ByIDFunc {
+			return t.byID
+		},
+		List: func(next store.ListFunc) store.ListFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) ([]map[string]interface{}, error) {
+				data, err := next(apiContext, schema, opt)
+				if err != nil {
+					return nil, err
+				}
+
+				trash := apiContext.Option("trash") == "true"
+				var result []map[string]interface{}
+				for _, obj := range data {
+					if deleted(obj) == trash {
+						result = append(result, obj)
+					}
+				}
+				return result, nil
+			}
+		},
+		// Deleted objects keep their name until they are purged
+		Create: func(next store.CreateFunc) store.CreateFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}) (map[string]interface{}, error) {
+				if id := createID(schema, data); id != "" {
+					existing, err := t.store.ByID(apiContext, schema, id)
+					if err == nil && deleted(existing) {
+						return nil, httperror.NewAPIError(httperror.Conflict,
+							schema.ID+" "+id+" is in the trash, restore or purge it first")
+					}
+				}
+				return next(apiContext, schema, data)
+			}
+		},
+		Update: func(next store.UpdateFunc) store.UpdateFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, data map[string]interface{}, id string) (map[string]interface{}, error) {
+				if _, err := t.byID(apiContext, schema, id); err != nil {
+					return nil, err
+				}
+				return next(apiContext, schema, data, id)
+			}
+		},
+		Delete: func(next store.DeleteFunc) store.DeleteFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, id string) (map[string]interface{}, error) {
+				existing, err := t.byID(apiContext, schema, id)
+				if err != nil {
+					return nil, err
+				}
+				return t.store.Update(apiContext, schema, setAnnotation(existing, time.Now().UTC().Format(time.RFC3339)), id)
+			}
+		},
+		// Objects moving to the trash are reported as removed
+		Watch: func(next store.WatchFunc) store.WatchFunc {
+			return func(apiContext *types.APIContext, schema *types.Schema, opt *types.QueryOptions) (chan map[string]interface{}, error) {
+				c, err := next(apiContext, schema, opt)
+				if err != nil || c == nil {
+					return c, err
+				}
+
+				return convert.Chan(c, func(data map[string]interface{}) map[string]interface{} {
+					if deleted(data) {
+						data[".removed"] = true
+					}
+					return data
+				}), nil
+			}
+		},
+	}
+}
@@ -0,0 +1,175 @@
+package trash
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rancher/norman/authorization"
+	"github.com/rancher/norman/httperror"
+	"github.com/rancher/norman/store/memory"
+	"github.com/rancher/norman/types"
+	"github.com/rancher/norman/types/convert"
+	"github.com/stretchr/testify/assert"
+)
+
+type widget struct {
+	types.Resource
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+func newTestTrash(t *testing.T, retention time.Duration) (*Trash, *types.APIContext) {
+	version := types.APIVersion{Version: "v1", Path: "/v1"}
+	schemas := types.NewSchemas().MustImport(&version, widget{})
+	schema := schemas.Schema(&version, "widget")
+	schema.Store = memory.NewStore()
+	trash := Setup(schema, retention)
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	apiContext := &types.APIContext{
+		Request:       req,
+		Schema:        schema,
+		Schemas:       schemas,
+		Query:         url.Values{},
+		AccessControl: &authorization.AllAccess{},
+	}
+	for _, name := range []string{"a", "b"} {
+		_, err := schema.Store.Create(apiContext, schema, map[string]interface{}{"name": name})
+		assert.NoError(t, err)
+	}
+	return trash, apiContext
+}
+
+func names(t *testing.T, apiContext *types.APIContext, trash bool) []string {
+	apiContext.Query.Del("_trash")
+	if trash {
+		apiContext.Query.Set("_trash", "true")
+	}
+	defer apiContext.Query.Del("_trash")
+
+	data, err := apiContext.Schema.Store.List(apiContext, apiContext.Schema, &types.QueryOptions{})
+	assert.NoError(t, err)
+	var result []string
+	for _, obj := range data {
+		result = append(result, convert.ToString(obj["name"]))
+	}
+	return result
+}
+
+func TestDelete(t *testing.T) {
+	_, apiContext := newTestTrash(t, time.Hour)
+	schema := apiContext.Schema
+
+	_, err := schema.Store.Delete(apiContext, schema, "a")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"b"}, names(t, apiContext, false))
+	assert.Equal(t, []string{"a"}, names(t, apiContext, true))
+
+	_, err = schema.Store.ByID(apiContext, schema, "a")
+	assert.True(t, httperror.IsNotFound(err))
+	_, err = schema.Store.Update(apiContext, schema, map[string]interface{}{"name": "c"}, "a")
+	assert.True(t, httperror.IsNotFound(err))
+	_, err = schema.Store.Delete(apiContext, schema, "a")
+	assert.True(t, httperror.IsNotFound(err))
+
+	apiContext.Query.Set("_trash", "true")
+	data, err := schema.Store.ByID(apiContext, schema, "a")
+	if assert.NoError(t, err) {
+		assert.True(t, deleted(data))
+	}
+}
+
+func TestCreateDeletedName(t *testing.T) {
+	_, apiContext := newTestTrash(t, time.Hour)
+	schema := apiContext.Schema
+
+	_, err := schema.Store.Delete(apiContext, schema, "a")
+	assert.NoError(t, err)
+
+	_, err = schema.Store.Create(apiContext, schema, map[string]interface{}{"name": "a"})
+	if assert.Error(t, err) {
+		assert.Equal(t, httperror.Conflict, err.(*httperror.APIError).Code)
+	}
+	_, err = schema.Store.Create(apiContext, schema, map[string]interface{}{"name": "c"})
+	assert.NoError(t, err)
+}
+
+func TestRestoreAndPurge(t *testing.T) {
+	trash, apiContext := newTestTrash(t, time.Hour)
+	schema := apiContext.Schema
+	schema.ResourceMethods = []string{http.MethodGet, http.MethodDelete}
+
+	for _, name := range []string{"a", "b"} {
+		_, err := schema.Store.Delete(apiContext, schema, name)
+		assert.NoError(t, err)
+	}
+
+	data, err := trash.restore(apiContext, schema, "a")
+	if assert.NoError(t, err) {
+		assert.False(t, deleted(data))
+	}
+	assert.Equal(t, []string{"a"}, names(t, apiContext, false))
+
+	err = trash.purge(apiContext, schema, "a")
+	assert.Equal(t, httperror.InvalidState, err.(*httperror.APIError).Code)
+
+	assert.NoError(t, trash.purge(apiContext, schema, "b"))
+	assert.Empty(t, names(t, apiContext, true))
+}
+
+func TestGC(t *testing.T) {
+	trash, apiContext := newTestTrash(t, time.Hour)
+	schema := apiContext.Schema
+
+	_, err := schema.Store.Delete(apiContext, schema, "a")
+	assert.NoError(t, err)
+
+	assert.NoError(t, trash.GC(apiContext, schema))
+	assert.Equal(t, []string{"a"}, names(t, apiContext, true))
+
+	trash.Retention = 0
+	assert.NoError(t, trash.GC(apiContext, schema))
+	assert.Empty(t, names(t, apiContext, true))
+	assert.Equal(t, []string{"b"}, names(t, apiContext, false))
+}
+
+func TestWatchRemoved(t *testing.T) {
+	_, apiContext := newTestTrash(t, time.Hour)
+	schema := apiContext.Schema
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	apiContext.Request = apiContext.Request.WithContext(ctx)
+
+	c, err := schema.Store.Watch(apiContext, schema, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = schema.Store.Delete(apiContext, schema, "a")
+	assert.NoError(t, err)
+
+	select {
+	case data := <-c:
+		assert.Equal(t, "a", data["name"])
+		assert.Equal(t, true, data[".removed"])
+	case <-time.After(time.Second):
+		t.Error("no event for the deleted object")
+	}
+}
+
+func TestAddActions(t *testing.T) {
+	trash, apiContext := newTestTrash(t, time.Hour)
+	schema := apiContext.Schema
+
+	assert.NotContains(t, schema.ResourceActions, RestoreAction)
+	assert.NotContains(t, schema.ResourceActions, PurgeAction)
+
+	trash.AddActions(schema)
+	assert.Contains(t, schema.ResourceActions, RestoreAction)
+	assert.Contains(t, schema.ResourceActions, PurgeAction)
+}
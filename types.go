@@ -6,10 +6,12 @@ import (
 
 	"github.com/rancher/norman/api"
 	"github.com/rancher/norman/controller"
+	"github.com/rancher/norman/leader"
 	"github.com/rancher/norman/pkg/remotedialer"
 	"github.com/rancher/norman/store/proxy"
 	"github.com/rancher/norman/types"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -30,10 +32,20 @@ type Config struct {
 	APIExtClient         clientset.Interface
 	Config               *rest.Config
 	LeaderLockNamespace  string
+	LeaderElection       leader.Config
 	KubeConfig           string
 	IgnoredKubeConfigEnv bool
 	Threadiness          int
+	CORS                 *api.CORSConfig
 	K3s                  K3sConfig
+	// AddToSchemes register the generated types, usually the AddToScheme functions of the generated packages, so
+	// controller events can reference their objects
+	AddToSchemes []func(*runtime.Scheme) error
+
+	// HealthMaxConsecutiveErrors marks controllers failing this many times in a row unhealthy, zero to disable
+	HealthMaxConsecutiveErrors int
+	// HealthPath is where the API handler serves the controller health, /healthz by default
+	HealthPath string
 
 	CustomizeSchemas func(context.Context, proxy.ClientGetter, *types.Schemas) error
 	GlobalSetup      func(context.Context) (context.Context, error)
@@ -60,6 +72,8 @@ type Runtime struct {
 	LocalConfig       *rest.Config
 	UnversionedClient rest.Interface
 	APIHandler        http.Handler
+	Clusters          *controller.Clusters
+	HealthHandler     http.Handler
 	K3sTunnelServer   http.Handler
 	K3sServerConfig   interface{}
 	Embedded          bool
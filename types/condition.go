@@ -1,6 +1,8 @@
 package types
 
 import (
+	"strings"
+
 	"github.com/rancher/norman/types/convert"
 )
 
@@ -37,9 +39,14 @@ type QueryCondition struct {
 	Values        map[string]bool
 	conditionType QueryConditionType
 	left, right   *QueryCondition
+	match         func(data map[string]interface{}) bool
 }
 
 func (q *QueryCondition) Valid(schema *Schema, data map[string]interface{}) bool {
+	if q.match != nil {
+		return q.match(data)
+	}
+
 	switch q.conditionType {
 	case CondAnd:
 		if q.left == nil || q.right == nil {
@@ -52,22 +59,64 @@ func (q *QueryCondition) Valid(schema *Schema, data map[string]interface{}) bool
 		}
 		return q.left.Valid(schema, data) || q.right.Valid(schema, data)
 	case CondEQ:
-		return q.Value == convert.ToString(valueOrDefault(schema, data, q))
+		return q.any(schema, data, func(value string) bool { return q.Value == value })
 	case CondNE:
-		return q.Value != convert.ToString(valueOrDefault(schema, data, q))
+		return !q.any(schema, data, func(value string) bool { return q.Value == value })
 	case CondIn:
-		return q.Values[convert.ToString(valueOrDefault(schema, data, q))]
+		return q.any(schema, data, func(value string) bool { return q.Values[value] })
 	case CondNotIn:
-		return !q.Values[convert.ToString(valueOrDefault(schema, data, q))]
+		return !q.any(schema, data, func(value string) bool { return q.Values[value] })
 	case CondNotNull:
-		return convert.ToString(valueOrDefault(schema, data, q)) != ""
+		return q.any(schema, data, func(value string) bool { return value != "" })
 	case CondNull:
-		return convert.ToString(valueOrDefault(schema, data, q)) == ""
+		return !q.any(schema, data, func(value string) bool { return value != "" })
+	}
+
+	return false
+}
+
+// any reports if one of the values of the field matches. A nested field like spec.nodePool.name has a value for
+// every element of the arrays along its path, or none if the path is missing.
+func (q *QueryCondition) any(schema *Schema, data map[string]interface{}, match func(value string) bool) bool {
+	if !strings.Contains(q.Field, ".") {
+		return match(convert.ToString(valueOrDefault(schema, data, q)))
 	}
 
+	for _, value := range nestedValues(data, strings.Split(q.Field, ".")) {
+		if match(value) {
+			return true
+		}
+	}
 	return false
 }
 
+func nestedValues(value interface{}, path []string) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			result = append(result, nestedValues(item, path)...)
+		}
+		return result
+	case []map[string]interface{}:
+		var result []string
+		for _, item := range v {
+			result = append(result, nestedValues(item, path)...)
+		}
+		return result
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return nil
+		}
+		return nestedValues(v[path[0]], path[1:])
+	}
+
+	if value == nil || len(path) > 0 {
+		return nil
+	}
+	return []string{convert.ToString(value)}
+}
+
 func valueOrDefault(schema *Schema, data map[string]interface{}, q *QueryCondition) interface{} {
 	value := data[q.Field]
 	if value == nil {
@@ -81,7 +130,11 @@ func (q *QueryCondition) ToCondition() Condition {
 	cond := Condition{
 		Modifier: q.conditionType.Name,
 	}
-	if q.conditionType.Args == 1 {
+	if q.left != nil && q.right != nil {
+		left, right := q.left.ToCondition(), q.right.ToCondition()
+		left.Field, right.Field = q.left.Field, q.right.Field
+		cond.Value = []Condition{left, right}
+	} else if q.conditionType.Args == 1 {
 		cond.Value = q.Value
 	} else if q.conditionType.Args == -1 {
 		stringValues := []string{}
@@ -99,6 +152,38 @@ func ValidMod(mod ModifierType) bool {
 	return ok
 }
 
+// Or matches data matching either condition.
+func Or(left, right *QueryCondition) *QueryCondition {
+	return &QueryCondition{
+		conditionType: CondOr,
+		left:          left,
+		right:         right,
+	}
+}
+
+// And matches data matching both conditions.
+func And(left, right *QueryCondition) *QueryCondition {
+	return &QueryCondition{
+		conditionType: CondAnd,
+		left:          left,
+		right:         right,
+	}
+}
+
+// NewPredicateCondition matches data with match, for query languages evaluated by code instead of field
+// conditions. The condition is described by mod and value, usually the expression match was compiled from.
+func NewPredicateCondition(field string, mod ModifierType, value string, match func(data map[string]interface{}) bool) *QueryCondition {
+	return &QueryCondition{
+		Field: field,
+		Value: value,
+		conditionType: QueryConditionType{
+			Name: mod,
+			Args: 1,
+		},
+		match: match,
+	}
+}
+
 func EQ(key, value string) *QueryCondition {
 	return NewConditionFromString(key, ModifierEQ, value)
 }
@@ -1,11 +1,13 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"regexp"
 
 	"github.com/ghodss/yaml"
+	"github.com/vmihailenco/msgpack"
 )
 
 var (
@@ -30,3 +32,21 @@ func YAMLEncoder(writer io.Writer, v interface{}) error {
 	_, err = writer.Write(buf)
 	return err
 }
+
+// MsgpackEncoder encodes v as msgpack, keeping the field names and shape of its JSON form.
+func MsgpackEncoder(writer io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var obj interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return err
+	}
+	if obj, err = msgpackNumbers(obj); err != nil {
+		return err
+	}
+	return msgpack.NewEncoder(writer).SortMapKeys(true).UseCompactEncoding(true).Encode(obj)
+}
@@ -1,19 +1,25 @@
 package types
 
 import (
-	"fmt"
-	"regexp"
-	"strings"
-
-	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"github.com/rancher/norman/name"
 )
 
-var (
-	lowerChars = regexp.MustCompile("[a-z]+")
-)
+// nameAttempts is how often a name is generated before giving up on finding one that isn't taken.
+const nameAttempts = 10
+
+var defaultNameGenerator = name.Random{}
 
 func GenerateName(typeName string) string {
-	base := typeName[0:1] + lowerChars.ReplaceAllString(typeName[1:], "")
-	last := utilrand.String(5)
-	return fmt.Sprintf("%s-%s", strings.ToLower(base), last)
+	generated, _ := defaultNameGenerator.Generate(typeName)
+	return generated
+}
+
+// GenerateObjectName generates a name for a new object of the schema with its NameGenerator, or a random name if
+// unset, until exists, which may be nil, reports the name as free.
+func (s *Schema) GenerateObjectName(exists func(name string) (bool, error)) (string, error) {
+	var generator name.Generator = defaultNameGenerator
+	if s.NameGenerator != nil {
+		generator = s.NameGenerator
+	}
+	return name.Unique(generator, s.ID, nameAttempts, exists)
 }
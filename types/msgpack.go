@@ -0,0 +1,32 @@
+package types
+
+import "encoding/json"
+
+// msgpackNumbers replaces the json.Numbers of a value decoded from JSON with integers or floats, so they are
+// encoded as msgpack numbers rather than strings.
+func msgpackNumbers(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []interface{}:
+		for i, item := range v {
+			converted, err := msgpackNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			converted, err := msgpackNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = converted
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgpackEncoder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := MsgpackEncoder(buf, map[string]interface{}{
+		"a": 1,
+		"b": []interface{}{"x", true, nil, -1, 1.5},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x82,
+		0xa1, 'a', 0x01,
+		0xa1, 'b', 0x95,
+		0xa1, 'x', 0xc3, 0xc0, 0xff,
+		0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+	}, buf.Bytes())
+}
@@ -392,6 +392,8 @@ func applyTag(structField *reflect.StructField, field *Field) error {
 			field.ValidChars = value
 		case "invalidChars":
 			field.InvalidChars = value
+		case "set":
+			field.Set = true
 		default:
 			return fmt.Errorf("invalid tag %s on field %s", key, structField.Name)
 		}
@@ -71,3 +71,45 @@ func (s *Schema) CanDelete(context *APIContext) error {
 	}
 	return context.AccessControl.CanDelete(context, nil, s)
 }
+
+// Verbs of requests and store operations. Handlers can be added for all but VerbWatch with AddVerbHandler.
+const (
+	VerbList   = "list"
+	VerbGet    = "get"
+	VerbWatch  = "watch"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+)
+
+// AddVerbHandler runs handler for requests of verb before the handler of the schema for the verb, for example
+// ListHandler for VerbList. The handler either writes the response itself or calls next, ignoring the next
+// argument of next, to continue with the handlers added after it and finally the handler of the schema.
+func (s *Schema) AddVerbHandler(verb string, handler RequestHandler) *Schema {
+	if s.VerbHandlers == nil {
+		s.VerbHandlers = map[string][]RequestHandler{}
+	}
+	s.VerbHandlers[verb] = append(s.VerbHandlers[verb], handler)
+	return s
+}
+
+// WithVerbHandlers returns handler, called with next, preceded by the handlers added for verb.
+func (s *Schema) WithVerbHandlers(verb string, handler RequestHandler) RequestHandler {
+	handlers := s.VerbHandlers[verb]
+	if len(handlers) == 0 || handler == nil {
+		return handler
+	}
+
+	return func(apiContext *APIContext, next RequestHandler) error {
+		var call func(i int) error
+		call = func(i int) error {
+			if i == len(handlers) {
+				return handler(apiContext, next)
+			}
+			return handlers[i](apiContext, func(*APIContext, RequestHandler) error {
+				return call(i + 1)
+			})
+		}
+		return call(0)
+	}
+}
@@ -278,6 +278,53 @@ func (s *Schemas) Schemas() []*Schema {
 	return s.schemas
 }
 
+// Copy returns schemas that start out like s and can be changed without affecting readers of s. The schemas
+// themselves are shared, adding or removing schemas only changes the copy.
+func (s *Schemas) Copy() *Schemas {
+	s.Lock()
+	defer s.Unlock()
+
+	result := &Schemas{
+		processingTypes:    map[reflect.Type]*Schema{},
+		typeNames:          map[reflect.Type]string{},
+		schemasByPath:      map[string]map[string]*Schema{},
+		mappers:            map[string]map[string][]Mapper{},
+		references:         map[string][]BackReference{},
+		embedded:           map[string]*Schema{},
+		DefaultMappers:     s.DefaultMappers,
+		DefaultPostMappers: s.DefaultPostMappers,
+		versions:           append([]APIVersion{}, s.versions...),
+		schemas:            append([]*Schema{}, s.schemas...),
+		AddHook:            s.AddHook,
+		errors:             append([]error{}, s.errors...),
+	}
+	for k, v := range s.processingTypes {
+		result.processingTypes[k] = v
+	}
+	for k, v := range s.typeNames {
+		result.typeNames[k] = v
+	}
+	for path, schemas := range s.schemasByPath {
+		result.schemasByPath[path] = map[string]*Schema{}
+		for id, schema := range schemas {
+			result.schemasByPath[path][id] = schema
+		}
+	}
+	for path, mappers := range s.mappers {
+		result.mappers[path] = map[string][]Mapper{}
+		for name, m := range mappers {
+			result.mappers[path][name] = m
+		}
+	}
+	for k, v := range s.references {
+		result.references[k] = append([]BackReference{}, v...)
+	}
+	for k, v := range s.embedded {
+		result.embedded[k] = v
+	}
+	return result
+}
+
 func (s *Schemas) mapper(version *APIVersion, name string) []Mapper {
 	var (
 		path string
@@ -100,6 +100,11 @@ type AccessControl interface {
 	FilterList(apiContext *APIContext, schema *Schema, obj []map[string]interface{}, context map[string]string) []map[string]interface{}
 }
 
+// ActionAccessControl is implemented by access controls that also authorize actions.
+type ActionAccessControl interface {
+	CanAction(apiContext *APIContext, schema *Schema, action string) error
+}
+
 type APIContext struct {
 	Action                      string
 	ID                          string
@@ -120,11 +125,25 @@ type APIContext struct {
 	AccessControl               AccessControl
 	SubContext                  map[string]string
 	Pagination                  *Pagination
+	RequestID                   string
+	// Identity is the caller authenticated by the authenticator of the server, nil if the server does not
+	// authenticate requests.
+	Identity *Identity
 
 	Request  *http.Request
 	Response http.ResponseWriter
 }
 
+// Identity is an authenticated user or service.
+type Identity struct {
+	Name   string
+	UID    string
+	Groups []string
+	Extra  map[string][]string
+}
+
+const RequestIDHeader = "X-Request-Id"
+
 type apiContextKey struct{}
 
 func NewAPIContext(req *http.Request, resp http.ResponseWriter, schemas *Schemas) *APIContext {
@@ -179,6 +198,13 @@ var (
 	DESC = SortOrder("desc")
 )
 
+// RevisionOption is the option of QueryOptions asking Store.Watch to resume after a revision instead of sending
+// only new changes. Stores set the revision of watched objects as the RevisionField of the data they send.
+const (
+	RevisionOption = "revision"
+	RevisionField  = ".revision"
+)
+
 type QueryOptions struct {
 	Sort       Sort
 	Pagination *Pagination
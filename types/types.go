@@ -1,5 +1,9 @@
 package types
 
+import (
+	"github.com/rancher/norman/name"
+)
+
 const (
 	ResourceFieldID = "id"
 )
@@ -46,6 +50,7 @@ var (
 type ModifierType string
 
 type Condition struct {
+	Field    string       `json:"field,omitempty"`
 	Modifier ModifierType `json:"modifier,omitempty"`
 	Value    interface{}  `json:"value,omitempty"`
 }
@@ -59,6 +64,8 @@ type Pagination struct {
 	Limit    *int64 `json:"limit,omitempty"`
 	Total    *int64 `json:"total,omitempty"`
 	Partial  bool   `json:"partial,omitempty"`
+	// LimitExceeded is set when the requested limit was above the maximum and the result was truncated
+	LimitExceeded bool `json:"limitExceeded,omitempty"`
 }
 
 type Resource struct {
@@ -129,6 +136,16 @@ type Schema struct {
 	ErrorHandler        ErrorHandler        `json:"-"`
 	Validator           Validator           `json:"-"`
 	Store               Store               `json:"-"`
+	NoReferenceLinks    bool                `json:"-"`
+	DefaultLimit        int64               `json:"-"`
+	MaxLimit            int64               `json:"-"`
+	StrictFields        bool                `json:"-"`
+	NameGenerator       name.Generator      `json:"-"`
+	// VerbHandlers run before the handler of their verb, see AddVerbHandler.
+	VerbHandlers map[string][]RequestHandler `json:"-"`
+	// ReferenceLinkNames renames, or with an empty name drops, the links added for schemas referencing this
+	// schema. Keys are "<referencing schema ID>.<field name>".
+	ReferenceLinkNames map[string]string `json:"-"`
 }
 
 type Field struct {
@@ -149,8 +166,13 @@ type Field struct {
 	Description  string      `json:"description,omitempty"`
 	CodeName     string      `json:"-"`
 	DynamicField bool        `json:"dynamicField,omitempty"`
+	Set          bool        `json:"set,omitempty"`
 }
 
+// ActionOutputBinary is the output of an action that responds with a raw stream, like a file download, instead
+// of a resource.
+const ActionOutputBinary = "binary"
+
 type Action struct {
 	Input  string `json:"input,omitempty"`
 	Output string `json:"output,omitempty"`
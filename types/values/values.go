@@ -109,3 +109,28 @@ func PutValue(data map[string]interface{}, val interface{}, keys ...string) {
 		}
 	}
 }
+
+func DeepCopyMap(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[k] = deepCopyValue(v)
+	}
+	return result
+}
+
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return DeepCopyMap(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = deepCopyValue(item)
+		}
+		return result
+	}
+	return value
+}
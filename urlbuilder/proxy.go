@@ -0,0 +1,89 @@
+package urlbuilder
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// forwardedHeaders are the headers a proxy sets to describe the URL the client requested.
+var forwardedHeaders = []string{
+	PrefixHeader,
+	ForwardedHostHeader,
+	ForwardedProtoHeader,
+	ForwardedPortHeader,
+	ForwardedPrefixHeader,
+}
+
+// ParseTrustedProxies parses IP addresses and CIDRs of proxies whose forwarded headers are trusted.
+func ParseTrustedProxies(values ...string) ([]*net.IPNet, error) {
+	var result []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy %s", value)
+		}
+		result = append(result, cidr)
+	}
+	return result, nil
+}
+
+// StripUntrustedForwarded returns req without forwarded headers unless it was sent by one of the trusted proxies,
+// so clients can not make the server build links to other hosts.
+func StripUntrustedForwarded(req *http.Request, trusted []*net.IPNet) *http.Request {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, cidr := range trusted {
+			if cidr.Contains(ip) {
+				return req
+			}
+		}
+	}
+
+	var found bool
+	for _, header := range forwardedHeaders {
+		if _, ok := req.Header[http.CanonicalHeaderKey(header)]; ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return req
+	}
+
+	newReq := req.WithContext(req.Context())
+	newReq.Header = http.Header{}
+	for k, v := range req.Header {
+		newReq.Header[k] = v
+	}
+	for _, header := range forwardedHeaders {
+		newReq.Header.Del(header)
+	}
+	return newReq
+}
+
+type relativeLinksKey struct{}
+
+// WithRelativeLinks makes URL builders of requests with the context build path-only links, which stay valid
+// behind proxies rewriting the scheme or host.
+func WithRelativeLinks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, relativeLinksKey{}, true)
+}
+
+func relativeLinks(ctx context.Context) bool {
+	relative, _ := ctx.Value(relativeLinksKey{}).(bool)
+	return relative
+}
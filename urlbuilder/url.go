@@ -12,10 +12,11 @@ import (
 )
 
 const (
-	PrefixHeader         = "X-API-URL-Prefix"
-	ForwardedHostHeader  = "X-Forwarded-Host"
-	ForwardedProtoHeader = "X-Forwarded-Proto"
-	ForwardedPortHeader  = "X-Forwarded-Port"
+	PrefixHeader          = "X-API-URL-Prefix"
+	ForwardedHostHeader   = "X-Forwarded-Host"
+	ForwardedProtoHeader  = "X-Forwarded-Proto"
+	ForwardedPortHeader   = "X-Forwarded-Port"
+	ForwardedPrefixHeader = "X-Forwarded-Prefix"
 )
 
 func New(r *http.Request, version types.APIVersion, schemas *types.Schemas) (types.URLBuilder, error) {
@@ -25,6 +26,11 @@ func New(r *http.Request, version types.APIVersion, schemas *types.Schemas) (typ
 		return nil, err
 	}
 
+	if relativeLinks(r.Context()) {
+		requestURL = pathOnly(requestURL)
+		responseURLBase = pathOnly(responseURLBase)
+	}
+
 	builder := &urlBuilder{
 		schemas:         schemas,
 		requestURL:      requestURL,
@@ -192,13 +198,19 @@ func parseRequestURL(r *http.Request) string {
 	if r.TLS != nil {
 		scheme = "https"
 	}
-	return fmt.Sprintf("%s://%s%s%s", scheme, r.Host, r.Header.Get(PrefixHeader), r.URL.Path)
+	return fmt.Sprintf("%s://%s%s%s", scheme, r.Host, getPrefix(r), r.URL.Path)
 }
 
 func getURLFromStandardHeaders(r *http.Request) string {
 	xForwardedProto := getOverrideHeader(r, ForwardedProtoHeader, "")
 	if xForwardedProto == "" {
-		return ""
+		if getOverrideHeader(r, ForwardedHostHeader, "") == "" {
+			return ""
+		}
+		xForwardedProto = "http"
+		if r.TLS != nil {
+			xForwardedProto = "https"
+		}
 	}
 
 	host := getOverrideHeader(r, ForwardedHostHeader, "")
@@ -226,7 +238,24 @@ func getURLFromStandardHeaders(r *http.Request) string {
 		port = ":" + port
 	}
 
-	return fmt.Sprintf("%s://%s%s%s%s", xForwardedProto, host, port, r.Header.Get(PrefixHeader), r.URL.Path)
+	return fmt.Sprintf("%s://%s%s%s%s", xForwardedProto, host, port, getPrefix(r), r.URL.Path)
+}
+
+// getPrefix returns the path the API is served under by a proxy, from X-API-URL-Prefix or X-Forwarded-Prefix.
+// A prefix starting with "//" or containing a backslash is ignored, since it would turn path-only links into
+// links to another host.
+func getPrefix(r *http.Request) string {
+	prefix := r.Header.Get(PrefixHeader)
+	if prefix == "" {
+		prefix = strings.TrimSuffix(getOverrideHeader(r, ForwardedPrefixHeader, ""), "/")
+		if prefix != "" && !strings.HasPrefix(prefix, "/") {
+			prefix = "/" + prefix
+		}
+	}
+	if strings.HasPrefix(prefix, "//") || strings.Contains(prefix, "\\") {
+		return ""
+	}
+	return prefix
 }
 
 func getOverrideHeader(r *http.Request, header string, defaultValue string) string {
@@ -271,3 +300,15 @@ func (u *urlBuilder) CollectionAction(schema *types.Schema, versionOverride *typ
 func (u *urlBuilder) ActionLinkByID(schema *types.Schema, id string, action string) string {
 	return u.constructBasicURL(schema.Version, schema.PluralName, id) + "?action=" + url.QueryEscape(action)
 }
+
+// pathOnly drops the scheme and host of the absolute URL.
+func pathOnly(absoluteURL string) string {
+	u, err := url.Parse(absoluteURL)
+	if err != nil {
+		return absoluteURL
+	}
+	u.Scheme = ""
+	u.Host = ""
+	u.User = nil
+	return u.String()
+}
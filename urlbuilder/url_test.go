@@ -0,0 +1,52 @@
+package urlbuilder
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardedPrefix(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://internal:8080/v3/pods", nil)
+	req.Header.Set(ForwardedHostHeader, "example.com")
+	req.Header.Set(ForwardedProtoHeader, "https")
+	req.Header.Set(ForwardedPrefixHeader, "/k8s/api/")
+
+	assert.Equal(t, "https://example.com/k8s/api/v3/pods", parseRequestURL(req))
+
+	req.Header.Set(ForwardedPrefixHeader, "//evil.com")
+	assert.Equal(t, "https://example.com/v3/pods", parseRequestURL(req))
+	req.Header.Set(ForwardedPrefixHeader, "/\\evil.com")
+	assert.Equal(t, "https://example.com/v3/pods", parseRequestURL(req))
+
+	req.Header.Set(PrefixHeader, "/rancher")
+	assert.Equal(t, "https://example.com/rancher/v3/pods", parseRequestURL(req))
+	req.Header.Set(PrefixHeader, "//evil.com")
+	assert.Equal(t, "https://example.com/v3/pods", parseRequestURL(req))
+}
+
+func TestStripUntrustedForwarded(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8", "192.168.1.1")
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://internal/v3", nil)
+	req.Header.Set(ForwardedHostHeader, "example.com")
+
+	req.RemoteAddr = "10.1.2.3:1234"
+	assert.Equal(t, "example.com", StripUntrustedForwarded(req, trusted).Header.Get(ForwardedHostHeader))
+
+	req.RemoteAddr = "172.16.0.1:1234"
+	assert.Equal(t, "", StripUntrustedForwarded(req, trusted).Header.Get(ForwardedHostHeader))
+	assert.Equal(t, "example.com", req.Header.Get(ForwardedHostHeader))
+
+	req, _ = http.NewRequest(http.MethodGet, "http://internal/v3", nil)
+	req.Header.Set(PrefixHeader, "/rancher")
+	req.RemoteAddr = "172.16.0.1:1234"
+	assert.Equal(t, "", StripUntrustedForwarded(req, trusted).Header.Get(PrefixHeader))
+}
+
+func TestPathOnly(t *testing.T) {
+	assert.Equal(t, "/v3/clusters?limit=5", pathOnly("https://user@example.com/v3/clusters?limit=5"))
+	assert.Equal(t, "/v3", pathOnly("/v3"))
+}